@@ -0,0 +1,211 @@
+package redis
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9/internal/proto"
+)
+
+// autoPipeliner collects the commands processed concurrently by a client
+// and sends them in batches using a single connection.
+type autoPipeliner struct {
+	window   time.Duration
+	maxBatch int
+	exec     pipelineExecer
+
+	mu    sync.Mutex
+	batch *autoPipelineBatch
+}
+
+type autoPipelineBatch struct {
+	cmds  []Cmder // *autoPipelineCmd
+	timer *time.Timer
+	taken bool
+	done  chan struct{}
+}
+
+// autoPipelineCmd wraps the cmd of a caller in a batch. The reply is buffered
+// and decoded into the cmd only while the caller is waiting for it, so the cmd
+// is never modified after the caller gave up and returned.
+type autoPipelineCmd struct {
+	Cmder
+	ctx context.Context
+
+	mu        sync.Mutex
+	abandoned bool
+}
+
+func (cmd *autoPipelineCmd) readReply(rd *proto.Reader) error {
+	raw, err := rd.ReadRaw()
+	if err != nil {
+		return err
+	}
+
+	cmd.mu.Lock()
+	defer cmd.mu.Unlock()
+	if cmd.abandoned {
+		return nil
+	}
+
+	crd := cacheReaders.Get().(*proto.Reader)
+	crd.Reset(bytes.NewReader(raw))
+	err = readCmdReply(crd, cmd.Cmder)
+	cacheReaders.Put(crd)
+	return err
+}
+
+// setAttributes is a no-op: the attributes are decoded along with the reply.
+func (cmd *autoPipelineCmd) setAttributes(map[string]interface{}) {}
+
+func (cmd *autoPipelineCmd) SetErr(err error) {
+	cmd.mu.Lock()
+	defer cmd.mu.Unlock()
+	if !cmd.abandoned {
+		cmd.Cmder.SetErr(err)
+	}
+}
+
+func (cmd *autoPipelineCmd) Err() error {
+	cmd.mu.Lock()
+	defer cmd.mu.Unlock()
+	if cmd.abandoned {
+		return nil
+	}
+	return cmd.Cmder.Err()
+}
+
+func (cmd *autoPipelineCmd) abandon() {
+	cmd.mu.Lock()
+	cmd.abandoned = true
+	cmd.mu.Unlock()
+}
+
+func newAutoPipeliner(c *baseClient) *autoPipeliner {
+	return &autoPipeliner{
+		window:   c.opt.AutoPipelineWindow,
+		maxBatch: c.opt.AutoPipelineMaxBatch,
+		exec: func(ctx context.Context, cmds []Cmder) error {
+			return c.generalProcessPipeline(ctx, cmds, c.pipelineProcessCmds)
+		},
+	}
+}
+
+// process adds the cmd to the current batch and waits until the batch is sent
+// and its replies are read, or until ctx is done.
+func (p *autoPipeliner) process(ctx context.Context, cmd Cmder) error {
+	pcmd := &autoPipelineCmd{
+		Cmder: cmd,
+		ctx:   ctx,
+	}
+
+	p.mu.Lock()
+
+	b := p.batch
+	if b == nil {
+		b = &autoPipelineBatch{
+			done: make(chan struct{}),
+		}
+		b.timer = time.AfterFunc(p.window, func() {
+			p.flush(b)
+		})
+		p.batch = b
+	}
+	b.cmds = append(b.cmds, pcmd)
+
+	full := len(b.cmds) >= p.maxBatch && p.take(b)
+
+	p.mu.Unlock()
+
+	if full {
+		b.timer.Stop()
+		go p.send(b)
+	}
+
+	select {
+	case <-b.done:
+		return cmd.Err()
+	case <-ctx.Done():
+		p.abandon(b, pcmd)
+		return ctx.Err()
+	}
+}
+
+// abandon removes the cmd of a caller that stopped waiting from the batch,
+// or makes the batch skip its reply if the batch is already being sent.
+func (p *autoPipeliner) abandon(b *autoPipelineBatch, cmd *autoPipelineCmd) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if b.taken {
+		cmd.abandon()
+		return
+	}
+	for i, c := range b.cmds {
+		if c == cmd {
+			b.cmds = append(b.cmds[:i], b.cmds[i+1:]...)
+			break
+		}
+	}
+}
+
+func (p *autoPipeliner) flush(b *autoPipelineBatch) {
+	p.mu.Lock()
+	ok := p.take(b)
+	p.mu.Unlock()
+
+	if ok {
+		p.send(b)
+	}
+}
+
+// take detaches the batch so no more commands are added to it.
+// It reports false if the batch was already taken.
+func (p *autoPipeliner) take(b *autoPipelineBatch) bool {
+	if b.taken {
+		return false
+	}
+	b.taken = true
+	if p.batch == b {
+		p.batch = nil
+	}
+	return true
+}
+
+func (p *autoPipeliner) send(b *autoPipelineBatch) {
+	defer close(b.done)
+	if len(b.cmds) == 0 {
+		return
+	}
+
+	ctx, cancel := b.context()
+	defer cancel()
+
+	if err := p.exec(ctx, b.cmds); err != nil {
+		setCmdsErr(b.cmds, err)
+	}
+}
+
+// context returns the context the batch is sent with. The batch is shared by
+// several callers, so it is not canceled with any of them, but it expires
+// at the earliest deadline of their contexts.
+func (b *autoPipelineBatch) context() (context.Context, context.CancelFunc) {
+	var deadline time.Time
+	for _, cmd := range b.cmds {
+		d, ok := cmd.(*autoPipelineCmd).ctx.Deadline()
+		if ok && (deadline.IsZero() || d.Before(deadline)) {
+			deadline = d
+		}
+	}
+	if deadline.IsZero() {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithDeadline(context.Background(), deadline)
+}
+
+func isWriterCmd(cmd Cmder) bool {
+	_, ok := cmd.(*WriterCmd)
+	return ok
+}
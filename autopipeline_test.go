@@ -0,0 +1,101 @@
+package redis_test
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	. "github.com/bsm/ginkgo/v2"
+	. "github.com/bsm/gomega"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var _ = Describe("auto pipelining", func() {
+	var client *redis.Client
+
+	BeforeEach(func() {
+		opt := redisOptions()
+		opt.AutoPipeline = true
+		opt.AutoPipelineWindow = 10 * time.Millisecond
+		client = redis.NewClient(opt)
+		Expect(client.FlushDB(ctx).Err()).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(client.Close()).NotTo(HaveOccurred())
+	})
+
+	It("batches concurrent commands", func() {
+		perform(100, func(id int) {
+			Expect(client.Incr(ctx, "counter").Err()).NotTo(HaveOccurred())
+		})
+		Expect(client.Get(ctx, "counter").Val()).To(Equal("100"))
+
+		stats := client.PoolStats()
+		Expect(stats.Hits + stats.Misses).To(BeNumerically("<", 50))
+	})
+
+	It("returns each caller its own reply", func() {
+		perform(100, func(id int) {
+			key := "key" + strconv.Itoa(id)
+			Expect(client.Set(ctx, key, id, 0).Err()).NotTo(HaveOccurred())
+			Expect(client.Get(ctx, key).Val()).To(Equal(strconv.Itoa(id)))
+			Expect(client.Get(ctx, "missing").Err()).To(Equal(redis.Nil))
+		})
+	})
+
+	It("does not batch blocking commands", func() {
+		Expect(client.RPush(ctx, "list", "a").Err()).NotTo(HaveOccurred())
+
+		val, err := client.BLPop(ctx, time.Second, "list").Result()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(val).To(Equal([]string{"list", "a"}))
+	})
+
+	It("sends full batches without waiting", func() {
+		opt := redisOptions()
+		opt.AutoPipeline = true
+		opt.AutoPipelineWindow = time.Hour
+		opt.AutoPipelineMaxBatch = 1
+		client := redis.NewClient(opt)
+		defer client.Close()
+
+		Expect(client.Ping(ctx).Err()).NotTo(HaveOccurred())
+	})
+	It("returns when the context is done while waiting for the batch", func() {
+		opt := redisOptions()
+		opt.AutoPipeline = true
+		opt.AutoPipelineWindow = 2 * time.Second
+		slow := redis.NewClient(opt)
+		defer slow.Close()
+
+		ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		Expect(slow.Ping(ctx).Err()).To(Equal(context.DeadlineExceeded))
+		Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+	})
+
+	It("sends the rest of the batch without the canceled commands", func() {
+		opt := redisOptions()
+		opt.AutoPipeline = true
+		opt.AutoPipelineWindow = 200 * time.Millisecond
+		slow := redis.NewClient(opt)
+		defer slow.Close()
+
+		canceled, cancel := context.WithCancel(ctx)
+		done := make(chan error)
+		go func() {
+			defer GinkgoRecover()
+			done <- slow.Incr(canceled, "canceled").Err()
+		}()
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+		Expect(<-done).To(Equal(context.Canceled))
+
+		Expect(slow.Incr(ctx, "counter").Val()).To(Equal(int64(1)))
+		Expect(client.Exists(ctx, "canceled").Val()).To(Equal(int64(0)))
+	})
+})
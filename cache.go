@@ -0,0 +1,528 @@
+package redis
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9/internal"
+	"github.com/redis/go-redis/v9/internal/pool"
+	"github.com/redis/go-redis/v9/internal/proto"
+)
+
+// ClientCacheOptions keeps the settings of the client-side cache, see Options.ClientCache.
+type ClientCacheOptions struct {
+	// Maximum number of cached replies. The least recently used reply
+	// is evicted when the limit is reached.
+	// Default is 10000 replies.
+	MaxEntries int
+	// Maximum amount of time a reply is served from the cache, even if
+	// no invalidation message was received for its key.
+	// Default is 0, replies are kept until they are invalidated or evicted.
+	TTL time.Duration
+	// Enables the broadcasting mode of CLIENT TRACKING (BCAST): the server
+	// does not remember the keys read by the client, but sends invalidation
+	// messages for every modified key matching one of Prefixes.
+	Broadcast bool
+	// Prefixes of the keys tracked in the broadcasting mode.
+	// When set, only keys matching one of the prefixes are cached.
+	Prefixes []string
+}
+
+func (opt *ClientCacheOptions) init() {
+	if opt.MaxEntries == 0 {
+		opt.MaxEntries = 10000
+	}
+}
+
+// Read-only single key commands which replies are cached. Commands reading
+// several keys, like EXISTS or MGET, are not cached: their replies would
+// have to be dropped when any of the keys is modified.
+var cacheableCommands = map[string]bool{
+	"get":           true,
+	"getrange":      true,
+	"strlen":        true,
+	"type":          true,
+	"hget":          true,
+	"hmget":         true,
+	"hgetall":       true,
+	"hexists":       true,
+	"hkeys":         true,
+	"hvals":         true,
+	"hlen":          true,
+	"hstrlen":       true,
+	"lindex":        true,
+	"llen":          true,
+	"lrange":        true,
+	"scard":         true,
+	"smembers":      true,
+	"sismember":     true,
+	"smismember":    true,
+	"zcard":         true,
+	"zcount":        true,
+	"zscore":        true,
+	"zmscore":       true,
+	"zrange":        true,
+	"zrangebyscore": true,
+	"zrank":         true,
+	"json.get":      true,
+	"json.type":     true,
+	"json.strlen":   true,
+	"json.arrlen":   true,
+	"json.objkeys":  true,
+	"json.objlen":   true,
+}
+
+type noClientCacheKey struct{}
+
+// WithoutClientCache returns a copy of ctx that makes commands bypass the client-side cache:
+// they are always sent to the server and their replies are not cached.
+func WithoutClientCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noClientCacheKey{}, true)
+}
+
+// How often the invalidation connection is pinged when idle.
+const cacheHealthCheckInterval = 10 * time.Second
+
+var cacheReaders = sync.Pool{
+	New: func() interface{} {
+		return proto.NewReader(nil)
+	},
+}
+
+type cacheEntry struct {
+	key       string // command the reply belongs to
+	redisKey  string
+	raw       []byte // nil until the reply is read
+	expiresAt time.Time
+	elem      *list.Element
+}
+
+// clientCache caches the replies of read-only commands. Connections of the pool
+// enable CLIENT TRACKING and redirect invalidation messages to a dedicated connection,
+// which drops the cached replies of the modified keys.
+type clientCache struct {
+	opt  *ClientCacheOptions
+	base *baseClient
+
+	mu       sync.Mutex
+	cn       *pool.Conn
+	redirect int64 // ID of the invalidation connection, 0 when disconnected
+	entries  map[string]*cacheEntry
+	keys     map[string]map[*cacheEntry]struct{}
+	lru      *list.List
+
+	closed   bool
+	disabled bool // set when the server rejects CLIENT TRACKING
+	exit     chan struct{}
+}
+
+func newClientCache(opt *ClientCacheOptions, base *baseClient) *clientCache {
+	clone := *opt
+	opt = &clone
+	opt.init()
+
+	cc := &clientCache{
+		opt:  opt,
+		base: base,
+
+		entries: make(map[string]*cacheEntry),
+		keys:    make(map[string]map[*cacheEntry]struct{}),
+		lru:     list.New(),
+
+		exit: make(chan struct{}),
+	}
+	// The invalidation connection is gone, so the cached replies can't be invalidated
+	// anymore. Pooled connections are tracked again by track once run restores it.
+	base.push.register("tracking-redir-broken", func(string, []interface{}) {
+		cc.mu.Lock()
+		cc.flush()
+		cc.mu.Unlock()
+	})
+	go cc.run()
+	return cc
+}
+
+func (cc *clientCache) Close() error {
+	cc.mu.Lock()
+	if cc.closed {
+		cc.mu.Unlock()
+		return nil
+	}
+	cc.closed = true
+	close(cc.exit)
+	cn := cc.cn
+	cc.mu.Unlock()
+
+	if cn != nil {
+		return cc.base.connPool.CloseConn(cn)
+	}
+	return nil
+}
+
+// run maintains the invalidation connection until the cache is closed.
+func (cc *clientCache) run() {
+	ctx := context.Background()
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			backoff := internal.RetryBackoff(attempt, cc.base.opt.MinRetryBackoff, cc.base.opt.MaxRetryBackoff)
+			select {
+			case <-time.After(backoff):
+			case <-cc.exit:
+				return
+			}
+		}
+
+		cn, err := cc.connect(ctx)
+		if err != nil {
+			if !cc.isClosed() {
+				internal.Logger.Printf(ctx, "redis: client cache: can't connect: %s", err)
+			}
+			continue
+		}
+		attempt = 0
+
+		err = cc.listen(ctx, cn)
+
+		cc.mu.Lock()
+		cc.cn = nil
+		cc.redirect = 0
+		cc.flush()
+		closed := cc.closed
+		cc.mu.Unlock()
+
+		_ = cc.base.connPool.CloseConn(cn)
+		if closed {
+			return
+		}
+		internal.Logger.Printf(ctx, "redis: client cache: invalidation connection failed: %s", err)
+	}
+}
+
+func (cc *clientCache) isClosed() bool {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	return cc.closed
+}
+
+// connect creates the invalidation connection and subscribes it to invalidation messages
+// sent using RESP2. RESP3 connections receive them as push messages.
+func (cc *clientCache) connect(ctx context.Context) (*pool.Conn, error) {
+	cn, err := cc.base.newConn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	idCmd := NewIntCmd(ctx, "client", "id")
+	subCmd := NewSliceCmd(ctx, "subscribe", "__redis__:invalidate")
+	if err := cn.WithWriter(ctx, cc.base.opt.WriteTimeout, func(wr *proto.Writer) error {
+		return writeCmds(wr, []Cmder{idCmd, subCmd})
+	}); err != nil {
+		_ = cc.base.connPool.CloseConn(cn)
+		return nil, err
+	}
+	if err := cn.WithReader(ctx, cc.base.opt.ReadTimeout, idCmd.readReply); err != nil {
+		_ = cc.base.connPool.CloseConn(cn)
+		return nil, err
+	}
+
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	if cc.closed {
+		_ = cc.base.connPool.CloseConn(cn)
+		return nil, pool.ErrClosed
+	}
+	cc.cn = cn
+	cc.redirect = idCmd.Val()
+	return cn, nil
+}
+
+func (cc *clientCache) listen(ctx context.Context, cn *pool.Conn) error {
+	var pinged bool
+	for {
+		err := cn.WithReader(ctx, cacheHealthCheckInterval, func(rd *proto.Reader) error {
+			reply, err := rd.ReadReply()
+			if err != nil {
+				return err
+			}
+			cc.handleMessage(reply)
+			return nil
+		})
+		if err == nil {
+			pinged = false
+			continue
+		}
+
+		var netErr net.Error
+		if pinged || !errors.As(err, &netErr) || !netErr.Timeout() {
+			return err
+		}
+
+		// Nothing was received for a while, make sure the connection is alive.
+		if err := cn.WithWriter(ctx, cc.base.opt.WriteTimeout, func(wr *proto.Writer) error {
+			return wr.WriteArgs([]interface{}{"ping"})
+		}); err != nil {
+			return err
+		}
+		pinged = true
+	}
+}
+
+func (cc *clientCache) handleMessage(reply interface{}) {
+	msg, ok := reply.([]interface{})
+	if !ok || len(msg) == 0 {
+		return
+	}
+	kind, _ := msg[0].(string)
+	switch kind {
+	case "invalidate":
+		// RESP3: >2 invalidate [keys...]
+		if len(msg) == 2 {
+			cc.invalidate(msg[1])
+		}
+	case "message":
+		// RESP2: *3 message __redis__:invalidate [keys...]
+		if len(msg) == 3 && msg[1] == "__redis__:invalidate" {
+			cc.invalidate(msg[2])
+		}
+	}
+}
+
+// invalidate drops the replies of the keys, nil keys are sent when the database is flushed.
+func (cc *clientCache) invalidate(keys interface{}) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	switch keys := keys.(type) {
+	case nil:
+		cc.flush()
+	case []interface{}:
+		for _, key := range keys {
+			key, ok := key.(string)
+			if !ok {
+				continue
+			}
+			for e := range cc.keys[key] {
+				cc.remove(e)
+			}
+		}
+	}
+}
+
+// track enables tracking on a pooled connection, redirecting invalidation messages
+// to the invalidation connection. The cache is disabled if the server rejects
+// CLIENT TRACKING, e.g. because it doesn't support it.
+func (cc *clientCache) track(ctx context.Context, c *baseClient, cn *pool.Conn) error {
+	cc.mu.Lock()
+	redirect := cc.redirect
+	disabled := cc.disabled
+	cc.mu.Unlock()
+
+	if disabled || redirect == 0 || cn.TrackingRedirect == redirect {
+		return nil
+	}
+
+	args := []interface{}{"client", "tracking", "on", "redirect", redirect}
+	if cc.opt.Broadcast {
+		args = append(args, "bcast")
+		for _, prefix := range cc.opt.Prefixes {
+			args = append(args, "prefix", prefix)
+		}
+	}
+
+	conn := newConn(c.opt, pool.NewSingleConnPool(c.connPool, cn))
+	_, err := conn.Pipelined(ctx, func(pipe Pipeliner) error {
+		if cn.TrackingRedirect != 0 {
+			// The previous invalidation connection is gone.
+			pipe.Do(ctx, "client", "tracking", "off")
+		}
+		pipe.Do(ctx, args...)
+		return nil
+	})
+	if err != nil {
+		if isRedisError(err) {
+			cc.disable(ctx, err)
+			return nil
+		}
+		return err
+	}
+
+	cn.TrackingRedirect = redirect
+	return nil
+}
+
+// disable stops caching replies after the server rejected CLIENT TRACKING.
+func (cc *clientCache) disable(ctx context.Context, err error) {
+	cc.mu.Lock()
+	if cc.disabled {
+		cc.mu.Unlock()
+		return
+	}
+	cc.disabled = true
+	cc.redirect = 0
+	cc.flush()
+	cc.mu.Unlock()
+
+	internal.Logger.Printf(ctx, "redis: client cache: disabled, CLIENT TRACKING failed: %s", err)
+	_ = cc.Close()
+}
+
+// cacheKey returns the key of the reply of cmd in the cache,
+// ok is false when cmd can't be cached.
+func (cc *clientCache) cacheKey(ctx context.Context, cmd Cmder) (key, redisKey string, ok bool) {
+	if !cacheableCommands[cmd.Name()] || ctx.Value(noClientCacheKey{}) != nil {
+		return "", "", false
+	}
+	if _, ok := cmd.(*WriterCmd); ok {
+		// Streamed replies are never buffered.
+		return "", "", false
+	}
+
+	redisKey = cmd.stringArg(1)
+	if cc.opt.Broadcast && len(cc.opt.Prefixes) > 0 && !hasAnyPrefix(redisKey, cc.opt.Prefixes) {
+		return "", "", false
+	}
+
+	var b strings.Builder
+	if err := proto.NewWriter(&b).WriteArgs(cmd.Args()); err != nil {
+		return "", "", false
+	}
+	return b.String(), redisKey, true
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// load reads the reply of cmd from the cache, ok is false on a cache miss.
+func (cc *clientCache) load(ctx context.Context, cmd Cmder) (ok bool, err error) {
+	key, _, ok := cc.cacheKey(ctx, cmd)
+	if !ok {
+		return false, nil
+	}
+
+	cc.mu.Lock()
+	e := cc.entries[key]
+	if cc.disabled || e == nil || e.raw == nil {
+		cc.mu.Unlock()
+		return false, nil
+	}
+	if !e.expiresAt.IsZero() && time.Now().After(e.expiresAt) {
+		cc.remove(e)
+		cc.mu.Unlock()
+		return false, nil
+	}
+	cc.lru.MoveToFront(e.elem)
+	raw := e.raw
+	cc.mu.Unlock()
+
+	rd := cacheReaders.Get().(*proto.Reader)
+	rd.Reset(bytes.NewReader(raw))
+	err = readCmdReply(rd, cmd)
+	cacheReaders.Put(rd)
+	return true, err
+}
+
+// reserve adds a pending entry for the reply of cmd before it is sent using cn.
+// Invalidation messages received while the reply is read drop the entry,
+// so a stale reply is never cached. It returns nil if the reply can't be cached.
+func (cc *clientCache) reserve(ctx context.Context, cn *pool.Conn, cmd Cmder) *cacheEntry {
+	key, redisKey, ok := cc.cacheKey(ctx, cmd)
+	if !ok {
+		return nil
+	}
+
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	if cc.disabled || cc.redirect == 0 || cn.TrackingRedirect != cc.redirect {
+		return nil
+	}
+
+	if old := cc.entries[key]; old != nil {
+		cc.remove(old)
+	}
+
+	e := &cacheEntry{
+		key:      key,
+		redisKey: redisKey,
+	}
+	e.elem = cc.lru.PushFront(e)
+	cc.entries[key] = e
+	if cc.keys[redisKey] == nil {
+		cc.keys[redisKey] = make(map[*cacheEntry]struct{})
+	}
+	cc.keys[redisKey][e] = struct{}{}
+
+	for cc.lru.Len() > cc.opt.MaxEntries {
+		cc.remove(cc.lru.Back().Value.(*cacheEntry))
+	}
+	return e
+}
+
+// readReply reads the reply of cmd and stores it in the pending entry e.
+func (cc *clientCache) readReply(rd *proto.Reader, e *cacheEntry, cmd Cmder) error {
+	raw, err := rd.ReadRaw()
+	if err != nil {
+		cc.release(e)
+		return err
+	}
+
+	crd := cacheReaders.Get().(*proto.Reader)
+	crd.Reset(bytes.NewReader(raw))
+	err = readCmdReply(crd, cmd)
+	cacheReaders.Put(crd)
+
+	if err != nil && err != Nil {
+		cc.release(e)
+		return err
+	}
+
+	cc.mu.Lock()
+	if cc.entries[e.key] == e {
+		e.raw = raw
+		if cc.opt.TTL > 0 {
+			e.expiresAt = time.Now().Add(cc.opt.TTL)
+		}
+	}
+	cc.mu.Unlock()
+
+	return err
+}
+
+func (cc *clientCache) release(e *cacheEntry) {
+	cc.mu.Lock()
+	if cc.entries[e.key] == e {
+		cc.remove(e)
+	}
+	cc.mu.Unlock()
+}
+
+func (cc *clientCache) remove(e *cacheEntry) {
+	if cc.entries[e.key] == e {
+		delete(cc.entries, e.key)
+	}
+	if entries := cc.keys[e.redisKey]; entries != nil {
+		delete(entries, e)
+		if len(entries) == 0 {
+			delete(cc.keys, e.redisKey)
+		}
+	}
+	cc.lru.Remove(e.elem)
+}
+
+func (cc *clientCache) flush() {
+	cc.entries = make(map[string]*cacheEntry)
+	cc.keys = make(map[string]map[*cacheEntry]struct{})
+	cc.lru.Init()
+}
@@ -0,0 +1,117 @@
+package redis_test
+
+import (
+	"time"
+
+	. "github.com/bsm/ginkgo/v2"
+	. "github.com/bsm/gomega"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var _ = Describe("client-side cache", func() {
+	var client, other *redis.Client
+
+	getCalls := func() string {
+		info, err := other.Info(ctx, "commandstats").Result()
+		Expect(err).NotTo(HaveOccurred())
+		return info
+	}
+
+	BeforeEach(func() {
+		other = redis.NewClient(redisOptions())
+		Expect(other.FlushDB(ctx).Err()).NotTo(HaveOccurred())
+		Expect(other.Set(ctx, "key", "hello", 0).Err()).NotTo(HaveOccurred())
+
+		opt := redisOptions()
+		opt.ClientCache = &redis.ClientCacheOptions{}
+		client = redis.NewClient(opt)
+	})
+
+	AfterEach(func() {
+		Expect(client.Close()).NotTo(HaveOccurred())
+		Expect(other.Close()).NotTo(HaveOccurred())
+	})
+
+	It("serves repeated reads from memory", func() {
+		Eventually(func() string {
+			Expect(other.ConfigResetStat(ctx).Err()).NotTo(HaveOccurred())
+			for i := 0; i < 3; i++ {
+				Expect(client.Get(ctx, "key").Val()).To(Equal("hello"))
+			}
+			return getCalls()
+		}).Should(ContainSubstring("cmdstat_get:calls=1,"))
+	})
+
+	It("drops invalidated keys", func() {
+		Expect(client.Get(ctx, "key").Val()).To(Equal("hello"))
+		Expect(other.Set(ctx, "key", "world", 0).Err()).NotTo(HaveOccurred())
+
+		Eventually(func() string {
+			return client.Get(ctx, "key").Val()
+		}).Should(Equal("world"))
+	})
+
+	It("caches missing keys", func() {
+		Expect(client.Get(ctx, "missing").Err()).To(Equal(redis.Nil))
+		Expect(other.Set(ctx, "missing", "found", 0).Err()).NotTo(HaveOccurred())
+
+		Eventually(func() error {
+			return client.Get(ctx, "missing").Err()
+		}).ShouldNot(HaveOccurred())
+	})
+
+	It("is bypassed with WithoutClientCache", func() {
+		nocache := redis.WithoutClientCache(ctx)
+		Expect(other.ConfigResetStat(ctx).Err()).NotTo(HaveOccurred())
+		for i := 0; i < 3; i++ {
+			Expect(client.Get(nocache, "key").Val()).To(Equal("hello"))
+		}
+		Expect(getCalls()).To(ContainSubstring("cmdstat_get:calls=3,"))
+	})
+
+	It("expires replies after TTL", func() {
+		opt := redisOptions()
+		opt.ClientCache = &redis.ClientCacheOptions{TTL: 100 * time.Millisecond}
+		ttlClient := redis.NewClient(opt)
+		defer ttlClient.Close()
+
+		Eventually(func() string {
+			Expect(other.ConfigResetStat(ctx).Err()).NotTo(HaveOccurred())
+			Expect(ttlClient.Get(ctx, "key").Val()).To(Equal("hello"))
+			Expect(ttlClient.Get(ctx, "key").Val()).To(Equal("hello"))
+			time.Sleep(200 * time.Millisecond)
+			Expect(ttlClient.Get(ctx, "key").Val()).To(Equal("hello"))
+			return getCalls()
+		}).Should(ContainSubstring("cmdstat_get:calls=2,"))
+	})
+
+	It("does not modify the options", func() {
+		cacheOpt := &redis.ClientCacheOptions{}
+		opt := redisOptions()
+		opt.ClientCache = cacheOpt
+		c := redis.NewClient(opt)
+		defer c.Close()
+
+		Expect(cacheOpt.MaxEntries).To(Equal(0))
+	})
+
+	It("is not shared with Conn", func() {
+		Expect(client.Get(ctx, "key").Val()).To(Equal("hello"))
+
+		conn := client.Conn()
+		defer conn.Close()
+
+		Expect(conn.Select(ctx, 1).Err()).NotTo(HaveOccurred())
+		Expect(conn.Set(ctx, "key", "db1", 0).Err()).NotTo(HaveOccurred())
+		Expect(conn.Get(ctx, "key").Val()).To(Equal("db1"))
+		Expect(conn.Del(ctx, "key").Err()).NotTo(HaveOccurred())
+		Expect(conn.Select(ctx, redisOptions().DB).Err()).NotTo(HaveOccurred())
+	})
+
+	It("does not cache EXISTS", func() {
+		Expect(client.Exists(ctx, "key", "other").Val()).To(Equal(int64(1)))
+		Expect(other.Set(ctx, "other", "world", 0).Err()).NotTo(HaveOccurred())
+		Expect(client.Exists(ctx, "key", "other").Val()).To(Equal(int64(2)))
+	})
+})
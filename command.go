@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"context"
 	"fmt"
+	"io"
 	"net"
 	"regexp"
 	"strconv"
@@ -43,6 +44,11 @@ type Cmder interface {
 
 	SetErr(error)
 	Err() error
+
+	// RESP3 attributes sent by the server along with the reply, nil if there were none.
+	// e.g. "|1 key-popularity %1 key 0.5 ..." -> "map[key-popularity:map[key:0.5]]".
+	Attributes() map[string]interface{}
+	setAttributes(map[string]interface{})
 }
 
 func setCmdsErr(cmds []Cmder, e error) {
@@ -75,6 +81,14 @@ func writeCmd(wr *proto.Writer, cmd Cmder) error {
 	return wr.WriteArgs(cmd.Args())
 }
 
+// readCmdReply reads the reply of cmd and keeps the attributes sent with it.
+func readCmdReply(rd *proto.Reader, cmd Cmder) error {
+	_ = rd.TakeAttributes()
+	err := cmd.readReply(rd)
+	cmd.setAttributes(rd.TakeAttributes())
+	return err
+}
+
 func cmdFirstKeyPos(cmd Cmder) int {
 	if pos := cmd.firstKeyPos(); pos != 0 {
 		return int(pos)
@@ -126,6 +140,7 @@ type baseCmd struct {
 	args   []interface{}
 	err    error
 	keyPos int8
+	attrs  map[string]interface{}
 
 	_readTimeout *time.Duration
 }
@@ -189,6 +204,14 @@ func (cmd *baseCmd) Err() error {
 	return cmd.err
 }
 
+func (cmd *baseCmd) Attributes() map[string]interface{} {
+	return cmd.attrs
+}
+
+func (cmd *baseCmd) setAttributes(attrs map[string]interface{}) {
+	cmd.attrs = attrs
+}
+
 func (cmd *baseCmd) readTimeout() *time.Duration {
 	return cmd._readTimeout
 }
@@ -666,11 +689,13 @@ func (cmd *IntSliceCmd) readReply(rd *proto.Reader) error {
 	if err != nil {
 		return err
 	}
-	cmd.val = make([]int64, n)
-	for i := 0; i < len(cmd.val); i++ {
-		if cmd.val[i], err = rd.ReadInt(); err != nil {
+	cmd.val = make([]int64, 0, proto.PreallocLen(n))
+	for i := 0; i < n; i++ {
+		num, err := rd.ReadInt()
+		if err != nil {
 			return err
 		}
+		cmd.val = append(cmd.val, num)
 	}
 	return nil
 }
@@ -932,6 +957,54 @@ func (cmd *StringCmd) readReply(rd *proto.Reader) (err error) {
 
 //------------------------------------------------------------------------------
 
+// WriterCmd copies a string reply to an io.Writer while it is read,
+// Val returns the number of bytes written.
+type WriterCmd struct {
+	baseCmd
+
+	w   io.Writer
+	val int64
+}
+
+var _ Cmder = (*WriterCmd)(nil)
+
+func NewWriterCmd(ctx context.Context, w io.Writer, args ...interface{}) *WriterCmd {
+	return &WriterCmd{
+		baseCmd: baseCmd{
+			ctx:  ctx,
+			args: args,
+		},
+		w: w,
+	}
+}
+
+func (cmd *WriterCmd) SetVal(val int64) {
+	cmd.val = val
+}
+
+func (cmd *WriterCmd) Val() int64 {
+	return cmd.val
+}
+
+func (cmd *WriterCmd) Result() (int64, error) {
+	return cmd.val, cmd.err
+}
+
+func (cmd *WriterCmd) String() string {
+	return cmdString(cmd, cmd.val)
+}
+
+func (cmd *WriterCmd) readReply(rd *proto.Reader) (err error) {
+	cmd.val, err = rd.ReadStringTo(cmd.w)
+	if err != nil && cmd.val > 0 {
+		// Part of the reply was written, so the command can't be retried.
+		err = fmt.Errorf("redis: reply was partially written: %w", err)
+	}
+	return err
+}
+
+//------------------------------------------------------------------------------
+
 type FloatCmd struct {
 	baseCmd
 
@@ -1011,15 +1084,15 @@ func (cmd *FloatSliceCmd) readReply(rd *proto.Reader) error {
 		return err
 	}
 
-	cmd.val = make([]float64, n)
-	for i := 0; i < len(cmd.val); i++ {
+	cmd.val = make([]float64, 0, proto.PreallocLen(n))
+	for i := 0; i < n; i++ {
 		switch num, err := rd.ReadFloat(); {
 		case err == Nil:
-			cmd.val[i] = 0
+			cmd.val = append(cmd.val, 0)
 		case err != nil:
 			return err
 		default:
-			cmd.val[i] = num
+			cmd.val = append(cmd.val, num)
 		}
 	}
 	return nil
@@ -1069,15 +1142,15 @@ func (cmd *StringSliceCmd) readReply(rd *proto.Reader) error {
 	if err != nil {
 		return err
 	}
-	cmd.val = make([]string, n)
-	for i := 0; i < len(cmd.val); i++ {
+	cmd.val = make([]string, 0, proto.PreallocLen(n))
+	for i := 0; i < n; i++ {
 		switch s, err := rd.ReadString(); {
 		case err == Nil:
-			cmd.val[i] = ""
+			cmd.val = append(cmd.val, "")
 		case err != nil:
 			return err
 		default:
-			cmd.val[i] = s
+			cmd.val = append(cmd.val, s)
 		}
 	}
 	return nil
@@ -1152,26 +1225,27 @@ func (cmd *KeyValueSliceCmd) readReply(rd *proto.Reader) error { // nolint:dupl
 	}
 	array := typ == proto.RespArray
 
-	if array {
-		cmd.val = make([]KeyValue, n)
-	} else {
-		cmd.val = make([]KeyValue, n/2)
+	if !array {
+		n /= 2
 	}
 
-	for i := 0; i < len(cmd.val); i++ {
+	cmd.val = make([]KeyValue, 0, proto.PreallocLen(n))
+	for i := 0; i < n; i++ {
 		if array {
 			if err = rd.ReadFixedArrayLen(2); err != nil {
 				return err
 			}
 		}
 
-		if cmd.val[i].Key, err = rd.ReadString(); err != nil {
+		var kv KeyValue
+		if kv.Key, err = rd.ReadString(); err != nil {
 			return err
 		}
 
-		if cmd.val[i].Value, err = rd.ReadString(); err != nil {
+		if kv.Value, err = rd.ReadString(); err != nil {
 			return err
 		}
+		cmd.val = append(cmd.val, kv)
 	}
 
 	return nil
@@ -1217,11 +1291,13 @@ func (cmd *BoolSliceCmd) readReply(rd *proto.Reader) error {
 	if err != nil {
 		return err
 	}
-	cmd.val = make([]bool, n)
-	for i := 0; i < len(cmd.val); i++ {
-		if cmd.val[i], err = rd.ReadBool(); err != nil {
+	cmd.val = make([]bool, 0, proto.PreallocLen(n))
+	for i := 0; i < n; i++ {
+		b, err := rd.ReadBool()
+		if err != nil {
 			return err
 		}
+		cmd.val = append(cmd.val, b)
 	}
 	return nil
 }
@@ -1288,7 +1364,7 @@ func (cmd *MapStringStringCmd) readReply(rd *proto.Reader) error {
 		return err
 	}
 
-	cmd.val = make(map[string]string, n)
+	cmd.val = make(map[string]string, proto.PreallocLen(n))
 	for i := 0; i < n; i++ {
 		key, err := rd.ReadString()
 		if err != nil {
@@ -1346,7 +1422,7 @@ func (cmd *MapStringIntCmd) readReply(rd *proto.Reader) error {
 		return err
 	}
 
-	cmd.val = make(map[string]int64, n)
+	cmd.val = make(map[string]int64, proto.PreallocLen(n))
 	for i := 0; i < n; i++ {
 		key, err := rd.ReadString()
 		if err != nil {
@@ -1398,7 +1474,7 @@ func (cmd *MapStringSliceInterfaceCmd) readReply(rd *proto.Reader) (err error) {
 	if err != nil {
 		return err
 	}
-	cmd.val = make(map[string][]interface{}, n)
+	cmd.val = make(map[string][]interface{}, proto.PreallocLen(n))
 	for i := 0; i < n; i++ {
 		k, err := rd.ReadString()
 		if err != nil {
@@ -1408,14 +1484,15 @@ func (cmd *MapStringSliceInterfaceCmd) readReply(rd *proto.Reader) (err error) {
 		if err != nil {
 			return err
 		}
-		cmd.val[k] = make([]interface{}, nn)
+		vals := make([]interface{}, 0, proto.PreallocLen(nn))
 		for j := 0; j < nn; j++ {
 			value, err := rd.ReadReply()
 			if err != nil {
 				return err
 			}
-			cmd.val[k][j] = value
+			vals = append(vals, value)
 		}
+		cmd.val[k] = vals
 	}
 
 	return nil
@@ -1462,7 +1539,7 @@ func (cmd *StringStructMapCmd) readReply(rd *proto.Reader) error {
 		return err
 	}
 
-	cmd.val = make(map[string]struct{}, n)
+	cmd.val = make(map[string]struct{}, proto.PreallocLen(n))
 	for i := 0; i < n; i++ {
 		key, err := rd.ReadString()
 		if err != nil {
@@ -1524,11 +1601,13 @@ func readXMessageSlice(rd *proto.Reader) ([]XMessage, error) {
 		return nil, err
 	}
 
-	msgs := make([]XMessage, n)
-	for i := 0; i < len(msgs); i++ {
-		if msgs[i], err = readXMessage(rd); err != nil {
+	msgs := make([]XMessage, 0, proto.PreallocLen(n))
+	for i := 0; i < n; i++ {
+		msg, err := readXMessage(rd)
+		if err != nil {
 			return nil, err
 		}
+		msgs = append(msgs, msg)
 	}
 	return msgs, nil
 }
@@ -1562,7 +1641,7 @@ func stringInterfaceMapParser(rd *proto.Reader) (map[string]interface{}, error)
 		return nil, err
 	}
 
-	m := make(map[string]interface{}, n)
+	m := make(map[string]interface{}, proto.PreallocLen(n))
 	for i := 0; i < n; i++ {
 		key, err := rd.ReadString()
 		if err != nil {
@@ -1634,19 +1713,21 @@ func (cmd *XStreamSliceCmd) readReply(rd *proto.Reader) error {
 	if err != nil {
 		return err
 	}
-	cmd.val = make([]XStream, n)
-	for i := 0; i < len(cmd.val); i++ {
+	cmd.val = make([]XStream, 0, proto.PreallocLen(n))
+	for i := 0; i < n; i++ {
 		if typ != proto.RespMap {
 			if err = rd.ReadFixedArrayLen(2); err != nil {
 				return err
 			}
 		}
-		if cmd.val[i].Stream, err = rd.ReadString(); err != nil {
+		var stream XStream
+		if stream.Stream, err = rd.ReadString(); err != nil {
 			return err
 		}
-		if cmd.val[i].Messages, err = readXMessageSlice(rd); err != nil {
+		if stream.Messages, err = readXMessageSlice(rd); err != nil {
 			return err
 		}
+		cmd.val = append(cmd.val, stream)
 	}
 	return nil
 }
@@ -1715,7 +1796,7 @@ func (cmd *XPendingCmd) readReply(rd *proto.Reader) error {
 	if err != nil && err != Nil {
 		return err
 	}
-	cmd.val.Consumers = make(map[string]int64, n)
+	cmd.val.Consumers = make(map[string]int64, proto.PreallocLen(n))
 	for i := 0; i < n; i++ {
 		if err = rd.ReadFixedArrayLen(2); err != nil {
 			return err
@@ -1780,18 +1861,19 @@ func (cmd *XPendingExtCmd) readReply(rd *proto.Reader) error {
 	if err != nil {
 		return err
 	}
-	cmd.val = make([]XPendingExt, n)
+	cmd.val = make([]XPendingExt, 0, proto.PreallocLen(n))
 
-	for i := 0; i < len(cmd.val); i++ {
+	for i := 0; i < n; i++ {
 		if err = rd.ReadFixedArrayLen(4); err != nil {
 			return err
 		}
 
-		if cmd.val[i].ID, err = rd.ReadString(); err != nil {
+		var pending XPendingExt
+		if pending.ID, err = rd.ReadString(); err != nil {
 			return err
 		}
 
-		if cmd.val[i].Consumer, err = rd.ReadString(); err != nil && err != Nil {
+		if pending.Consumer, err = rd.ReadString(); err != nil && err != Nil {
 			return err
 		}
 
@@ -1799,11 +1881,12 @@ func (cmd *XPendingExtCmd) readReply(rd *proto.Reader) error {
 		if err != nil && err != Nil {
 			return err
 		}
-		cmd.val[i].Idle = time.Duration(idle) * time.Millisecond
+		pending.Idle = time.Duration(idle) * time.Millisecond
 
-		if cmd.val[i].RetryCount, err = rd.ReadInt(); err != nil && err != Nil {
+		if pending.RetryCount, err = rd.ReadInt(); err != nil && err != Nil {
 			return err
 		}
+		cmd.val = append(cmd.val, pending)
 	}
 
 	return nil
@@ -1940,12 +2023,13 @@ func (cmd *XAutoClaimJustIDCmd) readReply(rd *proto.Reader) error {
 		return err
 	}
 
-	cmd.val = make([]string, nn)
+	cmd.val = make([]string, 0, proto.PreallocLen(nn))
 	for i := 0; i < nn; i++ {
-		cmd.val[i], err = rd.ReadString()
+		id, err := rd.ReadString()
 		if err != nil {
 			return err
 		}
+		cmd.val = append(cmd.val, id)
 	}
 
 	if n >= 3 {
@@ -2003,9 +2087,10 @@ func (cmd *XInfoConsumersCmd) readReply(rd *proto.Reader) error {
 	if err != nil {
 		return err
 	}
-	cmd.val = make([]XInfoConsumer, n)
+	cmd.val = make([]XInfoConsumer, 0, proto.PreallocLen(n))
 
-	for i := 0; i < len(cmd.val); i++ {
+	for i := 0; i < n; i++ {
+		var consumer XInfoConsumer
 		nn, err := rd.ReadMapLen()
 		if err != nil {
 			return err
@@ -2020,17 +2105,17 @@ func (cmd *XInfoConsumersCmd) readReply(rd *proto.Reader) error {
 
 			switch key {
 			case "name":
-				cmd.val[i].Name, err = rd.ReadString()
+				consumer.Name, err = rd.ReadString()
 			case "pending":
-				cmd.val[i].Pending, err = rd.ReadInt()
+				consumer.Pending, err = rd.ReadInt()
 			case "idle":
 				var idle int64
 				idle, err = rd.ReadInt()
-				cmd.val[i].Idle = time.Duration(idle) * time.Millisecond
+				consumer.Idle = time.Duration(idle) * time.Millisecond
 			case "inactive":
 				var inactive int64
 				inactive, err = rd.ReadInt()
-				cmd.val[i].Inactive = time.Duration(inactive) * time.Millisecond
+				consumer.Inactive = time.Duration(inactive) * time.Millisecond
 			default:
 				return fmt.Errorf("redis: unexpected content %s in XINFO CONSUMERS reply", key)
 			}
@@ -2038,6 +2123,7 @@ func (cmd *XInfoConsumersCmd) readReply(rd *proto.Reader) error {
 				return err
 			}
 		}
+		cmd.val = append(cmd.val, consumer)
 	}
 
 	return nil
@@ -2091,10 +2177,10 @@ func (cmd *XInfoGroupsCmd) readReply(rd *proto.Reader) error {
 	if err != nil {
 		return err
 	}
-	cmd.val = make([]XInfoGroup, n)
+	cmd.val = make([]XInfoGroup, 0, proto.PreallocLen(n))
 
-	for i := 0; i < len(cmd.val); i++ {
-		group := &cmd.val[i]
+	for i := 0; i < n; i++ {
+		var group XInfoGroup
 
 		nn, err := rd.ReadMapLen()
 		if err != nil {
@@ -2146,6 +2232,7 @@ func (cmd *XInfoGroupsCmd) readReply(rd *proto.Reader) error {
 				return fmt.Errorf("redis: unexpected key %q in XINFO GROUPS reply", key)
 			}
 		}
+		cmd.val = append(cmd.val, group)
 	}
 
 	return nil
@@ -2417,7 +2504,7 @@ func readStreamGroups(rd *proto.Reader) ([]XInfoStreamGroup, error) {
 	if err != nil {
 		return nil, err
 	}
-	groups := make([]XInfoStreamGroup, 0, n)
+	groups := make([]XInfoStreamGroup, 0, proto.PreallocLen(n))
 	for i := 0; i < n; i++ {
 		nn, err := rd.ReadMapLen()
 		if err != nil {
@@ -2487,7 +2574,7 @@ func readXInfoStreamGroupPending(rd *proto.Reader) ([]XInfoStreamGroupPending, e
 		return nil, err
 	}
 
-	pending := make([]XInfoStreamGroupPending, 0, n)
+	pending := make([]XInfoStreamGroupPending, 0, proto.PreallocLen(n))
 
 	for i := 0; i < n; i++ {
 		if err = rd.ReadFixedArrayLen(4); err != nil {
@@ -2529,7 +2616,7 @@ func readXInfoStreamConsumers(rd *proto.Reader) ([]XInfoStreamConsumer, error) {
 		return nil, err
 	}
 
-	consumers := make([]XInfoStreamConsumer, 0, n)
+	consumers := make([]XInfoStreamConsumer, 0, proto.PreallocLen(n))
 
 	for i := 0; i < n; i++ {
 		nn, err := rd.ReadMapLen()
@@ -2568,7 +2655,7 @@ func readXInfoStreamConsumers(rd *proto.Reader) ([]XInfoStreamConsumer, error) {
 					return nil, err
 				}
 
-				c.Pending = make([]XInfoStreamConsumerPending, 0, pendingNumber)
+				c.Pending = make([]XInfoStreamConsumerPending, 0, proto.PreallocLen(pendingNumber))
 
 				for pn := 0; pn < pendingNumber; pn++ {
 					if err = rd.ReadFixedArrayLen(3); err != nil {
@@ -2662,26 +2749,27 @@ func (cmd *ZSliceCmd) readReply(rd *proto.Reader) error { // nolint:dupl
 	}
 	array := typ == proto.RespArray
 
-	if array {
-		cmd.val = make([]Z, n)
-	} else {
-		cmd.val = make([]Z, n/2)
+	if !array {
+		n /= 2
 	}
 
-	for i := 0; i < len(cmd.val); i++ {
+	cmd.val = make([]Z, 0, proto.PreallocLen(n))
+	for i := 0; i < n; i++ {
 		if array {
 			if err = rd.ReadFixedArrayLen(2); err != nil {
 				return err
 			}
 		}
 
-		if cmd.val[i].Member, err = rd.ReadString(); err != nil {
+		var z Z
+		if z.Member, err = rd.ReadString(); err != nil {
 			return err
 		}
 
-		if cmd.val[i].Score, err = rd.ReadFloat(); err != nil {
+		if z.Score, err = rd.ReadFloat(); err != nil {
 			return err
 		}
+		cmd.val = append(cmd.val, z)
 	}
 
 	return nil
@@ -2796,12 +2884,14 @@ func (cmd *ScanCmd) readReply(rd *proto.Reader) error {
 	if err != nil {
 		return err
 	}
-	cmd.page = make([]string, n)
+	cmd.page = make([]string, 0, proto.PreallocLen(n))
 
-	for i := 0; i < len(cmd.page); i++ {
-		if cmd.page[i], err = rd.ReadString(); err != nil {
+	for i := 0; i < n; i++ {
+		key, err := rd.ReadString()
+		if err != nil {
 			return err
 		}
+		cmd.page = append(cmd.page, key)
 	}
 	return nil
 }
@@ -2865,15 +2955,15 @@ func (cmd *ClusterSlotsCmd) readReply(rd *proto.Reader) error {
 	if err != nil {
 		return err
 	}
-	cmd.val = make([]ClusterSlot, n)
+	cmd.val = make([]ClusterSlot, 0, proto.PreallocLen(n))
 
-	for i := 0; i < len(cmd.val); i++ {
-		n, err = rd.ReadArrayLen()
+	for i := 0; i < n; i++ {
+		m, err := rd.ReadArrayLen()
 		if err != nil {
 			return err
 		}
-		if n < 2 {
-			return fmt.Errorf("redis: got %d elements in cluster info, expected at least 2", n)
+		if m < 2 {
+			return fmt.Errorf("redis: got %d elements in cluster info, expected at least 2", m)
 		}
 
 		start, err := rd.ReadInt()
@@ -2887,15 +2977,15 @@ func (cmd *ClusterSlotsCmd) readReply(rd *proto.Reader) error {
 		}
 
 		// subtract start and end.
-		nodes := make([]ClusterNode, n-2)
+		nodes := make([]ClusterNode, 0, proto.PreallocLen(m-2))
 
-		for j := 0; j < len(nodes); j++ {
+		for j := 0; j < m-2; j++ {
 			nn, err := rd.ReadArrayLen()
 			if err != nil {
 				return err
 			}
 			if nn < 2 || nn > 4 {
-				return fmt.Errorf("got %d elements in cluster info address, expected 2, 3, or 4", n)
+				return fmt.Errorf("got %d elements in cluster info address, expected 2, 3, or 4", nn)
 			}
 
 			ip, err := rd.ReadString()
@@ -2908,14 +2998,14 @@ func (cmd *ClusterSlotsCmd) readReply(rd *proto.Reader) error {
 				return err
 			}
 
-			nodes[j].Addr = net.JoinHostPort(ip, port)
+			node := ClusterNode{Addr: net.JoinHostPort(ip, port)}
 
 			if nn >= 3 {
 				id, err := rd.ReadString()
 				if err != nil {
 					return err
 				}
-				nodes[j].ID = id
+				node.ID = id
 			}
 
 			if nn >= 4 {
@@ -2924,7 +3014,7 @@ func (cmd *ClusterSlotsCmd) readReply(rd *proto.Reader) error {
 					return err
 				}
 
-				networkingMetadata := make(map[string]string, metadataLength)
+				networkingMetadata := make(map[string]string, proto.PreallocLen(metadataLength))
 
 				for i := 0; i < metadataLength; i++ {
 					key, err := rd.ReadString()
@@ -2938,15 +3028,16 @@ func (cmd *ClusterSlotsCmd) readReply(rd *proto.Reader) error {
 					networkingMetadata[key] = value
 				}
 
-				nodes[j].NetworkingMetadata = networkingMetadata
+				node.NetworkingMetadata = networkingMetadata
 			}
+			nodes = append(nodes, node)
 		}
 
-		cmd.val[i] = ClusterSlot{
+		cmd.val = append(cmd.val, ClusterSlot{
 			Start: int(start),
 			End:   int(end),
 			Nodes: nodes,
-		}
+		})
 	}
 
 	return nil
@@ -3055,14 +3146,16 @@ func (cmd *GeoLocationCmd) readReply(rd *proto.Reader) error {
 	if err != nil {
 		return err
 	}
-	cmd.locations = make([]GeoLocation, n)
+	cmd.locations = make([]GeoLocation, 0, proto.PreallocLen(n))
 
-	for i := 0; i < len(cmd.locations); i++ {
+	for i := 0; i < n; i++ {
+		var loc GeoLocation
 		// only name
 		if cmd.q.withLen == 0 {
-			if cmd.locations[i].Name, err = rd.ReadString(); err != nil {
+			if loc.Name, err = rd.ReadString(); err != nil {
 				return err
 			}
+			cmd.locations = append(cmd.locations, loc)
 			continue
 		}
 
@@ -3071,16 +3164,16 @@ func (cmd *GeoLocationCmd) readReply(rd *proto.Reader) error {
 			return err
 		}
 
-		if cmd.locations[i].Name, err = rd.ReadString(); err != nil {
+		if loc.Name, err = rd.ReadString(); err != nil {
 			return err
 		}
 		if cmd.q.WithDist {
-			if cmd.locations[i].Dist, err = rd.ReadFloat(); err != nil {
+			if loc.Dist, err = rd.ReadFloat(); err != nil {
 				return err
 			}
 		}
 		if cmd.q.WithGeoHash {
-			if cmd.locations[i].GeoHash, err = rd.ReadInt(); err != nil {
+			if loc.GeoHash, err = rd.ReadInt(); err != nil {
 				return err
 			}
 		}
@@ -3088,13 +3181,14 @@ func (cmd *GeoLocationCmd) readReply(rd *proto.Reader) error {
 			if err = rd.ReadFixedArrayLen(2); err != nil {
 				return err
 			}
-			if cmd.locations[i].Longitude, err = rd.ReadFloat(); err != nil {
+			if loc.Longitude, err = rd.ReadFloat(); err != nil {
 				return err
 			}
-			if cmd.locations[i].Latitude, err = rd.ReadFloat(); err != nil {
+			if loc.Latitude, err = rd.ReadFloat(); err != nil {
 				return err
 			}
 		}
+		cmd.locations = append(cmd.locations, loc)
 	}
 
 	return nil
@@ -3236,7 +3330,7 @@ func (cmd *GeoSearchLocationCmd) readReply(rd *proto.Reader) error {
 		return err
 	}
 
-	cmd.val = make([]GeoLocation, n)
+	cmd.val = make([]GeoLocation, 0, proto.PreallocLen(n))
 	for i := 0; i < n; i++ {
 		_, err = rd.ReadArrayLen()
 		if err != nil {
@@ -3275,7 +3369,7 @@ func (cmd *GeoSearchLocationCmd) readReply(rd *proto.Reader) error {
 			}
 		}
 
-		cmd.val[i] = loc
+		cmd.val = append(cmd.val, loc)
 	}
 
 	return nil
@@ -3325,13 +3419,13 @@ func (cmd *GeoPosCmd) readReply(rd *proto.Reader) error {
 	if err != nil {
 		return err
 	}
-	cmd.val = make([]*GeoPos, n)
+	cmd.val = make([]*GeoPos, 0, proto.PreallocLen(n))
 
-	for i := 0; i < len(cmd.val); i++ {
+	for i := 0; i < n; i++ {
 		err = rd.ReadFixedArrayLen(2)
 		if err != nil {
 			if err == Nil {
-				cmd.val[i] = nil
+				cmd.val = append(cmd.val, nil)
 				continue
 			}
 			return err
@@ -3346,10 +3440,10 @@ func (cmd *GeoPosCmd) readReply(rd *proto.Reader) error {
 			return err
 		}
 
-		cmd.val[i] = &GeoPos{
+		cmd.val = append(cmd.val, &GeoPos{
 			Longitude: longitude,
 			Latitude:  latitude,
-		}
+		})
 	}
 
 	return nil
@@ -3410,7 +3504,7 @@ func (cmd *CommandsInfoCmd) readReply(rd *proto.Reader) error {
 	if err != nil {
 		return err
 	}
-	cmd.val = make(map[string]*CommandInfo, n)
+	cmd.val = make(map[string]*CommandInfo, proto.PreallocLen(n))
 
 	for i := 0; i < n; i++ {
 		nn, err := rd.ReadArrayLen()
@@ -3440,18 +3534,18 @@ func (cmd *CommandsInfoCmd) readReply(rd *proto.Reader) error {
 		if err != nil {
 			return err
 		}
-		cmdInfo.Flags = make([]string, flagLen)
-		for f := 0; f < len(cmdInfo.Flags); f++ {
+		cmdInfo.Flags = make([]string, 0, proto.PreallocLen(flagLen))
+		for f := 0; f < flagLen; f++ {
 			switch s, err := rd.ReadString(); {
 			case err == Nil:
-				cmdInfo.Flags[f] = ""
+				cmdInfo.Flags = append(cmdInfo.Flags, "")
 			case err != nil:
 				return err
 			default:
 				if !cmdInfo.ReadOnly && s == "readonly" {
 					cmdInfo.ReadOnly = true
 				}
-				cmdInfo.Flags[f] = s
+				cmdInfo.Flags = append(cmdInfo.Flags, s)
 			}
 		}
 
@@ -3478,15 +3572,15 @@ func (cmd *CommandsInfoCmd) readReply(rd *proto.Reader) error {
 			if err != nil {
 				return err
 			}
-			cmdInfo.ACLFlags = make([]string, aclFlagLen)
-			for f := 0; f < len(cmdInfo.ACLFlags); f++ {
+			cmdInfo.ACLFlags = make([]string, 0, proto.PreallocLen(aclFlagLen))
+			for f := 0; f < aclFlagLen; f++ {
 				switch s, err := rd.ReadString(); {
 				case err == Nil:
-					cmdInfo.ACLFlags[f] = ""
+					cmdInfo.ACLFlags = append(cmdInfo.ACLFlags, "")
 				case err != nil:
 					return err
 				default:
-					cmdInfo.ACLFlags[f] = s
+					cmdInfo.ACLFlags = append(cmdInfo.ACLFlags, s)
 				}
 			}
 		}
@@ -3596,9 +3690,10 @@ func (cmd *SlowLogCmd) readReply(rd *proto.Reader) error {
 	if err != nil {
 		return err
 	}
-	cmd.val = make([]SlowLog, n)
+	cmd.val = make([]SlowLog, 0, proto.PreallocLen(n))
 
-	for i := 0; i < len(cmd.val); i++ {
+	for i := 0; i < n; i++ {
+		var entry SlowLog
 		nn, err := rd.ReadArrayLen()
 		if err != nil {
 			return err
@@ -3607,7 +3702,7 @@ func (cmd *SlowLogCmd) readReply(rd *proto.Reader) error {
 			return fmt.Errorf("redis: got %d elements in slowlog get, expected at least 4", nn)
 		}
 
-		if cmd.val[i].ID, err = rd.ReadInt(); err != nil {
+		if entry.ID, err = rd.ReadInt(); err != nil {
 			return err
 		}
 
@@ -3615,13 +3710,13 @@ func (cmd *SlowLogCmd) readReply(rd *proto.Reader) error {
 		if err != nil {
 			return err
 		}
-		cmd.val[i].Time = time.Unix(createdAt, 0)
+		entry.Time = time.Unix(createdAt, 0)
 
 		costs, err := rd.ReadInt()
 		if err != nil {
 			return err
 		}
-		cmd.val[i].Duration = time.Duration(costs) * time.Microsecond
+		entry.Duration = time.Duration(costs) * time.Microsecond
 
 		cmdLen, err := rd.ReadArrayLen()
 		if err != nil {
@@ -3631,25 +3726,27 @@ func (cmd *SlowLogCmd) readReply(rd *proto.Reader) error {
 			return fmt.Errorf("redis: got %d elements commands reply in slowlog get, expected at least 1", cmdLen)
 		}
 
-		cmd.val[i].Args = make([]string, cmdLen)
-		for f := 0; f < len(cmd.val[i].Args); f++ {
-			cmd.val[i].Args[f], err = rd.ReadString()
+		entry.Args = make([]string, 0, proto.PreallocLen(cmdLen))
+		for f := 0; f < cmdLen; f++ {
+			arg, err := rd.ReadString()
 			if err != nil {
 				return err
 			}
+			entry.Args = append(entry.Args, arg)
 		}
 
 		if nn >= 5 {
-			if cmd.val[i].ClientAddr, err = rd.ReadString(); err != nil {
+			if entry.ClientAddr, err = rd.ReadString(); err != nil {
 				return err
 			}
 		}
 
 		if nn >= 6 {
-			if cmd.val[i].ClientName, err = rd.ReadString(); err != nil {
+			if entry.ClientName, err = rd.ReadString(); err != nil {
 				return err
 			}
 		}
+		cmd.val = append(cmd.val, entry)
 	}
 
 	return nil
@@ -3696,7 +3793,7 @@ func (cmd *MapStringInterfaceCmd) readReply(rd *proto.Reader) error {
 		return err
 	}
 
-	cmd.val = make(map[string]interface{}, n)
+	cmd.val = make(map[string]interface{}, proto.PreallocLen(n))
 	for i := 0; i < n; i++ {
 		k, err := rd.ReadString()
 		if err != nil {
@@ -3760,13 +3857,13 @@ func (cmd *MapStringStringSliceCmd) readReply(rd *proto.Reader) error {
 		return err
 	}
 
-	cmd.val = make([]map[string]string, n)
+	cmd.val = make([]map[string]string, 0, proto.PreallocLen(n))
 	for i := 0; i < n; i++ {
 		nn, err := rd.ReadMapLen()
 		if err != nil {
 			return err
 		}
-		cmd.val[i] = make(map[string]string, nn)
+		m := make(map[string]string, proto.PreallocLen(nn))
 		for f := 0; f < nn; f++ {
 			k, err := rd.ReadString()
 			if err != nil {
@@ -3777,8 +3874,9 @@ func (cmd *MapStringStringSliceCmd) readReply(rd *proto.Reader) error {
 			if err != nil {
 				return err
 			}
-			cmd.val[i][k] = v
+			m[k] = v
 		}
+		cmd.val = append(cmd.val, m)
 	}
 	return nil
 }
@@ -3824,13 +3922,13 @@ func (cmd *MapStringInterfaceSliceCmd) readReply(rd *proto.Reader) error {
 		return err
 	}
 
-	cmd.val = make([]map[string]interface{}, n)
+	cmd.val = make([]map[string]interface{}, 0, proto.PreallocLen(n))
 	for i := 0; i < n; i++ {
 		nn, err := rd.ReadMapLen()
 		if err != nil {
 			return err
 		}
-		cmd.val[i] = make(map[string]interface{}, nn)
+		m := make(map[string]interface{}, proto.PreallocLen(nn))
 		for f := 0; f < nn; f++ {
 			k, err := rd.ReadString()
 			if err != nil {
@@ -3842,8 +3940,9 @@ func (cmd *MapStringInterfaceSliceCmd) readReply(rd *proto.Reader) error {
 					return err
 				}
 			}
-			cmd.val[i][k] = v
+			m[k] = v
 		}
+		cmd.val = append(cmd.val, m)
 	}
 	return nil
 }
@@ -3899,12 +3998,13 @@ func (cmd *KeyValuesCmd) readReply(rd *proto.Reader) (err error) {
 	if err != nil {
 		return err
 	}
-	cmd.val = make([]string, n)
+	cmd.val = make([]string, 0, proto.PreallocLen(n))
 	for i := 0; i < n; i++ {
-		cmd.val[i], err = rd.ReadString()
+		s, err := rd.ReadString()
 		if err != nil {
 			return err
 		}
+		cmd.val = append(cmd.val, s)
 	}
 
 	return nil
@@ -3962,32 +4062,39 @@ func (cmd *ZSliceWithKeyCmd) readReply(rd *proto.Reader) (err error) {
 		return err
 	}
 
+	// If the n is 0, can't continue reading.
+	if n == 0 {
+		cmd.val = make([]Z, 0)
+		return nil
+	}
+
 	typ, err := rd.PeekReplyType()
 	if err != nil {
 		return err
 	}
 	array := typ == proto.RespArray
 
-	if array {
-		cmd.val = make([]Z, n)
-	} else {
-		cmd.val = make([]Z, n/2)
+	if !array {
+		n /= 2
 	}
 
-	for i := 0; i < len(cmd.val); i++ {
+	cmd.val = make([]Z, 0, proto.PreallocLen(n))
+	for i := 0; i < n; i++ {
 		if array {
 			if err = rd.ReadFixedArrayLen(2); err != nil {
 				return err
 			}
 		}
 
-		if cmd.val[i].Member, err = rd.ReadString(); err != nil {
+		var z Z
+		if z.Member, err = rd.ReadString(); err != nil {
 			return err
 		}
 
-		if cmd.val[i].Score, err = rd.ReadFloat(); err != nil {
+		if z.Score, err = rd.ReadFloat(); err != nil {
 			return err
 		}
+		cmd.val = append(cmd.val, z)
 	}
 
 	return nil
@@ -4055,7 +4162,7 @@ func (cmd *FunctionListCmd) readReply(rd *proto.Reader) (err error) {
 		return err
 	}
 
-	libraries := make([]Library, n)
+	libraries := make([]Library, 0, proto.PreallocLen(n))
 	for i := 0; i < n; i++ {
 		nn, err := rd.ReadMapLen()
 		if err != nil {
@@ -4087,7 +4194,7 @@ func (cmd *FunctionListCmd) readReply(rd *proto.Reader) (err error) {
 			}
 		}
 
-		libraries[i] = library
+		libraries = append(libraries, library)
 	}
 	cmd.val = libraries
 	return nil
@@ -4099,7 +4206,7 @@ func (cmd *FunctionListCmd) readFunctions(rd *proto.Reader) ([]Function, error)
 		return nil, err
 	}
 
-	functions := make([]Function, n)
+	functions := make([]Function, 0, proto.PreallocLen(n))
 	for i := 0; i < n; i++ {
 		nn, err := rd.ReadMapLen()
 		if err != nil {
@@ -4129,18 +4236,20 @@ func (cmd *FunctionListCmd) readFunctions(rd *proto.Reader) ([]Function, error)
 					return nil, err
 				}
 
-				function.Flags = make([]string, nx)
+				function.Flags = make([]string, 0, proto.PreallocLen(nx))
 				for j := 0; j < nx; j++ {
-					if function.Flags[j], err = rd.ReadString(); err != nil {
+					flag, err := rd.ReadString()
+					if err != nil {
 						return nil, err
 					}
+					function.Flags = append(function.Flags, flag)
 				}
 			default:
 				return nil, fmt.Errorf("redis: function list unexpected key %s", key)
 			}
 		}
 
-		functions[i] = function
+		functions = append(functions, function)
 	}
 	return functions, nil
 }
@@ -4295,7 +4404,7 @@ func (cmd *FunctionStatsCmd) readEngines(rd *proto.Reader) ([]Engine, error) {
 		return nil, err
 	}
 
-	engines := make([]Engine, 0, n)
+	engines := make([]Engine, 0, proto.PreallocLen(n))
 	for i := 0; i < n; i++ {
 		engine := Engine{}
 		engine.Language, err = rd.ReadString()
@@ -4340,7 +4449,7 @@ func (cmd *FunctionStatsCmd) readCommand(rd *proto.Reader) ([]string, error) {
 		return nil, err
 	}
 
-	command := make([]string, 0, n)
+	command := make([]string, 0, proto.PreallocLen(n))
 	for i := 0; i < n; i++ {
 		x, err := rd.ReadString()
 		if err != nil {
@@ -4358,7 +4467,7 @@ func (cmd *FunctionStatsCmd) readRunningScripts(rd *proto.Reader) ([]RunningScri
 		return nil, false, err
 	}
 
-	runningScripts := make([]RunningScript, 0, n)
+	runningScripts := make([]RunningScript, 0, proto.PreallocLen(n))
 	for i := 0; i < n; i++ {
 		rs, _, err := cmd.readRunningScript(rd)
 		if err != nil {
@@ -4507,26 +4616,28 @@ func (cmd *LCSCmd) readMatchedPositions(rd *proto.Reader) ([]LCSMatchedPosition,
 		return nil, err
 	}
 
-	positions := make([]LCSMatchedPosition, n)
+	positions := make([]LCSMatchedPosition, 0, proto.PreallocLen(n))
 	for i := 0; i < n; i++ {
+		var pos LCSMatchedPosition
 		pn, err := rd.ReadArrayLen()
 		if err != nil {
 			return nil, err
 		}
 
-		if positions[i].Key1, err = cmd.readPosition(rd); err != nil {
+		if pos.Key1, err = cmd.readPosition(rd); err != nil {
 			return nil, err
 		}
-		if positions[i].Key2, err = cmd.readPosition(rd); err != nil {
+		if pos.Key2, err = cmd.readPosition(rd); err != nil {
 			return nil, err
 		}
 
 		// read match length if WithMatchLen is true
 		if pn > 2 {
-			if positions[i].MatchLen, err = rd.ReadInt(); err != nil {
+			if pos.MatchLen, err = rd.ReadInt(); err != nil {
 				return nil, err
 			}
 		}
+		positions = append(positions, pos)
 	}
 
 	return positions, nil
@@ -4597,28 +4708,31 @@ func (cmd *KeyFlagsCmd) readReply(rd *proto.Reader) error {
 		return nil
 	}
 
-	cmd.val = make([]KeyFlags, n)
-
-	for i := 0; i < len(cmd.val); i++ {
+	cmd.val = make([]KeyFlags, 0, proto.PreallocLen(n))
 
+	for i := 0; i < n; i++ {
 		if err = rd.ReadFixedArrayLen(2); err != nil {
 			return err
 		}
 
-		if cmd.val[i].Key, err = rd.ReadString(); err != nil {
+		var keyFlags KeyFlags
+		if keyFlags.Key, err = rd.ReadString(); err != nil {
 			return err
 		}
 		flagsLen, err := rd.ReadArrayLen()
 		if err != nil {
 			return err
 		}
-		cmd.val[i].Flags = make([]string, flagsLen)
+		keyFlags.Flags = make([]string, 0, proto.PreallocLen(flagsLen))
 
 		for j := 0; j < flagsLen; j++ {
-			if cmd.val[i].Flags[j], err = rd.ReadString(); err != nil {
+			flag, err := rd.ReadString()
+			if err != nil {
 				return err
 			}
+			keyFlags.Flags = append(keyFlags.Flags, flag)
 		}
+		cmd.val = append(cmd.val, keyFlags)
 	}
 
 	return nil
@@ -4673,9 +4787,10 @@ func (cmd *ClusterLinksCmd) readReply(rd *proto.Reader) error {
 	if err != nil {
 		return err
 	}
-	cmd.val = make([]ClusterLink, n)
+	cmd.val = make([]ClusterLink, 0, proto.PreallocLen(n))
 
-	for i := 0; i < len(cmd.val); i++ {
+	for i := 0; i < n; i++ {
+		var link ClusterLink
 		m, err := rd.ReadMapLen()
 		if err != nil {
 			return err
@@ -4689,17 +4804,17 @@ func (cmd *ClusterLinksCmd) readReply(rd *proto.Reader) error {
 
 			switch key {
 			case "direction":
-				cmd.val[i].Direction, err = rd.ReadString()
+				link.Direction, err = rd.ReadString()
 			case "node":
-				cmd.val[i].Node, err = rd.ReadString()
+				link.Node, err = rd.ReadString()
 			case "create-time":
-				cmd.val[i].CreateTime, err = rd.ReadInt()
+				link.CreateTime, err = rd.ReadInt()
 			case "events":
-				cmd.val[i].Events, err = rd.ReadString()
+				link.Events, err = rd.ReadString()
 			case "send-buffer-allocated":
-				cmd.val[i].SendBufferAllocated, err = rd.ReadInt()
+				link.SendBufferAllocated, err = rd.ReadInt()
 			case "send-buffer-used":
-				cmd.val[i].SendBufferUsed, err = rd.ReadInt()
+				link.SendBufferUsed, err = rd.ReadInt()
 			default:
 				return fmt.Errorf("redis: unexpected key %q in CLUSTER LINKS reply", key)
 			}
@@ -4708,6 +4823,7 @@ func (cmd *ClusterLinksCmd) readReply(rd *proto.Reader) error {
 				return err
 			}
 		}
+		cmd.val = append(cmd.val, link)
 	}
 
 	return nil
@@ -4775,9 +4891,10 @@ func (cmd *ClusterShardsCmd) readReply(rd *proto.Reader) error {
 	if err != nil {
 		return err
 	}
-	cmd.val = make([]ClusterShard, n)
+	cmd.val = make([]ClusterShard, 0, proto.PreallocLen(n))
 
 	for i := 0; i < n; i++ {
+		var shard ClusterShard
 		m, err := rd.ReadMapLen()
 		if err != nil {
 			return err
@@ -4806,15 +4923,16 @@ func (cmd *ClusterShardsCmd) readReply(rd *proto.Reader) error {
 						return err
 					}
 
-					cmd.val[i].Slots = append(cmd.val[i].Slots, SlotRange{Start: start, End: end})
+					shard.Slots = append(shard.Slots, SlotRange{Start: start, End: end})
 				}
 			case "nodes":
 				nodesLen, err := rd.ReadArrayLen()
 				if err != nil {
 					return err
 				}
-				cmd.val[i].Nodes = make([]Node, nodesLen)
+				shard.Nodes = make([]Node, 0, proto.PreallocLen(nodesLen))
 				for k := 0; k < nodesLen; k++ {
+					var node Node
 					nodeMapLen, err := rd.ReadMapLen()
 					if err != nil {
 						return err
@@ -4828,23 +4946,23 @@ func (cmd *ClusterShardsCmd) readReply(rd *proto.Reader) error {
 
 						switch nodeKey {
 						case "id":
-							cmd.val[i].Nodes[k].ID, err = rd.ReadString()
+							node.ID, err = rd.ReadString()
 						case "endpoint":
-							cmd.val[i].Nodes[k].Endpoint, err = rd.ReadString()
+							node.Endpoint, err = rd.ReadString()
 						case "ip":
-							cmd.val[i].Nodes[k].IP, err = rd.ReadString()
+							node.IP, err = rd.ReadString()
 						case "hostname":
-							cmd.val[i].Nodes[k].Hostname, err = rd.ReadString()
+							node.Hostname, err = rd.ReadString()
 						case "port":
-							cmd.val[i].Nodes[k].Port, err = rd.ReadInt()
+							node.Port, err = rd.ReadInt()
 						case "tls-port":
-							cmd.val[i].Nodes[k].TLSPort, err = rd.ReadInt()
+							node.TLSPort, err = rd.ReadInt()
 						case "role":
-							cmd.val[i].Nodes[k].Role, err = rd.ReadString()
+							node.Role, err = rd.ReadString()
 						case "replication-offset":
-							cmd.val[i].Nodes[k].ReplicationOffset, err = rd.ReadInt()
+							node.ReplicationOffset, err = rd.ReadInt()
 						case "health":
-							cmd.val[i].Nodes[k].Health, err = rd.ReadString()
+							node.Health, err = rd.ReadString()
 						default:
 							return fmt.Errorf("redis: unexpected key %q in CLUSTER SHARDS node reply", nodeKey)
 						}
@@ -4853,11 +4971,13 @@ func (cmd *ClusterShardsCmd) readReply(rd *proto.Reader) error {
 							return err
 						}
 					}
+					shard.Nodes = append(shard.Nodes, node)
 				}
 			default:
 				return fmt.Errorf("redis: unexpected key %q in CLUSTER SHARDS reply", key)
 			}
 		}
+		cmd.val = append(cmd.val, shard)
 	}
 
 	return nil
@@ -5249,10 +5369,10 @@ func (cmd *ACLLogCmd) readReply(rd *proto.Reader) error {
 		return err
 	}
 
-	cmd.val = make([]*ACLLogEntry, n)
+	cmd.val = make([]*ACLLogEntry, 0, proto.PreallocLen(n))
 	for i := 0; i < n; i++ {
-		cmd.val[i] = &ACLLogEntry{}
-		entry := cmd.val[i]
+		entry := &ACLLogEntry{}
+		cmd.val = append(cmd.val, entry)
 		respLen, err := rd.ReadMapLen()
 		if err != nil {
 			return err
@@ -5372,7 +5492,8 @@ func (cmd *InfoCmd) readReply(rd *proto.Reader) error {
 			}
 			section = strings.TrimPrefix(line, "# ")
 			cmd.val[section] = make(map[string]string)
-		} else if line != "" {
+		} else if line != "" && cmd.val != nil {
+			// Lines before the first section are skipped.
 			if section == "Modules" {
 				kv := moduleRe.FindStringSubmatch(line)
 				if len(kv) == 3 {
@@ -0,0 +1,157 @@
+package redis
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9/internal/proto"
+)
+
+// fuzzCmds returns a Cmder of every type that reads a reply, with the
+// variants that read it differently. MonitorCmd is left out because it
+// keeps reading in a goroutine.
+func fuzzCmds() []Cmder {
+	return []Cmder{
+		NewCmd(ctx),
+		NewSliceCmd(ctx),
+		NewStatusCmd(ctx),
+		NewIntCmd(ctx),
+		NewIntSliceCmd(ctx),
+		NewDurationCmd(ctx, time.Second),
+		NewDurationCmd(ctx, time.Millisecond),
+		NewTimeCmd(ctx),
+		NewBoolCmd(ctx),
+		NewStringCmd(ctx),
+		NewWriterCmd(ctx, io.Discard),
+		NewFloatCmd(ctx),
+		NewFloatSliceCmd(ctx),
+		NewStringSliceCmd(ctx),
+		NewKeyValueSliceCmd(ctx),
+		NewBoolSliceCmd(ctx),
+		NewMapStringStringCmd(ctx),
+		NewMapStringIntCmd(ctx),
+		NewMapStringSliceInterfaceCmd(ctx),
+		NewTSMultiRangeCmd(ctx),
+		NewStringStructMapCmd(ctx),
+		NewXMessageSliceCmd(ctx),
+		NewXStreamSliceCmd(ctx),
+		NewXPendingCmd(ctx),
+		NewXPendingExtCmd(ctx),
+		NewXAutoClaimCmd(ctx),
+		NewXAutoClaimJustIDCmd(ctx),
+		NewXInfoConsumersCmd(ctx, "stream", "group"),
+		NewXInfoGroupsCmd(ctx, "stream"),
+		NewXInfoStreamCmd(ctx, "stream"),
+		NewXInfoStreamFullCmd(ctx),
+		NewZSliceCmd(ctx),
+		NewZWithKeyCmd(ctx),
+		NewScanCmd(ctx, nil),
+		NewClusterSlotsCmd(ctx),
+		NewGeoLocationCmd(ctx, &GeoRadiusQuery{}),
+		NewGeoLocationCmd(ctx, &GeoRadiusQuery{WithCoord: true, WithDist: true, WithGeoHash: true}),
+		NewGeoSearchLocationCmd(ctx, &GeoSearchLocationQuery{}),
+		NewGeoSearchLocationCmd(ctx, &GeoSearchLocationQuery{WithCoord: true, WithDist: true, WithHash: true}),
+		NewGeoPosCmd(ctx),
+		NewCommandsInfoCmd(ctx),
+		NewSlowLogCmd(ctx),
+		NewMapStringInterfaceCmd(ctx),
+		NewMapStringStringSliceCmd(ctx),
+		NewMapStringInterfaceSliceCmd(ctx),
+		NewKeyValuesCmd(ctx),
+		NewZSliceWithKeyCmd(ctx),
+		NewFunctionListCmd(ctx),
+		NewFunctionStatsCmd(ctx),
+		NewLCSCmd(ctx, &LCSQuery{}),
+		NewLCSCmd(ctx, &LCSQuery{Len: true}),
+		NewLCSCmd(ctx, &LCSQuery{Idx: true}),
+		NewLCSCmd(ctx, &LCSQuery{Idx: true, WithMatchLen: true}),
+		NewKeyFlagsCmd(ctx),
+		NewClusterLinksCmd(ctx),
+		NewClusterShardsCmd(ctx),
+		NewRankWithScoreCmd(ctx),
+		NewClientInfoCmd(ctx),
+		NewACLLogCmd(ctx),
+		NewInfoCmd(ctx),
+		newJSONCmd(ctx),
+		NewJSONSliceCmd(ctx),
+		NewIntPointerSliceCmd(ctx),
+		newScanDumpCmd(ctx),
+		NewBFInfoCmd(ctx),
+		NewCFInfoCmd(ctx),
+		NewCMSInfoCmd(ctx),
+		NewTopKInfoCmd(ctx),
+		NewTDigestInfoCmd(ctx),
+		newTSTimestampValueCmd(ctx),
+		newTSTimestampValueSliceCmd(ctx),
+		NewFTSearchCmd(ctx, nil),
+		NewFTSearchCmd(ctx, &FTSearchOptions{NoContent: true, WithScores: true}),
+		NewFTSearchCmd(ctx, &FTSearchOptions{WithScores: true, WithPayloads: true, WithSortKeys: true}),
+		NewFTAggregateCmd(ctx),
+		NewFTInfoCmd(ctx),
+		NewFTSpellCheckCmd(ctx),
+		NewFTProfileCmd(ctx, nil),
+		NewFTProfileCmd(ctx, &FTProfileOptions{Aggregate: true}),
+		NewKNNSearchCmd(ctx),
+		NewFTSuggestionCmd(ctx, nil),
+		NewFTSuggestionCmd(ctx, &FTSugGetOptions{WithScores: true, WithPayloads: true}),
+		NewTypedCmd(ctx, DecodeReply[interface{}]),
+		NewTypedCmd(ctx, DecodeReply[map[string][]*float64]),
+		newJSONValueCmd(ctx, jsonGetDecoder[map[string]interface{}](true)),
+		newJSONValueCmd(ctx, jsonMGetDecoder[[]int](false)),
+		newJSONPathCmd[int64](ctx, JSONPathRoot(), "JSON.STRLEN"),
+		newJSONPathCmd[interface{}](ctx, JSONLegacyPathRoot(), "JSON.GET"),
+		newJSONPathCmd[map[string]interface{}](ctx, JSONPathRoot(), "JSON.GET"),
+	}
+}
+
+func FuzzCmdReadReply(f *testing.F) {
+	for _, seed := range []string{
+		"+OK\r\n",
+		"-ERR error\r\n",
+		":42\r\n",
+		"$5\r\nhello\r\n",
+		"$-1\r\n",
+		"*-1\r\n",
+		"_\r\n",
+		",3.14\r\n",
+		"#t\r\n",
+		"(12345678901234567890\r\n",
+		"!5\r\nerror\r\n",
+		"=7\r\ntxt:abc\r\n",
+		"*2\r\n$1\r\na\r\n$1\r\nb\r\n",
+		"*2\r\n$1\r\na\r\n:1\r\n",
+		"*2\r\n$2\r\n10\r\n*2\r\n$1\r\na\r\n$1\r\nb\r\n",
+		"*1\r\n*2\r\n$3\r\n1-0\r\n*2\r\n$1\r\nf\r\n$1\r\nv\r\n",
+		"*1\r\n*2\r\n$6\r\nstream\r\n*1\r\n*2\r\n$3\r\n1-0\r\n*0\r\n",
+		"*4\r\n:1\r\n$3\r\n1-0\r\n$3\r\n1-0\r\n*1\r\n*2\r\n$1\r\nc\r\n$1\r\n1\r\n",
+		"*1\r\n*3\r\n:0\r\n:16383\r\n*2\r\n$9\r\n127.0.0.1\r\n:6379\r\n",
+		"*1\r\n*2\r\n$1\r\na\r\n*2\r\n$3\r\n1.0\r\n$3\r\n2.0\r\n",
+		"*2\r\n$1\r\na\r\n$3\r\n1.5\r\n",
+		"*2\r\n$1\r\na\r\n,1.5\r\n",
+		"*2\r\n$3\r\nlen\r\n:3\r\n",
+		"*4\r\n$7\r\nmatches\r\n*1\r\n*3\r\n*2\r\n:0\r\n:1\r\n*2\r\n:0\r\n:1\r\n:2\r\n$3\r\nlen\r\n:2\r\n",
+		"%2\r\n$1\r\na\r\n$1\r\nb\r\n$1\r\nc\r\n:1\r\n",
+		"%1\r\n$1\r\na\r\n*1\r\n%1\r\n$1\r\nb\r\n:1\r\n",
+		"~2\r\n$1\r\na\r\n$1\r\nb\r\n",
+		"|1\r\n$1\r\na\r\n$1\r\nb\r\n+OK\r\n",
+		">2\r\n$7\r\nmessage\r\n$1\r\na\r\n+OK\r\n",
+		"$40\r\n# Server\r\nredis_version:7.2.0\r\nuptime:1\r\n\r\n",
+		"$23\r\nid=1 addr=:1 name=a\n\r\n",
+		"*2\r\n:1\r\n$3\r\n1.5\r\n",
+		"*1\r\n*2\r\n:1\r\n$3\r\n1.5\r\n",
+		"*9999999999\r\n",
+		"$9223372036854775807\r\n",
+		"%1\r\n*1\r\n:1\r\n:2\r\n",
+	} {
+		f.Add([]byte(seed))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, cmd := range fuzzCmds() {
+			rd := proto.NewReader(bytes.NewReader(data))
+			_ = cmd.readReply(rd)
+		}
+	})
+}
@@ -1,11 +1,14 @@
 package redis_test
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"reflect"
 	"strconv"
+	"strings"
 	"time"
 
 	. "github.com/bsm/ginkgo/v2"
@@ -1351,6 +1354,27 @@ var _ = Describe("Commands", func() {
 			Expect(get.Val()).To(Equal("hello"))
 		})
 
+		It("should GetToWriter and SetFromReader", func() {
+			var buf bytes.Buffer
+			get := client.GetToWriter(ctx, "_", &buf)
+			Expect(get.Err()).To(Equal(redis.Nil))
+			Expect(get.Val()).To(Equal(int64(0)))
+
+			value := strings.Repeat("value", 1<<18)
+			set := client.SetFromReader(ctx, "key", strings.NewReader(value), int64(len(value)))
+			Expect(set.Err()).NotTo(HaveOccurred())
+			Expect(set.Val()).To(Equal("OK"))
+
+			get = client.GetToWriter(ctx, "key", &buf)
+			Expect(get.Err()).NotTo(HaveOccurred())
+			Expect(get.Val()).To(Equal(int64(len(value))))
+			Expect(buf.String()).To(Equal(value))
+
+			set = client.SetFromReader(ctx, "key", strings.NewReader("short"), 10)
+			Expect(set.Err()).To(MatchError(io.EOF))
+			Expect(client.Get(ctx, "key").Val()).To(Equal(value))
+		})
+
 		It("should GetBit", func() {
 			setBit := client.SetBit(ctx, "key", 7, 1)
 			Expect(setBit.Err()).NotTo(HaveOccurred())
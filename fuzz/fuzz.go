@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/redis/go-redis/v9"
+	"github.com/redis/go-redis/v9/redistest"
 )
 
 var (
@@ -16,8 +17,13 @@ var (
 )
 
 func init() {
+	srv, err := redistest.Start()
+	if err != nil {
+		panic(err)
+	}
+
 	rdb = redis.NewClient(&redis.Options{
-		Addr:         ":6379",
+		Addr:         srv.Addr(),
 		DialTimeout:  10 * time.Second,
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 10 * time.Second,
@@ -23,6 +23,15 @@ type Conn struct {
 	Inited    bool
 	pooled    bool
 	createdAt time.Time
+
+	// TrackingRedirect is the ID of the client receiving invalidation
+	// messages for the keys read on this connection, 0 if tracking is off.
+	TrackingRedirect int64
+
+	// mux is set when the conn is a handle of a connection shared
+	// by several goroutines, see MuxConnPool.
+	mux  *connMux
+	turn *muxTurn
 }
 
 func NewConn(netConn net.Conn) *Conn {
@@ -52,6 +61,11 @@ func (cn *Conn) SetNetConn(netConn net.Conn) {
 	cn.bw.Reset(netConn)
 }
 
+// SetPushHandler sets the handler of RESP3 push messages received out-of-band.
+func (cn *Conn) SetPushHandler(fn proto.PushHandler) {
+	cn.rd.SetPushHandler(fn)
+}
+
 func (cn *Conn) Write(b []byte) (int, error) {
 	return cn.netConn.Write(b)
 }
@@ -66,6 +80,10 @@ func (cn *Conn) RemoteAddr() net.Addr {
 func (cn *Conn) WithReader(
 	ctx context.Context, timeout time.Duration, fn func(rd *proto.Reader) error,
 ) error {
+	if cn.mux != nil {
+		return cn.withMuxReader(ctx, timeout, fn)
+	}
+
 	if timeout >= 0 {
 		if err := cn.netConn.SetReadDeadline(cn.deadline(ctx, timeout)); err != nil {
 			return err
@@ -77,6 +95,10 @@ func (cn *Conn) WithReader(
 func (cn *Conn) WithWriter(
 	ctx context.Context, timeout time.Duration, fn func(wr *proto.Writer) error,
 ) error {
+	if cn.mux != nil {
+		return cn.withMuxWriter(ctx, timeout, fn)
+	}
+
 	if timeout >= 0 {
 		if err := cn.netConn.SetWriteDeadline(cn.deadline(ctx, timeout)); err != nil {
 			return err
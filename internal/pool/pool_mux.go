@@ -0,0 +1,410 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9/internal/proto"
+)
+
+// ErrConnBroken is returned for the commands sent on a shared connection
+// after it failed, their replies are lost.
+var ErrConnBroken = errors.New("redis: multiplexed connection is broken")
+
+var errNoReply = errors.New("redis: no reply to read on multiplexed connection")
+
+// MuxConnPool shares a few connections between all goroutines.
+// Commands are pipelined on the shared connections and the replies
+// are read in the order the commands were written.
+//
+// NewConn and Dedicated return connections that are not shared,
+// for commands that must not hold up the others or that depend
+// on the connection state, like blocking commands, transactions and PubSub.
+//
+// Shared connections older than ConnMaxLifetime or unused for ConnMaxIdleTime
+// are replaced on Get and closed once all their handles are put back.
+type MuxConnPool struct {
+	pool *ConnPool
+
+	next uint32 // atomic
+
+	mu     sync.Mutex
+	muxes  []*connMux
+	closed bool
+
+	stats Stats
+}
+
+var _ Pooler = (*MuxConnPool)(nil)
+
+// NewMuxConnPool returns a pool sharing size connections dialed by pool,
+// which also provides the dedicated connections.
+func NewMuxConnPool(pool *ConnPool, size int) *MuxConnPool {
+	return &MuxConnPool{
+		pool:  pool,
+		muxes: make([]*connMux, size),
+	}
+}
+
+// Dedicated returns the pool of connections that are not shared.
+func (p *MuxConnPool) Dedicated() Pooler {
+	return p.pool
+}
+
+func (p *MuxConnPool) NewConn(ctx context.Context) (*Conn, error) {
+	return p.pool.NewConn(ctx)
+}
+
+func (p *MuxConnPool) CloseConn(cn *Conn) error {
+	return p.pool.CloseConn(cn)
+}
+
+// Get returns a handle of a shared connection. The handles of a connection
+// that is not initialized yet are only returned once the first one is put back.
+func (p *MuxConnPool) Get(ctx context.Context) (*Conn, error) {
+	for {
+		m, created, err := p.getMux(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if created {
+			atomic.AddUint32(&p.stats.Misses, 1)
+			return m.handle(), nil
+		}
+
+		select {
+		case <-m.ready:
+		case <-ctx.Done():
+			m.release()
+			return nil, ctx.Err()
+		}
+		if m.error() != nil {
+			m.release()
+			continue
+		}
+
+		atomic.AddUint32(&p.stats.Hits, 1)
+		return m.handle(), nil
+	}
+}
+
+// getMux returns a shared connection with a handle reserved for the caller,
+// created reports whether the connection was dialed for it.
+func (p *MuxConnPool) getMux(ctx context.Context) (_ *connMux, created bool, _ error) {
+	i := atomic.AddUint32(&p.next, 1) % uint32(len(p.muxes))
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil, false, ErrClosed
+	}
+	if m := p.muxes[i]; m != nil && m.error() == nil {
+		if p.isHealthyMux(m) {
+			m.reserve()
+			p.mu.Unlock()
+			return m, false, nil
+		}
+		m.retire()
+	}
+	m := &connMux{
+		pool:      p.pool,
+		createdAt: time.Now(),
+		ready:     make(chan struct{}),
+		tail:      closedChan,
+	}
+	m.reserve()
+	p.muxes[i] = m
+	p.mu.Unlock()
+
+	cn, err := p.pool.NewConn(ctx)
+	if err != nil {
+		m.fail(ErrConnBroken)
+		m.release()
+		return nil, false, err
+	}
+	cn.mux = m
+	m.mu.Lock()
+	m.cn = cn
+	m.mu.Unlock()
+
+	return m, true, nil
+}
+
+func (p *MuxConnPool) Put(ctx context.Context, cn *Conn) {
+	m := cn.mux
+	if m == nil {
+		p.pool.Put(ctx, cn)
+		return
+	}
+
+	if cn.turn != nil {
+		// The reply was not read, the next ones can't be matched anymore.
+		m.fail(ErrConnBroken)
+		cn.dropTurn()
+		m.release()
+		return
+	}
+
+	m.mu.Lock()
+	m.cn.Inited = cn.Inited
+	m.cn.TrackingRedirect = cn.TrackingRedirect
+	m.mu.Unlock()
+	m.setReady()
+	m.release()
+}
+
+func (p *MuxConnPool) Remove(ctx context.Context, cn *Conn, reason error) {
+	if cn.mux == nil {
+		p.pool.Remove(ctx, cn, reason)
+		return
+	}
+	cn.mux.fail(ErrConnBroken)
+	cn.dropTurn()
+	cn.mux.release()
+}
+
+// isHealthyMux reports whether the shared connection can still be used
+// for new commands, see isHealthyConn.
+func (p *MuxConnPool) isHealthyMux(m *connMux) bool {
+	now := time.Now()
+
+	if p.pool.cfg.ConnMaxLifetime > 0 && now.Sub(m.createdAt) >= p.pool.cfg.ConnMaxLifetime {
+		return false
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if p.pool.cfg.ConnMaxIdleTime > 0 && m.handles == 0 && now.Sub(m.usedAt) >= p.pool.cfg.ConnMaxIdleTime {
+		return false
+	}
+	return true
+}
+
+// Len returns total number of connections, including the shared ones.
+func (p *MuxConnPool) Len() int {
+	return p.pool.Len()
+}
+
+// IdleLen returns number of idle dedicated connections.
+func (p *MuxConnPool) IdleLen() int {
+	return p.pool.IdleLen()
+}
+
+func (p *MuxConnPool) Stats() *Stats {
+	stats := p.pool.Stats()
+	stats.Hits += atomic.LoadUint32(&p.stats.Hits)
+	stats.Misses += atomic.LoadUint32(&p.stats.Misses)
+	return stats
+}
+
+func (p *MuxConnPool) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return ErrClosed
+	}
+	p.closed = true
+	muxes := p.muxes
+	p.mu.Unlock()
+
+	for _, m := range muxes {
+		if m != nil {
+			m.fail(ErrClosed)
+		}
+	}
+	return p.pool.Close()
+}
+
+//------------------------------------------------------------------------------
+
+var closedChan = func() chan struct{} {
+	ch := make(chan struct{})
+	close(ch)
+	return ch
+}()
+
+// connMux is the state of a shared connection.
+type connMux struct {
+	pool      *ConnPool
+	cn        *Conn
+	createdAt time.Time
+
+	mu        sync.Mutex // protects cn and its state copied into handles
+	handles   int        // handles reserved and not put back yet
+	usedAt    time.Time
+	retired   bool // closed once the last handle is put back
+	ready     chan struct{}
+	readyOnce sync.Once
+
+	wmu  sync.Mutex
+	tail chan struct{} // closed when the reply of the last written command is read
+
+	failOnce sync.Once
+	err      atomic.Value
+}
+
+// muxTurn is the position of a command in the replies of a shared connection.
+type muxTurn struct {
+	prev chan struct{}
+	done chan struct{}
+}
+
+func (m *connMux) reserve() {
+	m.mu.Lock()
+	m.handles++
+	m.usedAt = time.Now()
+	m.mu.Unlock()
+}
+
+// release puts back a handle reserved by reserve.
+func (m *connMux) release() {
+	m.mu.Lock()
+	m.handles--
+	closing := m.retired && m.handles == 0
+	m.mu.Unlock()
+
+	if closing {
+		m.fail(ErrClosed)
+	}
+}
+
+// retire closes the shared connection once the commands in flight are done.
+func (m *connMux) retire() {
+	m.mu.Lock()
+	m.retired = true
+	closing := m.handles == 0
+	m.mu.Unlock()
+
+	if closing {
+		m.fail(ErrClosed)
+	}
+}
+
+func (m *connMux) handle() *Conn {
+	m.mu.Lock()
+	cn := *m.cn
+	m.mu.Unlock()
+	return &cn
+}
+
+func (m *connMux) setReady() {
+	m.readyOnce.Do(func() {
+		close(m.ready)
+	})
+}
+
+func (m *connMux) error() error {
+	err, _ := m.err.Load().(error)
+	return err
+}
+
+func (m *connMux) fail(err error) {
+	m.failOnce.Do(func() {
+		m.err.Store(err)
+
+		m.mu.Lock()
+		cn := m.cn
+		m.mu.Unlock()
+		if cn != nil {
+			_ = m.pool.CloseConn(cn)
+		}
+	})
+	m.setReady()
+}
+
+func (cn *Conn) withMuxWriter(
+	ctx context.Context, timeout time.Duration, fn func(wr *proto.Writer) error,
+) error {
+	m := cn.mux
+	m.wmu.Lock()
+	defer m.wmu.Unlock()
+
+	if err := m.error(); err != nil {
+		return err
+	}
+	if cn.turn != nil {
+		m.fail(ErrConnBroken)
+		cn.dropTurn()
+		return errNoReply
+	}
+
+	if timeout >= 0 {
+		if err := cn.netConn.SetWriteDeadline(cn.deadline(ctx, timeout)); err != nil {
+			m.fail(ErrConnBroken)
+			return err
+		}
+	}
+
+	if cn.bw.Buffered() > 0 {
+		cn.bw.Reset(cn.netConn)
+	}
+
+	if err := fn(cn.wr); err != nil {
+		m.fail(ErrConnBroken)
+		return err
+	}
+	if err := cn.bw.Flush(); err != nil {
+		m.fail(ErrConnBroken)
+		return err
+	}
+
+	cn.turn = &muxTurn{
+		prev: m.tail,
+		done: make(chan struct{}),
+	}
+	m.tail = cn.turn.done
+	return nil
+}
+
+// dropTurn gives up the turn of a command whose reply won't be read, so that
+// the commands written after it stop waiting and find the connection failed.
+// The connection must be failed first.
+func (cn *Conn) dropTurn() {
+	if cn.turn != nil {
+		close(cn.turn.done)
+		cn.turn = nil
+	}
+}
+
+func (cn *Conn) withMuxReader(
+	ctx context.Context, timeout time.Duration, fn func(rd *proto.Reader) error,
+) error {
+	turn := cn.turn
+	if turn == nil {
+		return errNoReply
+	}
+	cn.turn = nil
+	defer close(turn.done)
+
+	m := cn.mux
+	select {
+	case <-turn.prev:
+	case <-ctx.Done():
+		// The reply can't be skipped, the next ones can't be matched anymore.
+		m.fail(ErrConnBroken)
+		return ctx.Err()
+	}
+
+	if err := m.error(); err != nil {
+		return err
+	}
+
+	if timeout >= 0 {
+		if err := cn.netConn.SetReadDeadline(cn.deadline(ctx, timeout)); err != nil {
+			m.fail(ErrConnBroken)
+			return err
+		}
+	}
+
+	err := fn(cn.rd)
+	if err != nil {
+		if _, ok := err.(proto.RedisError); !ok {
+			// The reply may be partially read.
+			m.fail(ErrConnBroken)
+		}
+	}
+	return err
+}
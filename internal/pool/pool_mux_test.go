@@ -0,0 +1,258 @@
+package pool_test
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"time"
+
+	. "github.com/bsm/ginkgo/v2"
+	. "github.com/bsm/gomega"
+
+	"github.com/redis/go-redis/v9/internal/pool"
+	"github.com/redis/go-redis/v9/internal/proto"
+)
+
+// echoDialer returns connections to a server replying
+// to each command with its first argument.
+func echoDialer(context.Context) (net.Conn, error) {
+	client, server := net.Pipe()
+
+	// net.Pipe is not buffered, so the replies are written by another
+	// goroutine while the client is still writing its commands.
+	replies := make(chan string, 100)
+	go func() {
+		for reply := range replies {
+			if _, err := server.Write([]byte(reply)); err != nil {
+				return
+			}
+		}
+	}()
+	go func() {
+		defer server.Close()
+		defer close(replies)
+
+		rd := proto.NewReader(server)
+		for {
+			args, err := rd.ReadSlice()
+			if err != nil {
+				return
+			}
+			replies <- "+" + args[0].(string) + "\r\n"
+		}
+	}()
+	return client, nil
+}
+
+var _ = Describe("MuxConnPool", func() {
+	ctx := context.Background()
+	var connPool *pool.MuxConnPool
+
+	BeforeEach(func() {
+		connPool = pool.NewMuxConnPool(pool.NewConnPool(&pool.Options{
+			Dialer:      echoDialer,
+			PoolSize:    10,
+			PoolTimeout: time.Hour,
+		}), 2)
+	})
+
+	AfterEach(func() {
+		connPool.Close()
+	})
+
+	echo := func(cn *pool.Conn, s string) (string, error) {
+		if err := cn.WithWriter(ctx, time.Second, func(wr *proto.Writer) error {
+			return wr.WriteArgs([]interface{}{s})
+		}); err != nil {
+			return "", err
+		}
+
+		var reply string
+		err := cn.WithReader(ctx, time.Second, func(rd *proto.Reader) error {
+			var err error
+			reply, err = rd.ReadString()
+			return err
+		})
+		return reply, err
+	}
+
+	It("matches replies to concurrent commands", func() {
+		perform(1000, func(id int) {
+			cn, err := connPool.Get(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			defer connPool.Put(ctx, cn)
+
+			s := strconv.Itoa(id)
+			Expect(echo(cn, s)).To(Equal(s))
+		})
+
+		Expect(connPool.Len()).To(Equal(2))
+		Expect(connPool.Stats().Misses).To(Equal(uint32(2)))
+	})
+
+	It("replaces removed connections", func() {
+		cn, err := connPool.Get(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(echo(cn, "a")).To(Equal("a"))
+		connPool.Remove(ctx, cn, nil)
+
+		_, err = echo(cn, "b")
+		Expect(err).To(Equal(pool.ErrConnBroken))
+		Expect(connPool.Len()).To(Equal(0))
+
+		cn, err = connPool.Get(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(echo(cn, "c")).To(Equal("c"))
+		connPool.Put(ctx, cn)
+		Expect(connPool.Len()).To(Equal(1))
+	})
+
+	It("fails pending commands when a reply is not read", func() {
+		cn, err := connPool.Get(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cn.WithWriter(ctx, time.Second, func(wr *proto.Writer) error {
+			return wr.WriteArgs([]interface{}{"a"})
+		})).NotTo(HaveOccurred())
+		connPool.Put(ctx, cn)
+
+		Expect(connPool.Len()).To(Equal(0))
+	})
+
+	It("fails the commands written after a reply that is not read", func() {
+		connPool.Close()
+		connPool = pool.NewMuxConnPool(pool.NewConnPool(&pool.Options{
+			Dialer:      echoDialer,
+			PoolSize:    10,
+			PoolTimeout: time.Hour,
+		}), 1)
+
+		cn, err := connPool.Get(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(echo(cn, "a")).To(Equal("a"))
+		connPool.Put(ctx, cn)
+
+		abandoned, err := connPool.Get(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		cn, err = connPool.Get(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		for _, c := range []*pool.Conn{abandoned, cn} {
+			Expect(c.WithWriter(ctx, time.Second, func(wr *proto.Writer) error {
+				return wr.WriteArgs([]interface{}{"b"})
+			})).NotTo(HaveOccurred())
+		}
+
+		errc := make(chan error, 1)
+		go func() {
+			errc <- cn.WithReader(ctx, time.Second, func(rd *proto.Reader) error {
+				_, err := rd.ReadString()
+				return err
+			})
+		}()
+		connPool.Put(ctx, abandoned)
+
+		Eventually(errc).Should(Receive(Equal(pool.ErrConnBroken)))
+		connPool.Put(ctx, cn)
+	})
+
+	It("stops waiting for the previous replies when ctx is done", func() {
+		connPool.Close()
+		connPool = pool.NewMuxConnPool(pool.NewConnPool(&pool.Options{
+			Dialer:      echoDialer,
+			PoolSize:    10,
+			PoolTimeout: time.Hour,
+		}), 1)
+
+		cn, err := connPool.Get(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(echo(cn, "a")).To(Equal("a"))
+		connPool.Put(ctx, cn)
+
+		first, err := connPool.Get(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		cn, err = connPool.Get(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		for _, c := range []*pool.Conn{first, cn} {
+			Expect(c.WithWriter(ctx, time.Second, func(wr *proto.Writer) error {
+				return wr.WriteArgs([]interface{}{"b"})
+			})).NotTo(HaveOccurred())
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		err = cn.WithReader(ctx, time.Second, func(rd *proto.Reader) error {
+			_, err := rd.ReadString()
+			return err
+		})
+		Expect(err).To(Equal(context.DeadlineExceeded))
+
+		connPool.Put(ctx, first)
+		connPool.Put(ctx, cn)
+		Expect(connPool.Len()).To(Equal(0))
+	})
+
+	It("does not share dedicated connections", func() {
+		cn, err := connPool.Dedicated().Get(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(echo(cn, "a")).To(Equal("a"))
+		connPool.Dedicated().Put(ctx, cn)
+
+		Expect(connPool.Len()).To(Equal(1))
+		Expect(connPool.IdleLen()).To(Equal(1))
+	})
+	It("replaces expired connections once their commands are done", func() {
+		connPool.Close()
+		connPool = pool.NewMuxConnPool(pool.NewConnPool(&pool.Options{
+			Dialer:          echoDialer,
+			PoolSize:        10,
+			PoolTimeout:     time.Hour,
+			ConnMaxLifetime: 10 * time.Millisecond,
+		}), 1)
+
+		old, err := connPool.Get(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(echo(old, "a")).To(Equal("a"))
+		connPool.Put(ctx, old)
+
+		old, err = connPool.Get(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		time.Sleep(20 * time.Millisecond)
+
+		cn, err := connPool.Get(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(connPool.Len()).To(Equal(2))
+
+		Expect(echo(old, "b")).To(Equal("b"))
+		connPool.Put(ctx, old)
+		Expect(connPool.Len()).To(Equal(1))
+
+		Expect(echo(cn, "c")).To(Equal("c"))
+		connPool.Put(ctx, cn)
+		Expect(connPool.Stats().Misses).To(Equal(uint32(2)))
+	})
+
+	It("closes idle connections", func() {
+		connPool.Close()
+		connPool = pool.NewMuxConnPool(pool.NewConnPool(&pool.Options{
+			Dialer:          echoDialer,
+			PoolSize:        10,
+			PoolTimeout:     time.Hour,
+			ConnMaxIdleTime: 10 * time.Millisecond,
+		}), 1)
+
+		cn, err := connPool.Get(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(echo(cn, "a")).To(Equal("a"))
+		connPool.Put(ctx, cn)
+		time.Sleep(20 * time.Millisecond)
+
+		cn, err = connPool.Get(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(echo(cn, "b")).To(Equal("b"))
+		connPool.Put(ctx, cn)
+
+		Expect(connPool.Len()).To(Equal(1))
+		Expect(connPool.Stats().Misses).To(Equal(uint32(2)))
+	})
+})
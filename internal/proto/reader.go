@@ -2,6 +2,7 @@ package proto
 
 import (
 	"bufio"
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
@@ -40,6 +41,23 @@ const (
 
 const Nil = RedisError("redis: nil") // nolint:errname
 
+// maxPrealloc limits the elements or bytes allocated before they are read,
+// so that a bogus reply length can't exhaust the memory.
+const maxPrealloc = 1 << 16
+
+// PreallocLen returns the capacity to allocate for a reply of n elements.
+// Larger replies grow as their elements are read.
+func PreallocLen(n int) int {
+	switch {
+	case n < 0:
+		return 0
+	case n > maxPrealloc:
+		return maxPrealloc
+	default:
+		return n
+	}
+}
+
 type RedisError string
 
 func (e RedisError) Error() string { return string(e) }
@@ -52,8 +70,15 @@ func ParseErrorReply(line []byte) error {
 
 //------------------------------------------------------------------------------
 
+// PushHandler handles a RESP3 push message read out-of-band, kind is the
+// first element of the push (e.g. "invalidate") and payload holds the rest.
+type PushHandler func(kind string, payload []interface{})
+
 type Reader struct {
 	rd *bufio.Reader
+
+	onPush PushHandler
+	attrs  map[string]interface{}
 }
 
 func NewReader(rd io.Reader) *Reader {
@@ -74,24 +99,110 @@ func (r *Reader) Reset(rd io.Reader) {
 	r.rd.Reset(rd)
 }
 
+// SetPushHandler makes the Reader consume RESP3 push messages out-of-band and
+// pass them to fn instead of returning them as replies.
+// A nil fn restores the default behaviour of reading a push like an array.
+func (r *Reader) SetPushHandler(fn PushHandler) {
+	r.onPush = fn
+}
+
+// readPush reads the push message represented by line and passes it to the push handler.
+func (r *Reader) readPush(line []byte) error {
+	v, err := r.readSlice(line)
+	if err != nil {
+		return err
+	}
+	var kind string
+	if len(v) > 0 {
+		kind, _ = v[0].(string)
+		v = v[1:]
+	}
+	r.onPush(kind, v)
+	return nil
+}
+
+// TakeAttributes returns the RESP3 attributes read since the previous call
+// and forgets them. It returns nil if no attribute was read.
+func (r *Reader) TakeAttributes() map[string]interface{} {
+	attrs := r.attrs
+	r.attrs = nil
+	return attrs
+}
+
+// readAttr reads the attribute represented by line, see TakeAttributes.
+func (r *Reader) readAttr(line []byte) error {
+	n, err := replyLen(line)
+	if err != nil {
+		if err == Nil {
+			return nil
+		}
+		return err
+	}
+	if r.attrs == nil {
+		r.attrs = make(map[string]interface{}, PreallocLen(n))
+	}
+	for i := 0; i < n; i++ {
+		k, err := r.ReadReply()
+		if err != nil {
+			if err, ok := err.(RedisError); ok {
+				k = err
+			} else {
+				return err
+			}
+		}
+		v, err := r.ReadReply()
+		if err != nil {
+			if err == Nil {
+				v = nil
+			} else if err, ok := err.(RedisError); ok {
+				v = err
+			} else {
+				return err
+			}
+		}
+		key, ok := k.(string)
+		if !ok {
+			key = fmt.Sprint(k)
+		}
+		r.attrs[key] = v
+	}
+	return nil
+}
+
 // PeekReplyType returns the data type of the next response without advancing the Reader,
-// and discard the attribute type.
+// and reads the attribute type, see TakeAttributes.
 func (r *Reader) PeekReplyType() (byte, error) {
 	b, err := r.rd.Peek(1)
 	if err != nil {
 		return 0, err
 	}
-	if b[0] == RespAttr {
-		if err = r.DiscardNext(); err != nil {
+	switch b[0] {
+	case RespAttr:
+		line, err := r.readLine()
+		if err != nil {
+			return 0, err
+		}
+		if err = r.readAttr(line); err != nil {
 			return 0, err
 		}
 		return r.PeekReplyType()
+	case RespPush:
+		if r.onPush != nil {
+			line, err := r.readLine()
+			if err != nil {
+				return 0, err
+			}
+			if err = r.readPush(line); err != nil {
+				return 0, err
+			}
+			return r.PeekReplyType()
+		}
 	}
 	return b[0], nil
 }
 
 // ReadLine Return a valid reply, it will check the protocol or redis error,
-// and discard the attribute type.
+// and reads the attribute type, see TakeAttributes.
 func (r *Reader) ReadLine() ([]byte, error) {
 	line, err := r.readLine()
 	if err != nil {
@@ -110,10 +221,17 @@ func (r *Reader) ReadLine() ([]byte, error) {
 		}
 		return nil, err
 	case RespAttr:
-		if err = r.Discard(line); err != nil {
+		if err = r.readAttr(line); err != nil {
 			return nil, err
 		}
 		return r.ReadLine()
+	case RespPush:
+		if r.onPush != nil {
+			if err = r.readPush(line); err != nil {
+				return nil, err
+			}
+			return r.ReadLine()
+		}
 	}
 
 	// Compatible with RESP2
@@ -219,13 +337,35 @@ func (r *Reader) readStringReply(line []byte) (string, error) {
 		return "", err
 	}
 
-	b := make([]byte, n+2)
-	_, err = io.ReadFull(r.rd, b)
+	b, err := r.readN(n)
 	if err != nil {
 		return "", err
 	}
 
-	return util.BytesToString(b[:n]), nil
+	return util.BytesToString(b), nil
+}
+
+// readN reads n bytes followed by \r\n.
+func (r *Reader) readN(n int) ([]byte, error) {
+	if n <= maxPrealloc {
+		b := make([]byte, n+2)
+		if _, err := io.ReadFull(r.rd, b); err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+
+	var buf bytes.Buffer
+	if _, err := io.CopyN(&buf, r.rd, int64(n)); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	if _, err := r.rd.Discard(2); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
 }
 
 func (r *Reader) readVerb(line []byte) (string, error) {
@@ -245,21 +385,21 @@ func (r *Reader) readSlice(line []byte) ([]interface{}, error) {
 		return nil, err
 	}
 
-	val := make([]interface{}, n)
-	for i := 0; i < len(val); i++ {
+	val := make([]interface{}, 0, PreallocLen(n))
+	for i := 0; i < n; i++ {
 		v, err := r.ReadReply()
 		if err != nil {
 			if err == Nil {
-				val[i] = nil
+				val = append(val, nil)
 				continue
 			}
 			if err, ok := err.(RedisError); ok {
-				val[i] = err
+				val = append(val, err)
 				continue
 			}
 			return nil, err
 		}
-		val[i] = v
+		val = append(val, v)
 	}
 	return val, nil
 }
@@ -269,12 +409,16 @@ func (r *Reader) readMap(line []byte) (map[interface{}]interface{}, error) {
 	if err != nil {
 		return nil, err
 	}
-	m := make(map[interface{}]interface{}, n)
+	m := make(map[interface{}]interface{}, PreallocLen(n))
 	for i := 0; i < n; i++ {
 		k, err := r.ReadReply()
 		if err != nil {
 			return nil, err
 		}
+		switch k.(type) {
+		case []interface{}, map[interface{}]interface{}:
+			return nil, fmt.Errorf("redis: can't use %T as a map key", k)
+		}
 		v, err := r.ReadReply()
 		if err != nil {
 			if err == Nil {
@@ -394,6 +538,45 @@ func (r *Reader) ReadString() (string, error) {
 	return "", fmt.Errorf("redis: can't parse reply=%.100q reading string", line)
 }
 
+// ReadStringTo reads a string reply and copies it to w in chunks,
+// without buffering the whole string in memory.
+// It returns the number of bytes copied.
+func (r *Reader) ReadStringTo(w io.Writer) (int64, error) {
+	line, err := r.ReadLine()
+	if err != nil {
+		return 0, err
+	}
+
+	switch line[0] {
+	case RespStatus, RespInt, RespFloat:
+		n, err := w.Write(line[1:])
+		return int64(n), err
+	case RespString, RespVerbatim:
+		n, err := replyLen(line)
+		if err != nil {
+			return 0, err
+		}
+		if line[0] == RespVerbatim {
+			// Skip the format, e.g. "txt:".
+			if n < 4 {
+				return 0, fmt.Errorf("redis: can't parse verbatim string reply: %q", line)
+			}
+			if _, err = r.rd.Discard(4); err != nil {
+				return 0, err
+			}
+			n -= 4
+		}
+
+		written, err := io.CopyN(w, r.rd, int64(n))
+		if err != nil {
+			return written, err
+		}
+		_, err = r.rd.Discard(2)
+		return written, err
+	}
+	return 0, fmt.Errorf("redis: can't parse reply=%.100q reading string", line)
+}
+
 func (r *Reader) ReadBool() (bool, error) {
 	s, err := r.ReadString()
 	if err != nil {
@@ -407,7 +590,12 @@ func (r *Reader) ReadSlice() ([]interface{}, error) {
 	if err != nil {
 		return nil, err
 	}
-	return r.readSlice(line)
+	switch line[0] {
+	case RespArray, RespSet, RespPush:
+		return r.readSlice(line)
+	default:
+		return nil, fmt.Errorf("redis: can't parse array/set/push reply: %.100q", line)
+	}
 }
 
 // ReadFixedArrayLen read fixed array length.
@@ -475,6 +663,67 @@ func (r *Reader) ReadMapLen() (int, error) {
 	}
 }
 
+// ReadRaw reads the next reply and returns its RESP encoding as is,
+// so that it can be decoded later by a Reader over the returned bytes.
+// Attributes are kept, push messages are handled as in ReadLine.
+func (r *Reader) ReadRaw() ([]byte, error) {
+	return r.appendRaw(nil)
+}
+
+func (r *Reader) appendRaw(b []byte) ([]byte, error) {
+	line, err := r.readLine()
+	if err != nil {
+		return nil, err
+	}
+	if line[0] == RespPush && r.onPush != nil {
+		if err = r.readPush(line); err != nil {
+			return nil, err
+		}
+		return r.appendRaw(b)
+	}
+
+	b = append(b, line...)
+	b = append(b, '\r', '\n')
+
+	switch line[0] {
+	case RespStatus, RespError, RespInt, RespNil, RespFloat, RespBool, RespBigInt:
+		return b, nil
+	}
+
+	n, err := replyLen(line)
+	if err != nil {
+		if err == Nil {
+			return b, nil
+		}
+		return nil, err
+	}
+
+	switch line[0] {
+	case RespBlobError, RespString, RespVerbatim:
+		data, err := r.readN(n)
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, data...)
+		return append(b, '\r', '\n'), nil
+	case RespArray, RespSet, RespPush:
+	case RespMap:
+		n *= 2
+	case RespAttr:
+		// Attribute key & value followed by the reply itself.
+		n = n*2 + 1
+	default:
+		return nil, fmt.Errorf("redis: can't parse %.100q", line)
+	}
+
+	for i := 0; i < n; i++ {
+		if b, err = r.appendRaw(b); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
 // DiscardNext read and discard the data represented by the next line.
 func (r *Reader) DiscardNext() error {
 	line, err := r.readLine()
@@ -3,6 +3,7 @@ package proto_test
 import (
 	"bytes"
 	"io"
+	"strings"
 	"testing"
 
 	"github.com/redis/go-redis/v9/internal/proto"
@@ -98,3 +99,157 @@ func benchmarkParseReply(b *testing.B, reply string, wanterr bool) {
 		}
 	}
 }
+
+func TestReader_PushHandler(t *testing.T) {
+	r := proto.NewReader(bytes.NewBufferString(
+		">2\r\n$10\r\ninvalidate\r\n*1\r\n$3\r\nfoo\r\n+OK\r\n"))
+
+	var kinds []string
+	r.SetPushHandler(func(kind string, payload []interface{}) {
+		kinds = append(kinds, kind)
+	})
+
+	v, err := r.ReadReply()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "OK" {
+		t.Errorf("got %v, wanted OK", v)
+	}
+	if len(kinds) != 1 || kinds[0] != "invalidate" {
+		t.Errorf("got push kinds %v, wanted [invalidate]", kinds)
+	}
+}
+
+func TestReader_ReadRaw(t *testing.T) {
+	replies := []string{
+		"+OK\r\n",
+		"$-1\r\n",
+		"$5\r\nhello\r\n",
+		"*2\r\n$5\r\nhello\r\n:1\r\n",
+		"%1\r\n+key\r\n*1\r\n,1.5\r\n",
+		"|1\r\n+ttl\r\n:100\r\n$5\r\nhello\r\n",
+	}
+	r := proto.NewReader(bytes.NewBufferString(strings.Join(replies, "")))
+	for _, want := range replies {
+		got, err := r.ReadRaw()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("got %q, wanted %q", got, want)
+		}
+	}
+}
+
+func TestReader_TakeAttributes(t *testing.T) {
+	r := proto.NewReader(bytes.NewBufferString(
+		"|1\r\n+key-popularity\r\n%1\r\n$1\r\na\r\n,0.5\r\n$5\r\nhello\r\n+OK\r\n"))
+
+	v, err := r.ReadString()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "hello" {
+		t.Errorf("got %q, wanted hello", v)
+	}
+
+	attrs := r.TakeAttributes()
+	want := map[interface{}]interface{}{"a": 0.5}
+	if got, ok := attrs["key-popularity"].(map[interface{}]interface{}); !ok || len(got) != 1 || got["a"] != want["a"] {
+		t.Errorf("got attributes %v, wanted key-popularity: %v", attrs, want)
+	}
+
+	if _, err := r.ReadReply(); err != nil {
+		t.Fatal(err)
+	}
+	if attrs := r.TakeAttributes(); attrs != nil {
+		t.Errorf("got attributes %v, wanted nil", attrs)
+	}
+}
+
+func TestReader_ReadStringTo(t *testing.T) {
+	value := strings.Repeat("a", 10000)
+	r := proto.NewReader(strings.NewReader(
+		"$10000\r\n" + value + "\r\n=9\r\ntxt:hello\r\n$-1\r\n+OK\r\n"))
+
+	var buf bytes.Buffer
+	for _, want := range []string{value, "hello"} {
+		buf.Reset()
+		n, err := r.ReadStringTo(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != int64(len(want)) || buf.String() != want {
+			t.Errorf("got %d bytes %.10q, wanted %d bytes %.10q", n, buf.String(), len(want), want)
+		}
+	}
+
+	buf.Reset()
+	if _, err := r.ReadStringTo(&buf); err != proto.Nil {
+		t.Errorf("got %v, wanted redis: nil", err)
+	}
+	if s, err := r.ReadString(); err != nil || s != "OK" {
+		t.Errorf("got %q %v, wanted OK", s, err)
+	}
+}
+
+func FuzzReader(f *testing.F) {
+	for _, seed := range []string{
+		"+OK\r\n",
+		"-ERR error\r\n",
+		":42\r\n",
+		"$5\r\nhello\r\n",
+		"$-1\r\n",
+		"*-1\r\n",
+		"_\r\n",
+		",3.14\r\n",
+		",inf\r\n",
+		"#t\r\n",
+		"(12345678901234567890\r\n",
+		"!5\r\nerror\r\n",
+		"=7\r\ntxt:abc\r\n",
+		"*2\r\n$1\r\na\r\n:1\r\n",
+		"%1\r\n$1\r\na\r\n~1\r\n#f\r\n",
+		"|1\r\n$1\r\na\r\n$1\r\nb\r\n+OK\r\n",
+		">2\r\n$7\r\nmessage\r\n$1\r\na\r\n+OK\r\n",
+		"*9999999999\r\n",
+		"$9223372036854775807\r\n",
+		"%1\r\n*1\r\n:1\r\n:2\r\n",
+	} {
+		f.Add([]byte(seed))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		rd := proto.NewReader(bytes.NewReader(data))
+		for {
+			if _, err := rd.ReadReply(); err != nil && err != proto.Nil {
+				if _, ok := err.(proto.RedisError); !ok {
+					break
+				}
+			}
+		}
+
+		readers := []func(rd *proto.Reader) error{
+			func(rd *proto.Reader) error { _, err := rd.ReadInt(); return err },
+			func(rd *proto.Reader) error { _, err := rd.ReadUint(); return err },
+			func(rd *proto.Reader) error { _, err := rd.ReadFloat(); return err },
+			func(rd *proto.Reader) error { _, err := rd.ReadString(); return err },
+			func(rd *proto.Reader) error { _, err := rd.ReadStringTo(io.Discard); return err },
+			func(rd *proto.Reader) error { _, err := rd.ReadBool(); return err },
+			func(rd *proto.Reader) error { _, err := rd.ReadSlice(); return err },
+			func(rd *proto.Reader) error { _, err := rd.ReadArrayLen(); return err },
+			func(rd *proto.Reader) error { _, err := rd.ReadMapLen(); return err },
+			func(rd *proto.Reader) error { _, err := rd.ReadRaw(); return err },
+			func(rd *proto.Reader) error { return rd.DiscardNext() },
+		}
+		for _, read := range readers {
+			rd := proto.NewReader(bytes.NewReader(data))
+			for i := 0; i < 100; i++ {
+				if err := read(rd); err == io.EOF || err == io.ErrUnexpectedEOF {
+					break
+				}
+			}
+		}
+	})
+}
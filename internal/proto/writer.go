@@ -18,6 +18,17 @@ type writer interface {
 	WriteString(s string) (n int, err error)
 }
 
+// StreamArg is an argument sent as a bulk string of Size bytes read from Reader,
+// so the whole value is never buffered in memory.
+type StreamArg struct {
+	Reader io.Reader
+	Size   int64
+}
+
+func (a *StreamArg) String() string {
+	return fmt.Sprintf("<%d bytes>", a.Size)
+}
+
 type Writer struct {
 	writer
 
@@ -140,6 +151,8 @@ func (w *Writer) WriteArg(v interface{}) error {
 		return w.bytes(b)
 	case net.IP:
 		return w.bytes(v)
+	case *StreamArg:
+		return w.stream(v)
 	default:
 		return fmt.Errorf(
 			"redis: can't marshal %T (implement encoding.BinaryMarshaler)", v)
@@ -162,6 +175,25 @@ func (w *Writer) bytes(b []byte) error {
 	return w.crlf()
 }
 
+func (w *Writer) stream(a *StreamArg) error {
+	if err := w.WriteByte(RespString); err != nil {
+		return err
+	}
+
+	w.lenBuf = strconv.AppendInt(w.lenBuf[:0], a.Size, 10)
+	w.lenBuf = append(w.lenBuf, '\r', '\n')
+	if _, err := w.Write(w.lenBuf); err != nil {
+		return err
+	}
+
+	// The reader can't be rewound, so the error is wrapped to prevent retries.
+	if _, err := io.CopyN(w.writer, a.Reader, a.Size); err != nil {
+		return fmt.Errorf("redis: can't stream argument: %w", err)
+	}
+
+	return w.crlf()
+}
+
 func (w *Writer) string(s string) error {
 	return w.bytes(util.StringToBytes(s))
 }
@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"encoding"
 	"fmt"
+	"io"
 	"net"
+	"strings"
 	"testing"
 	"time"
 
@@ -74,6 +76,23 @@ var _ = Describe("WriteBuffer", func() {
 		Expect(err).NotTo(HaveOccurred())
 		Expect(buf.String()).To(Equal(fmt.Sprintf("*1\r\n$16\r\n%s\r\n", bytes.NewBuffer(ip))))
 	})
+
+	It("should stream args", func() {
+		err := wr.WriteArgs([]interface{}{&proto.StreamArg{
+			Reader: bytes.NewReader(bytes.Repeat([]byte("a"), 10000)),
+			Size:   10000,
+		}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(buf.String()).To(Equal("*1\r\n$10000\r\n" + strings.Repeat("a", 10000) + "\r\n"))
+	})
+
+	It("should fail to stream short args", func() {
+		err := wr.WriteArgs([]interface{}{&proto.StreamArg{
+			Reader: strings.NewReader("short"),
+			Size:   10,
+		}})
+		Expect(err).To(MatchError(io.EOF))
+	})
 })
 
 type discard struct{}
@@ -0,0 +1,46 @@
+package util
+
+import "testing"
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern, s string
+		want       bool
+	}{
+		{"", "", true},
+		{"", "a", false},
+		{"*", "", true},
+		{"*", "anything", true},
+		{"user:*", "user:1", true},
+		{"user:*", "user:", true},
+		{"user:*", "order:1", false},
+		{"*:1", "user:1", true},
+		{"a**b", "axyzb", true},
+		{"a*b*c", "abxc", true},
+		{"a*b*c", "acb", false},
+		{"h?llo", "hello", true},
+		{"h?llo", "hllo", false},
+		{"?", "", false},
+		{"h[ae]llo", "hallo", true},
+		{"h[ae]llo", "hillo", false},
+		{"[a-z]1", "q1", true},
+		{"[a-z]1", "Q1", false},
+		{"[z-a]", "m", true},
+		{"[^x]y", "ay", true},
+		{"[^x]y", "xy", false},
+		{"[^a-c]", "b", false},
+		{"[\\]]", "]", true},
+		{"[a", "a", false},
+		{"[a]", "", false},
+		{"\\*", "*", true},
+		{"\\*", "a", false},
+		{"\\?x", "?x", true},
+		{"a\\[b", "a[b", true},
+		{"a\\", "a\\", true},
+	}
+	for _, test := range tests {
+		if got := MatchGlob(test.pattern, test.s); got != test.want {
+			t.Errorf("MatchGlob(%q, %q) = %v, wanted %v", test.pattern, test.s, got, test.want)
+		}
+	}
+}
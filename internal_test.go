@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -352,3 +353,23 @@ var _ = Describe("withConn", func() {
 		Expect(client.connPool.Len()).To(Equal(1))
 	})
 })
+
+var _ = Describe("readCmdReply", func() {
+	It("keeps RESP3 attributes of the reply", func() {
+		rd := proto.NewReader(strings.NewReader("|1\r\n+trace-id\r\n$3\r\nabc\r\n$5\r\nhello\r\n"))
+		cmd := NewStringCmd(ctx, "get", "key")
+
+		Expect(readCmdReply(rd, cmd)).NotTo(HaveOccurred())
+		Expect(cmd.Val()).To(Equal("hello"))
+		Expect(cmd.Attributes()).To(Equal(map[string]interface{}{"trace-id": "abc"}))
+	})
+
+	It("returns nil attributes for replies without attributes", func() {
+		rd := proto.NewReader(strings.NewReader(":1\r\n"))
+		cmd := NewIntCmd(ctx, "incr", "key")
+
+		Expect(readCmdReply(rd, cmd)).NotTo(HaveOccurred())
+		Expect(cmd.Val()).To(Equal(int64(1)))
+		Expect(cmd.Attributes()).To(BeNil())
+	})
+})
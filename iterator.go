@@ -64,3 +64,93 @@ func (it *ScanIterator) Val() string {
 	}
 	return v
 }
+
+// AggregateIterator is used to incrementally iterate over the rows of
+// FT.AGGREGATE with WITHCURSOR. The next batch is read with FT.CURSOR READ
+// when the current one is exhausted.
+type AggregateIterator struct {
+	process cmdable
+	index   string
+	count   int
+	cmd     *FTAggregateCmd
+	cursor  int64
+	pos     int
+}
+
+// Err returns the last iterator error, if any.
+func (it *AggregateIterator) Err() error {
+	return it.cmd.Err()
+}
+
+// Next advances the iterator and returns true if more rows can be read.
+// The server cursor is deleted when ctx is canceled or a batch can't be read.
+func (it *AggregateIterator) Next(ctx context.Context) bool {
+	// Instantly return on errors.
+	if it.cmd.Err() != nil {
+		return false
+	}
+
+	// Advance the position, check if we are still within the batch.
+	if it.pos < len(it.cmd.val.Rows) {
+		it.pos++
+		return true
+	}
+
+	for {
+		// Return if there is no more data to fetch.
+		if it.cursor == 0 {
+			return false
+		}
+
+		if err := ctx.Err(); err != nil {
+			_ = it.del(context.Background())
+			it.cmd.SetErr(err)
+			return false
+		}
+
+		// Fetch the next batch.
+		args := []interface{}{"FT.CURSOR", "READ", it.index, it.cursor}
+		if it.count > 0 {
+			args = append(args, "COUNT", it.count)
+		}
+		it.cmd = NewFTAggregateCmd(ctx, args...)
+		if err := it.process(ctx, it.cmd); err != nil {
+			// The iteration stops, the cursor would leak until its idle timeout.
+			_ = it.del(context.Background())
+			return false
+		}
+
+		it.cursor = it.cmd.val.CursorID
+		it.pos = 1
+
+		// The server can return an empty batch.
+		if len(it.cmd.val.Rows) > 0 {
+			return true
+		}
+	}
+}
+
+// Val returns the row at the current position.
+func (it *AggregateIterator) Val() AggregateRow {
+	var v AggregateRow
+	if it.cmd.Err() == nil && it.pos > 0 && it.pos <= len(it.cmd.val.Rows) {
+		v = it.cmd.val.Rows[it.pos-1]
+	}
+	return v
+}
+
+// Close deletes the server cursor, unless all the rows were read.
+// Next returns false after Close.
+func (it *AggregateIterator) Close(ctx context.Context) error {
+	it.pos = len(it.cmd.val.Rows)
+	return it.del(ctx)
+}
+
+func (it *AggregateIterator) del(ctx context.Context) error {
+	if it.cursor == 0 {
+		return nil
+	}
+	cmd := NewStatusCmd(ctx, "FT.CURSOR", "DEL", it.index, it.cursor)
+	it.cursor = 0
+	return it.process(ctx, cmd)
+}
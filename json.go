@@ -2,11 +2,9 @@ package redis
 
 import (
 	"context"
-	"encoding/json"
 	"strings"
 
 	"github.com/redis/go-redis/v9/internal/proto"
-	"github.com/redis/go-redis/v9/internal/util"
 )
 
 // -------------------------------------------
@@ -26,13 +24,14 @@ type JSONCmdable interface {
 	JSONForget(ctx context.Context, key, path string) *IntCmd
 	JSONGet(ctx context.Context, key string, paths ...string) *JSONCmd
 	JSONGetWithArgs(ctx context.Context, key string, options *JSONGetArgs, paths ...string) *JSONCmd
-	JSONMerge(ctx context.Context, key, path string, value string) *StatusCmd
+	JSONMerge(ctx context.Context, key, path string, value interface{}) *StatusCmd
 	JSONMSetArgs(ctx context.Context, docs []JSONSetArgs) *StatusCmd
 	JSONMSet(ctx context.Context, params ...interface{}) *StatusCmd
 	JSONMGet(ctx context.Context, path string, keys ...string) *JSONSliceCmd
 	JSONNumIncrBy(ctx context.Context, key, path string, value float64) *JSONCmd
 	JSONObjKeys(ctx context.Context, key, path string) *SliceCmd
 	JSONObjLen(ctx context.Context, key, path string) *IntPointerSliceCmd
+	JSONPatch(ctx context.Context, key string, patch []PatchOp) *StatusCmd
 	JSONSet(ctx context.Context, key, path string, value interface{}) *StatusCmd
 	JSONSetMode(ctx context.Context, key, path string, value interface{}, mode string) *StatusCmd
 	JSONStrAppend(ctx context.Context, key, path, value string) *IntPointerSliceCmd
@@ -61,6 +60,7 @@ type JSONCmd struct {
 	baseCmd
 	val      string
 	expanded []interface{}
+	codec    JSONCodec
 }
 
 var _ Cmder = (*JSONCmd)(nil)
@@ -82,9 +82,20 @@ func (cmd *JSONCmd) SetVal(val string) {
 	cmd.val = val
 }
 
+func (cmd *JSONCmd) setJSONCodec(codec JSONCodec) {
+	cmd.codec = codec
+}
+
+func (cmd *JSONCmd) jsonCodec() JSONCodec {
+	if cmd.codec == nil {
+		return defaultJSONCodec
+	}
+	return cmd.codec
+}
+
 func (cmd *JSONCmd) Val() string {
 	if len(cmd.val) == 0 && cmd.expanded != nil {
-		val, err := json.Marshal(cmd.expanded)
+		val, err := cmd.jsonCodec().Marshal(cmd.expanded)
 		if err != nil {
 			cmd.SetErr(err)
 			return ""
@@ -102,7 +113,7 @@ func (cmd *JSONCmd) Result() (string, error) {
 
 func (cmd JSONCmd) Expanded() (interface{}, error) {
 	if len(cmd.val) != 0 && cmd.expanded == nil {
-		err := json.Unmarshal([]byte(cmd.val), &cmd.expanded)
+		err := cmd.jsonCodec().Unmarshal([]byte(cmd.val), &cmd.expanded)
 		if err != nil {
 			return "", err
 		}
@@ -127,12 +138,14 @@ func (cmd *JSONCmd) readReply(rd *proto.Reader) error {
 			return err
 		}
 
-		expanded := make([]interface{}, size)
+		expanded := make([]interface{}, 0, proto.PreallocLen(size))
 
 		for i := 0; i < size; i++ {
-			if expanded[i], err = rd.ReadReply(); err != nil {
+			val, err := rd.ReadReply()
+			if err != nil {
 				return err
 			}
+			expanded = append(expanded, val)
 		}
 		cmd.expanded = expanded
 
@@ -202,15 +215,15 @@ func (cmd *JSONSliceCmd) readReply(rd *proto.Reader) error {
 		if err != nil {
 			return err
 		}
-		cmd.val = make([]interface{}, n)
-		for i := 0; i < len(cmd.val); i++ {
+		cmd.val = make([]interface{}, 0, proto.PreallocLen(n))
+		for i := 0; i < n; i++ {
 			switch s, err := rd.ReadString(); {
 			case err == Nil:
-				cmd.val[i] = ""
+				cmd.val = append(cmd.val, "")
 			case err != nil:
 				return err
 			default:
-				cmd.val[i] = s
+				cmd.val = append(cmd.val, s)
 			}
 		}
 	}
@@ -260,14 +273,16 @@ func (cmd *IntPointerSliceCmd) readReply(rd *proto.Reader) error {
 	if err != nil {
 		return err
 	}
-	cmd.val = make([]*int64, n)
+	cmd.val = make([]*int64, 0, proto.PreallocLen(n))
 
-	for i := 0; i < len(cmd.val); i++ {
+	for i := 0; i < n; i++ {
 		val, err := rd.ReadInt()
 		if err != nil && err != Nil {
 			return err
 		} else if err != Nil {
-			cmd.val[i] = &val
+			cmd.val = append(cmd.val, &val)
+		} else {
+			cmd.val = append(cmd.val, nil)
 		}
 	}
 
@@ -277,10 +292,14 @@ func (cmd *IntPointerSliceCmd) readReply(rd *proto.Reader) error {
 //------------------------------------------------------------------------------
 
 // JSONArrAppend adds the provided JSON values to the end of the array at the given path.
+// The values are marshaled with the JSONCodec of the client unless they are
+// strings or []byte, which are passed directly as JSON.
 // For more information, see https://redis.io/commands/json.arrappend
 func (c cmdable) JSONArrAppend(ctx context.Context, key, path string, values ...interface{}) *IntSliceCmd {
 	args := []interface{}{"JSON.ARRAPPEND", key, path}
-	args = append(args, values...)
+	for _, value := range values {
+		args = append(args, jsonArg(value))
+	}
 	cmd := NewIntSliceCmd(ctx, args...)
 	_ = c(ctx, cmd)
 	return cmd
@@ -448,10 +467,11 @@ func (c cmdable) JSONGetWithArgs(ctx context.Context, key string, options *JSONG
 	return cmd
 }
 
-// JSONMerge merges a given JSON value into matching paths.
+// JSONMerge merges a given JSON value into matching paths. The value is marshaled
+// with the JSONCodec of the client unless it is a string or a []byte.
 // For more information, see https://redis.io/commands/json.merge
-func (c cmdable) JSONMerge(ctx context.Context, key, path string, value string) *StatusCmd {
-	args := []interface{}{"JSON.MERGE", key, path, value}
+func (c cmdable) JSONMerge(ctx context.Context, key, path string, value interface{}) *StatusCmd {
+	args := []interface{}{"JSON.MERGE", key, path, jsonArg(value)}
 	cmd := NewStatusCmd(ctx, args...)
 	_ = c(ctx, cmd)
 	return cmd
@@ -474,11 +494,12 @@ func (c cmdable) JSONMGet(ctx context.Context, path string, keys ...string) *JSO
 }
 
 // JSONMSetArgs sets or updates one or more JSON values according to the specified key-path-value triplets.
+// The values are marshaled with the JSONCodec of the client unless they are strings or []byte.
 // For more information, see https://redis.io/commands/json.mset
 func (c cmdable) JSONMSetArgs(ctx context.Context, docs []JSONSetArgs) *StatusCmd {
 	args := []interface{}{"JSON.MSET"}
 	for _, doc := range docs {
-		args = append(args, doc.Key, doc.Path, doc.Value)
+		args = append(args, doc.Key, doc.Path, jsonArg(doc.Value))
 	}
 	cmd := NewStatusCmd(ctx, args...)
 	_ = c(ctx, cmd)
@@ -521,29 +542,19 @@ func (c cmdable) JSONObjLen(ctx context.Context, key, path string) *IntPointerSl
 }
 
 // JSONSet sets the JSON value at the given path in the given key. The value must be something that
-// can be marshaled to JSON (using encoding/JSON) unless the argument is a string or a []byte when we assume that
-// it can be passed directly as JSON.
+// can be marshaled to JSON (using the JSONCodec of the client) unless the argument is a string or a []byte when we
+// assume that it can be passed directly as JSON.
 // For more information, see https://redis.io/commands/json.set
 func (c cmdable) JSONSet(ctx context.Context, key, path string, value interface{}) *StatusCmd {
 	return c.JSONSetMode(ctx, key, path, value, "")
 }
 
 // JSONSetMode sets the JSON value at the given path in the given key and allows the mode to be set
-// (the mode value must be "XX" or "NX"). The value must be something that can be marshaled to JSON (using encoding/JSON) unless
-// the argument is a string or []byte when we assume that it can be passed directly as JSON.
+// (the mode value must be "XX" or "NX"). The value must be something that can be marshaled to JSON (using the JSONCodec
+// of the client) unless the argument is a string or []byte when we assume that it can be passed directly as JSON.
 // For more information, see https://redis.io/commands/json.set
 func (c cmdable) JSONSetMode(ctx context.Context, key, path string, value interface{}, mode string) *StatusCmd {
-	var bytes []byte
-	var err error
-	switch v := value.(type) {
-	case string:
-		bytes = []byte(v)
-	case []byte:
-		bytes = v
-	default:
-		bytes, err = json.Marshal(v)
-	}
-	args := []interface{}{"JSON.SET", key, path, util.BytesToString(bytes)}
+	args := []interface{}{"JSON.SET", key, path, jsonArg(value)}
 	if mode != "" {
 		switch strings.ToUpper(mode) {
 		case "XX", "NX":
@@ -554,11 +565,7 @@ func (c cmdable) JSONSetMode(ctx context.Context, key, path string, value interf
 		}
 	}
 	cmd := NewStatusCmd(ctx, args...)
-	if err != nil {
-		cmd.SetErr(err)
-	} else {
-		_ = c(ctx, cmd)
-	}
+	_ = c(ctx, cmd)
 	return cmd
 }
 
@@ -0,0 +1,256 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/redis/go-redis/v9/internal/proto"
+	"github.com/redis/go-redis/v9/internal/util"
+)
+
+// JSONCodec marshals the values written by the JSON commands and unmarshals
+// the documents they return. It's set with Options.JSONCodec, so a faster
+// encoder than encoding/json can be plugged in.
+type JSONCodec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+type stdJSONCodec struct{}
+
+func (stdJSONCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (stdJSONCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// defaultJSONCodec is used when Options.JSONCodec is nil.
+var defaultJSONCodec JSONCodec = stdJSONCodec{}
+
+// jsonValue is an arg that is marshaled with the JSONCodec of the client
+// the command is processed by.
+type jsonValue struct {
+	value interface{}
+}
+
+// jsonArg returns the arg of a JSON value: strings and []byte are passed
+// through as JSON, other values are marshaled.
+func jsonArg(value interface{}) interface{} {
+	switch value.(type) {
+	case string, []byte:
+		return value
+	default:
+		return &jsonValue{value: value}
+	}
+}
+
+// MarshalBinary marshals the value with the default codec, for the
+// commands that are written without going through applyJSONCodec.
+func (v *jsonValue) MarshalBinary() ([]byte, error) {
+	return defaultJSONCodec.Marshal(v.value)
+}
+
+func (v *jsonValue) String() string {
+	b, err := v.MarshalBinary()
+	if err != nil {
+		return fmt.Sprint(v.value)
+	}
+	return string(b)
+}
+
+// jsonCodecCmd is implemented by the commands that unmarshal JSON replies.
+type jsonCodecCmd interface {
+	setJSONCodec(codec JSONCodec)
+}
+
+// applyJSONCodec marshals the JSON values in the args of cmds with codec
+// and passes codec to the commands that unmarshal JSON replies. A command
+// whose value can't be marshaled gets the error.
+func applyJSONCodec(codec JSONCodec, cmds ...Cmder) error {
+	if codec == nil {
+		codec = defaultJSONCodec
+	}
+	for _, cmd := range cmds {
+		if cmd, ok := cmd.(jsonCodecCmd); ok {
+			cmd.setJSONCodec(codec)
+		}
+		args := cmd.Args()
+		for i, arg := range args {
+			v, ok := arg.(*jsonValue)
+			if !ok {
+				continue
+			}
+			b, err := codec.Marshal(v.value)
+			if err != nil {
+				cmd.SetErr(err)
+				return err
+			}
+			args[i] = util.BytesToString(b)
+		}
+	}
+	return nil
+}
+
+//------------------------------------------------------------------------------
+
+// JSONValueCmd is a JSON command whose reply is unmarshaled into a T with
+// the JSONCodec of the client.
+type JSONValueCmd[T any] struct {
+	baseCmd
+
+	val    T
+	codec  JSONCodec
+	decode func(codec JSONCodec, reply interface{}) (T, error)
+}
+
+var _ Cmder = (*JSONValueCmd[interface{}])(nil)
+
+func newJSONValueCmd[T any](
+	ctx context.Context, decode func(codec JSONCodec, reply interface{}) (T, error), args ...interface{},
+) *JSONValueCmd[T] {
+	return &JSONValueCmd[T]{
+		baseCmd: baseCmd{
+			ctx:  ctx,
+			args: args,
+		},
+		decode: decode,
+	}
+}
+
+func (cmd *JSONValueCmd[T]) SetVal(val T) {
+	cmd.val = val
+}
+
+func (cmd *JSONValueCmd[T]) Val() T {
+	return cmd.val
+}
+
+func (cmd *JSONValueCmd[T]) Result() (T, error) {
+	return cmd.val, cmd.err
+}
+
+func (cmd *JSONValueCmd[T]) String() string {
+	return cmdString(cmd, cmd.val)
+}
+
+func (cmd *JSONValueCmd[T]) setJSONCodec(codec JSONCodec) {
+	cmd.codec = codec
+}
+
+func (cmd *JSONValueCmd[T]) readReply(rd *proto.Reader) error {
+	reply, err := rd.ReadReply()
+	if err != nil {
+		return err
+	}
+	codec := cmd.codec
+	if codec == nil {
+		codec = defaultJSONCodec
+	}
+	cmd.val, err = cmd.decode(codec, reply)
+	return err
+}
+
+// JSONGetInto gets the value at path of the JSON document key, or the whole
+// document without a path, and unmarshals it into a T. The one-element array
+// returned for a JSONPath starting with "$" is unwrapped, and the error is
+// Nil when the key doesn't exist or the path matches no value.
+//
+//	user, err := redis.JSONGetInto[User](ctx, rdb, "user:1", "$").Result()
+func JSONGetInto[T any](ctx context.Context, c Processor, key string, path ...string) *JSONValueCmd[T] {
+	args := []interface{}{"JSON.GET", key}
+	for _, p := range path {
+		args = append(args, p)
+	}
+	unwrap := len(path) == 1 && strings.HasPrefix(path[0], "$")
+	cmd := newJSONValueCmd(ctx, jsonGetDecoder[T](unwrap), args...)
+	_ = c.Process(ctx, cmd)
+	return cmd
+}
+
+// JSONMGetInto gets the value at path of the JSON documents keys and
+// unmarshals them into Ts, like JSONGetInto. The value of a key that
+// doesn't exist, or doesn't match path, is nil.
+func JSONMGetInto[T any](ctx context.Context, c Processor, path string, keys ...string) *JSONValueCmd[[]*T] {
+	args := make([]interface{}, 0, len(keys)+2)
+	args = append(args, "JSON.MGET")
+	for _, key := range keys {
+		args = append(args, key)
+	}
+	args = append(args, path)
+	unwrap := strings.HasPrefix(path, "$")
+	cmd := newJSONValueCmd(ctx, jsonMGetDecoder[T](unwrap), args...)
+	_ = c.Process(ctx, cmd)
+	return cmd
+}
+
+// JSONSetValue marshals value and sets it at path of the JSON document key.
+// Unlike JSONSet, strings and []byte are marshaled as JSON strings.
+func JSONSetValue[T any](ctx context.Context, c Processor, key, path string, value T) *StatusCmd {
+	cmd := NewStatusCmd(ctx, "JSON.SET", key, path, &jsonValue{value: value})
+	_ = c.Process(ctx, cmd)
+	return cmd
+}
+
+func jsonGetDecoder[T any](unwrap bool) func(codec JSONCodec, reply interface{}) (T, error) {
+	return func(codec JSONCodec, reply interface{}) (T, error) {
+		var val T
+		s, ok := reply.(string)
+		if !ok {
+			return val, fmt.Errorf("redis: unexpected JSON.GET reply type %T", reply)
+		}
+		return unmarshalJSON[T](codec, s, unwrap)
+	}
+}
+
+func jsonMGetDecoder[T any](unwrap bool) func(codec JSONCodec, reply interface{}) ([]*T, error) {
+	return func(codec JSONCodec, reply interface{}) ([]*T, error) {
+		replies, ok := reply.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("redis: unexpected JSON.MGET reply type %T", reply)
+		}
+		vals := make([]*T, len(replies))
+		for i, reply := range replies {
+			if reply == nil {
+				continue
+			}
+			s, ok := reply.(string)
+			if !ok {
+				return nil, fmt.Errorf("redis: unexpected JSON.MGET value type %T", reply)
+			}
+			val, err := unmarshalJSON[T](codec, s, unwrap)
+			if err == Nil {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			vals[i] = &val
+		}
+		return vals, nil
+	}
+}
+
+func unmarshalJSON[T any](codec JSONCodec, s string, unwrap bool) (T, error) {
+	var val T
+	if !unwrap {
+		err := codec.Unmarshal([]byte(s), &val)
+		return val, err
+	}
+
+	var vals []T
+	if err := codec.Unmarshal([]byte(s), &vals); err != nil {
+		return val, err
+	}
+	switch len(vals) {
+	case 0:
+		return val, Nil
+	case 1:
+		return vals[0], nil
+	default:
+		return val, fmt.Errorf("redis: JSONPath matched %d values, wanted 1", len(vals))
+	}
+}
@@ -0,0 +1,118 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/redis/go-redis/v9/internal/proto"
+)
+
+type upperJSONCodec struct {
+	stdJSONCodec
+}
+
+func (c upperJSONCodec) Marshal(v interface{}) ([]byte, error) {
+	b, err := c.stdJSONCodec.Marshal(v)
+	return []byte(strings.ToUpper(string(b))), err
+}
+
+// processorFunc turns a cmdable into a Processor.
+type processorFunc func(ctx context.Context, cmd Cmder) error
+
+func (fn processorFunc) Process(ctx context.Context, cmd Cmder) error {
+	return fn(ctx, cmd)
+}
+
+type jsonUser struct {
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+func TestApplyJSONCodec(t *testing.T) {
+	ctx := context.Background()
+	var cmds []Cmder
+	c := cmdable(func(ctx context.Context, cmd Cmder) error {
+		cmds = append(cmds, cmd)
+		return applyJSONCodec(upperJSONCodec{}, cmd)
+	})
+
+	c.JSONSet(ctx, "k", "$", jsonUser{Name: "ann"})
+	c.JSONSet(ctx, "k", "$", `{"name":"raw"}`)
+	c.JSONMSetArgs(ctx, []JSONSetArgs{{Key: "k", Path: "$", Value: []string{"a"}}})
+	c.JSONArrAppend(ctx, "k", "$.tags", "\"b\"", true)
+	c.JSONMerge(ctx, "k", "$", map[string]int{"age": 2})
+	JSONSetValue(ctx, processorFunc(c), "k", "$.name", "bob")
+
+	want := [][]interface{}{
+		{"JSON.SET", "k", "$", `{"NAME":"ANN","AGE":0}`},
+		{"JSON.SET", "k", "$", `{"name":"raw"}`},
+		{"JSON.MSET", "k", "$", `["A"]`},
+		{"JSON.ARRAPPEND", "k", "$.tags", "\"b\"", "TRUE"},
+		{"JSON.MERGE", "k", "$", `{"AGE":2}`},
+		{"JSON.SET", "k", "$.name", `"BOB"`},
+	}
+	for i, cmd := range cmds {
+		if !reflect.DeepEqual(cmd.Args(), want[i]) {
+			t.Errorf("got %q, wanted %q", cmd.Args(), want[i])
+		}
+	}
+
+	cmd := c.JSONSet(ctx, "k", "$", make(chan int))
+	if cmd.Err() == nil {
+		t.Fatal("expected a marshal error")
+	}
+}
+
+func TestJSONGetInto(t *testing.T) {
+	ctx := context.Background()
+	var reply string
+	c := processorFunc(func(ctx context.Context, cmd Cmder) error {
+		if err := applyJSONCodec(nil, cmd); err != nil {
+			return err
+		}
+		err := cmd.readReply(proto.NewReader(strings.NewReader(reply)))
+		cmd.SetErr(err)
+		return err
+	})
+
+	reply = "$24\r\n[{\"name\":\"ann\",\"age\":3}]\r\n"
+	user, err := JSONGetInto[jsonUser](ctx, c, "user:1", "$").Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user != (jsonUser{Name: "ann", Age: 3}) {
+		t.Fatalf("got %+v", user)
+	}
+
+	reply = "$22\r\n{\"name\":\"ann\",\"age\":3}\r\n"
+	if user, err = JSONGetInto[jsonUser](ctx, c, "user:1").Result(); err != nil || user.Name != "ann" {
+		t.Fatalf("got %+v, %v", user, err)
+	}
+
+	reply = "$2\r\n[]\r\n"
+	if _, err := JSONGetInto[jsonUser](ctx, c, "user:1", "$.missing").Result(); err != Nil {
+		t.Fatalf("got %v, wanted Nil", err)
+	}
+
+	reply = "$-1\r\n"
+	if _, err := JSONGetInto[jsonUser](ctx, c, "user:2", "$").Result(); err != Nil {
+		t.Fatalf("got %v, wanted Nil", err)
+	}
+
+	reply = "*3\r\n$7\r\n[\"ann\"]\r\n$-1\r\n$2\r\n[]\r\n"
+	names, err := JSONMGetInto[string](ctx, c, "$.name", "user:1", "user:2", "user:3").Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 3 || *names[0] != "ann" || names[1] != nil || names[2] != nil {
+		t.Fatalf("got %v", names)
+	}
+
+	reply = "$13\r\n[\"ann\",\"bob\"]\r\n"
+	if _, err := JSONGetInto[string](ctx, c, "k", "$..name").Result(); err == nil || errors.Is(err, Nil) {
+		t.Fatalf("got %v, wanted a multiple values error", err)
+	}
+}
@@ -0,0 +1,363 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PatchOp is an operation of a JSON Patch (RFC 6902). Path and From are
+// JSON Pointers (RFC 6901), like "/users/0/name".
+type PatchOp struct {
+	// Op is add, remove, replace, move, copy or test.
+	Op   string `json:"op"`
+	Path string `json:"path"`
+	// From is the source of move and copy.
+	From string `json:"from,omitempty"`
+	// Value of add, replace and test, marshaled with the JSONCodec of the client.
+	// A nil Value is the JSON null.
+	Value interface{} `json:"value"`
+}
+
+// jsonPatchScript applies the patch ops passed as ARGV quadruplets of
+// op, path, from and value to the document KEYS[1]. The JSON Pointers are
+// resolved to JSONPaths level by level, so that a token is an array index
+// or an object key depending on the type of its parent. The document is
+// restored when an op fails, so a patch is applied entirely or not at all.
+var jsonPatchScript = NewScript(`
+local key = KEYS[1]
+
+local function fail(msg)
+  error({err = msg})
+end
+
+local function jsonType(path)
+  local res = redis.call('JSON.TYPE', key, path)
+  if type(res) ~= 'table' then
+    return nil
+  end
+  return res[1]
+end
+
+local function quote(token)
+  return '"' .. string.gsub(token, '[\\"]', '\\%0') .. '"'
+end
+
+local function tokens(pointer)
+  local toks = {}
+  if pointer == '' then
+    return toks
+  end
+  if string.sub(pointer, 1, 1) ~= '/' then
+    fail('invalid JSON pointer ' .. pointer)
+  end
+  for tok in string.gmatch(string.sub(pointer, 2) .. '/', '([^/]*)/') do
+    tok = string.gsub(tok, '~1', '/')
+    tok = string.gsub(tok, '~0', '~')
+    toks[#toks + 1] = tok
+  end
+  return toks
+end
+
+local function resolve(toks, n)
+  local path = '$'
+  for i = 1, n do
+    local t = jsonType(path)
+    if t == 'array' then
+      if not string.match(toks[i], '^%d+$') then
+        fail('invalid array index ' .. toks[i])
+      end
+      path = path .. '[' .. toks[i] .. ']'
+    elseif t == 'object' then
+      path = path .. '[' .. quote(toks[i]) .. ']'
+    else
+      fail('path not found')
+    end
+  end
+  return path
+end
+
+local function get(toks)
+  local res = redis.call('JSON.GET', key, resolve(toks, #toks))
+  if not res or res == '[]' then
+    fail('path not found')
+  end
+  return string.sub(res, 2, -2)
+end
+
+local function add(toks, value)
+  if #toks == 0 then
+    redis.call('JSON.SET', key, '$', value)
+    return
+  end
+  local parent = resolve(toks, #toks - 1)
+  local last = toks[#toks]
+  local t = jsonType(parent)
+  if t == 'object' then
+    redis.call('JSON.SET', key, parent .. '[' .. quote(last) .. ']', value)
+  elseif t == 'array' then
+    if last == '-' then
+      redis.call('JSON.ARRAPPEND', key, parent, value)
+    elseif string.match(last, '^%d+$') and tonumber(last) <= redis.call('JSON.ARRLEN', key, parent)[1] then
+      redis.call('JSON.ARRINSERT', key, parent, last, value)
+    else
+      fail('invalid array index ' .. last)
+    end
+  else
+    fail('path not found')
+  end
+end
+
+local function remove(toks)
+  if #toks == 0 then
+    fail('can not remove the root')
+  end
+  local path = resolve(toks, #toks)
+  if not jsonType(path) then
+    fail('path not found')
+  end
+  redis.call('JSON.DEL', key, path)
+end
+
+local function replace(toks, value)
+  local path = resolve(toks, #toks)
+  if #toks > 0 and not jsonType(path) then
+    fail('path not found')
+  end
+  redis.call('JSON.SET', key, path, value)
+end
+
+local function equal(a, b)
+  if type(a) ~= type(b) then
+    return false
+  end
+  if type(a) ~= 'table' then
+    return a == b
+  end
+  for k, v in pairs(a) do
+    if not equal(v, b[k]) then
+      return false
+    end
+  end
+  for k in pairs(b) do
+    if a[k] == nil then
+      return false
+    end
+  end
+  return true
+end
+
+local snapshot = redis.call('JSON.GET', key)
+local ok, err = pcall(function()
+  for i = 1, #ARGV, 4 do
+    local op, pointer, from, value = ARGV[i], ARGV[i + 1], ARGV[i + 2], ARGV[i + 3]
+    local ok, err = pcall(function()
+      local toks = tokens(pointer)
+      if op == 'add' then
+        add(toks, value)
+      elseif op == 'remove' then
+        remove(toks)
+      elseif op == 'replace' then
+        replace(toks, value)
+      elseif op == 'move' then
+        if string.sub(pointer, 1, #from + 1) == from .. '/' then
+          fail('can not move a value into itself')
+        end
+        local fromToks = tokens(from)
+        local moved = get(fromToks)
+        remove(fromToks)
+        add(toks, moved)
+      elseif op == 'copy' then
+        add(toks, get(tokens(from)))
+      elseif op == 'test' then
+        if not equal(cjson.decode(get(toks)), cjson.decode(value)) then
+          fail('test failed')
+        end
+      else
+        fail('unknown op')
+      end
+    end)
+    if not ok then
+      if type(err) == 'table' then
+        err = err.err
+      end
+      fail(string.format('op %d %s %s: %s', (i + 3) / 4, op, pointer, tostring(err)))
+    end
+  end
+end)
+
+if not ok then
+  if snapshot then
+    redis.call('JSON.SET', key, '$', snapshot)
+  else
+    redis.call('DEL', key)
+  end
+  if type(err) == 'table' then
+    err = err.err
+  end
+  return redis.error_reply('JSONPATCH ' .. tostring(err))
+end
+return redis.status_reply('OK')
+`)
+
+// JSONPatch - Applies a JSON Patch (RFC 6902) to the JSON document key in one
+// step with a Lua script. The ops are applied in order, and the document is
+// left untouched when one of them fails, like a test op whose value doesn't
+// match. The error of a failed op is a redis error starting with JSONPATCH.
+//
+// The script is run with EVALSHA, and with EVAL when it isn't loaded yet. In
+// a pipeline, where the fallback isn't possible, load it beforehand with
+// JSONPatchScriptLoad.
+func (c cmdable) JSONPatch(ctx context.Context, key string, patch []PatchOp) *StatusCmd {
+	args := make([]interface{}, 0, 4+4*len(patch))
+	args = append(args, "evalsha", jsonPatchScript.Hash(), 1, key)
+	var err error
+	for i, op := range patch {
+		if err = validatePatchOp(op); err != nil {
+			err = fmt.Errorf("redis: JSON patch op %d: %w", i+1, err)
+			break
+		}
+		var value interface{} = ""
+		switch op.Op {
+		case "add", "replace", "test":
+			value = &jsonValue{value: op.Value}
+		}
+		args = append(args, op.Op, op.Path, op.From, value)
+	}
+
+	cmd := NewStatusCmd(ctx, args...)
+	if err != nil {
+		cmd.SetErr(err)
+		return cmd
+	}
+	_ = c(ctx, cmd)
+	if HasErrorPrefix(cmd.Err(), "NOSCRIPT") {
+		evalArgs := append([]interface{}{"eval", jsonPatchScript.src}, args[2:]...)
+		cmd = NewStatusCmd(ctx, evalArgs...)
+		_ = c(ctx, cmd)
+	}
+	return cmd
+}
+
+// JSONPatchScriptLoad loads the script of JSONPatch.
+func JSONPatchScriptLoad(ctx context.Context, c Scripter) *StringCmd {
+	return jsonPatchScript.Load(ctx, c)
+}
+
+func validatePatchOp(op PatchOp) error {
+	switch op.Op {
+	case "add", "remove", "replace", "test":
+	case "move", "copy":
+		if err := validateJSONPointer(op.From); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown op %q", op.Op)
+	}
+	return validateJSONPointer(op.Path)
+}
+
+func validateJSONPointer(s string) error {
+	if s != "" && !strings.HasPrefix(s, "/") {
+		return fmt.Errorf("invalid JSON pointer %q", s)
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] == '~' && (i+1 == len(s) || (s[i+1] != '0' && s[i+1] != '1')) {
+			return fmt.Errorf("invalid JSON pointer %q", s)
+		}
+	}
+	return nil
+}
+
+//------------------------------------------------------------------------------
+
+// JSONDiff returns the JSON Patch that turns the JSON encoding of from into
+// the JSON encoding of to. Objects are compared member by member and arrays
+// element by element, values that differ otherwise are replaced.
+func JSONDiff(from, to interface{}) ([]PatchOp, error) {
+	a, err := jsonTree(from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := jsonTree(to)
+	if err != nil {
+		return nil, err
+	}
+	return diffJSON(nil, "", a, b), nil
+}
+
+// jsonTree returns v as decoded from its JSON encoding, with the numbers
+// kept as json.Number.
+func jsonTree(v interface{}) (interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return decodeJSONTree(b)
+}
+
+func diffJSON(patch []PatchOp, path string, a, b interface{}) []PatchOp {
+	switch a := a.(type) {
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok {
+			break
+		}
+		for _, k := range sortedKeys(a) {
+			if _, ok := b[k]; !ok {
+				patch = append(patch, PatchOp{Op: "remove", Path: path + "/" + escapeJSONPointer(k)})
+			}
+		}
+		for _, k := range sortedKeys(b) {
+			p := path + "/" + escapeJSONPointer(k)
+			if v, ok := a[k]; ok {
+				patch = diffJSON(patch, p, v, b[k])
+			} else {
+				patch = append(patch, PatchOp{Op: "add", Path: p, Value: b[k]})
+			}
+		}
+		return patch
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok {
+			break
+		}
+		n := len(a)
+		if len(b) < n {
+			n = len(b)
+		}
+		for i := 0; i < n; i++ {
+			patch = diffJSON(patch, path+"/"+strconv.Itoa(i), a[i], b[i])
+		}
+		for i := len(a) - 1; i >= len(b); i-- {
+			patch = append(patch, PatchOp{Op: "remove", Path: path + "/" + strconv.Itoa(i)})
+		}
+		for i := len(a); i < len(b); i++ {
+			patch = append(patch, PatchOp{Op: "add", Path: path + "/-", Value: b[i]})
+		}
+		return patch
+	}
+
+	if !reflect.DeepEqual(a, b) {
+		patch = append(patch, PatchOp{Op: "replace", Path: path, Value: b})
+	}
+	return patch
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func escapeJSONPointer(s string) string {
+	s = strings.ReplaceAll(s, "~", "~0")
+	return strings.ReplaceAll(s, "/", "~1")
+}
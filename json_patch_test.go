@@ -0,0 +1,116 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/redis/go-redis/v9/internal/proto"
+)
+
+func TestJSONPatchArgs(t *testing.T) {
+	ctx := context.Background()
+	var sent [][]interface{}
+	c := cmdable(func(ctx context.Context, cmd Cmder) error {
+		if err := applyJSONCodec(nil, cmd); err != nil {
+			return err
+		}
+		sent = append(sent, cmd.Args())
+		if cmd.Name() == "evalsha" {
+			cmd.SetErr(proto.RedisError("NOSCRIPT No matching script."))
+		} else {
+			cmd.(*StatusCmd).SetVal("OK")
+		}
+		return cmd.Err()
+	})
+
+	err := c.JSONPatch(ctx, "doc", []PatchOp{
+		{Op: "test", Path: "/name", Value: "ann"},
+		{Op: "move", Path: "/first", From: "/name"},
+		{Op: "add", Path: "/tags/-", Value: []int{1}},
+	}).Err()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantArgs := []interface{}{
+		"test", "/name", "", `"ann"`,
+		"move", "/first", "/name", "",
+		"add", "/tags/-", "", "[1]",
+	}
+	if len(sent) != 2 {
+		t.Fatalf("got %d commands, wanted EVALSHA and EVAL", len(sent))
+	}
+	for i, head := range [][]interface{}{
+		{"evalsha", jsonPatchScript.Hash(), 1, "doc"},
+		{"eval", jsonPatchScript.src, 1, "doc"},
+	} {
+		if want := append(head, wantArgs...); !reflect.DeepEqual(sent[i], want) {
+			t.Fatalf("got %q, wanted %q", sent[i], want)
+		}
+	}
+
+	for _, op := range []PatchOp{
+		{Op: "merge", Path: "/a"},
+		{Op: "add", Path: "a"},
+		{Op: "copy", Path: "/a", From: "/b~2"},
+	} {
+		sent = nil
+		if err := c.JSONPatch(ctx, "doc", []PatchOp{op}).Err(); err == nil || sent != nil {
+			t.Fatalf("%+v: got %v, wanted an error before sending", op, err)
+		}
+	}
+}
+
+func TestPatchOpMarshal(t *testing.T) {
+	b, err := json.Marshal([]PatchOp{{Op: "replace", Path: "/a", Value: nil}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `[{"op":"replace","path":"/a","value":null}]`; string(b) != want {
+		t.Fatalf("got %s, wanted %s", b, want)
+	}
+}
+
+func TestJSONDiff(t *testing.T) {
+	from := map[string]interface{}{
+		"name": "ann",
+		"a/b":  1,
+		"tags": []string{"x", "y", "z"},
+		"addr": map[string]interface{}{"city": "Paris", "zip": "75001"},
+	}
+	to := map[string]interface{}{
+		"name": "ann",
+		"tags": []string{"x", "w"},
+		"addr": map[string]interface{}{"city": "Lyon", "zip": "75001", "country": "FR"},
+		"age":  30,
+	}
+	patch, err := JSONDiff(from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []PatchOp{
+		{Op: "remove", Path: "/a~1b"},
+		{Op: "replace", Path: "/addr/city", Value: "Lyon"},
+		{Op: "add", Path: "/addr/country", Value: "FR"},
+		{Op: "add", Path: "/age", Value: json.Number("30")},
+		{Op: "replace", Path: "/tags/1", Value: "w"},
+		{Op: "remove", Path: "/tags/2"},
+	}
+	if !reflect.DeepEqual(patch, want) {
+		t.Fatalf("got %+v, wanted %+v", patch, want)
+	}
+
+	patch, err = JSONDiff([]int{1}, map[string]int{"a": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(patch) != 1 || patch[0].Op != "replace" || patch[0].Path != "" {
+		t.Fatalf("got %+v", patch)
+	}
+
+	if patch, _ := JSONDiff(from, from); len(patch) != 0 {
+		t.Fatalf("got %+v, wanted no ops", patch)
+	}
+}
@@ -0,0 +1,476 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/redis/go-redis/v9/internal/proto"
+)
+
+// JSONPath is a path of the JSON commands. A path starting with "$" is a
+// JSONPath, whose commands reply with an array of the values of all the
+// matches, anything else is a legacy path, whose commands reply with the
+// value of the first match.
+//
+//	path := redis.JSONPathRoot().Key("users").Wildcard().Key("first name")
+//	path.String() // $.users[*]["first name"]
+type JSONPath struct {
+	path string
+	err  error
+}
+
+// JSONPathRoot returns the JSONPath "$".
+func JSONPathRoot() JSONPath {
+	return JSONPath{path: "$"}
+}
+
+// JSONLegacyPathRoot returns the legacy path ".".
+func JSONLegacyPathRoot() JSONPath {
+	return JSONPath{path: "."}
+}
+
+// ParseJSONPath checks the syntax of the path s.
+func ParseJSONPath(s string) (JSONPath, error) {
+	if err := validateJSONPath(s); err != nil {
+		return JSONPath{}, err
+	}
+	return JSONPath{path: s}, nil
+}
+
+// String returns the path as sent to Redis.
+func (p JSONPath) String() string {
+	return p.path
+}
+
+// Err returns the error of the path built with an invalid Filter.
+func (p JSONPath) Err() error {
+	return p.err
+}
+
+// IsLegacy reports whether p is a legacy path.
+func (p JSONPath) IsLegacy() bool {
+	return !strings.HasPrefix(p.path, "$")
+}
+
+func (p JSONPath) append(s string) JSONPath {
+	if p.path == "." {
+		// The legacy root is dropped by its children: .a, not ..a.
+		if strings.HasPrefix(s, ".") {
+			return JSONPath{path: s, err: p.err}
+		}
+		p.path = ""
+	}
+	p.path += s
+	return p
+}
+
+// Key selects the member name of an object. Names that aren't identifiers
+// are quoted: ["first name"].
+func (p JSONPath) Key(name string) JSONPath {
+	if isJSONPathIdent(name) {
+		return p.append("." + name)
+	}
+	return p.append("[" + strconv.Quote(name) + "]")
+}
+
+// Index selects the element i of an array, counted from the end when
+// negative.
+func (p JSONPath) Index(i int) JSONPath {
+	return p.append("[" + strconv.Itoa(i) + "]")
+}
+
+// Wildcard selects all the members of an object or elements of an array.
+func (p JSONPath) Wildcard() JSONPath {
+	return p.append("[*]")
+}
+
+// Descendant selects the member name of the value and of all its
+// descendants: ..name.
+func (p JSONPath) Descendant(name string) JSONPath {
+	if isJSONPathIdent(name) {
+		return p.append(".." + name)
+	}
+	return p.append("..[" + strconv.Quote(name) + "]")
+}
+
+// Filter selects the elements matching the filter expression, as in
+// [?(@.price < 10)].
+func (p JSONPath) Filter(expr string) JSONPath {
+	p = p.append("[?(" + expr + ")]")
+	if p.err == nil {
+		paren := "(" + expr + ")"
+		n, err := skipJSONPathParens(paren, 0)
+		if err == nil && n != len(paren) {
+			err = fmt.Errorf("unbalanced parentheses")
+		}
+		if err != nil {
+			p.err = fmt.Errorf("redis: invalid JSONPath filter %q: %w", expr, err)
+		}
+	}
+	return p
+}
+
+func isJSONPathIdent(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, c := range s {
+		switch {
+		case c == '_', c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		case c >= '0' && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+//------------------------------------------------------------------------------
+
+// validateJSONPath checks the syntax of a JSONPath or legacy path:
+//
+//	path     = "$" segments | "." | ["."] name segments | bracket segments
+//	segments = { "." name | ".*" | ".." (name | "*" | bracket) | bracket }
+//	bracket  = "[" selector { "," selector } "]"
+//	selector = "*" | int | [int] ":" [int] [":" [int]] | quoted | "?(" filter ")"
+func validateJSONPath(s string) error {
+	if err := checkJSONPath(s); err != nil {
+		return fmt.Errorf("redis: invalid JSON path %q: %w", s, err)
+	}
+	return nil
+}
+
+func checkJSONPath(s string) error {
+	var i int
+	switch {
+	case s == "":
+		return fmt.Errorf("empty path")
+	case s == ".":
+		return nil
+	case s[0] == '$':
+		i = 1
+	case s[0] == '[', s[0] == '.':
+	default:
+		n := jsonPathNameLen(s)
+		if n == 0 {
+			return fmt.Errorf("unexpected %q at %d", s[0], 0)
+		}
+		i = n
+	}
+
+	for i < len(s) {
+		switch s[i] {
+		case '.':
+			i++
+			if i < len(s) && s[i] == '.' {
+				i++
+				if i < len(s) && s[i] == '[' {
+					continue
+				}
+			}
+			if i < len(s) && s[i] == '*' {
+				i++
+				continue
+			}
+			n := jsonPathNameLen(s[i:])
+			if n == 0 {
+				return fmt.Errorf("missing name at %d", i)
+			}
+			i += n
+		case '[':
+			n, err := checkJSONPathBracket(s, i)
+			if err != nil {
+				return err
+			}
+			i = n
+		default:
+			return fmt.Errorf("unexpected %q at %d", s[i], i)
+		}
+	}
+	return nil
+}
+
+func jsonPathNameLen(s string) int {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '.', '[', ']', '(', ')', '*', '?', ',', '\'', '"', ' ', '\t', '$':
+			return i
+		}
+	}
+	return len(s)
+}
+
+// checkJSONPathBracket checks the bracket starting at s[i] and returns the
+// position after it.
+func checkJSONPathBracket(s string, i int) (int, error) {
+	i++ // [
+	for {
+		i = skipJSONPathSpaces(s, i)
+		if i >= len(s) {
+			return 0, fmt.Errorf("unterminated bracket")
+		}
+
+		switch c := s[i]; {
+		case c == '*':
+			i++
+		case c == '\'' || c == '"':
+			n, err := skipJSONPathString(s, i)
+			if err != nil {
+				return 0, err
+			}
+			i = n
+		case c == '?':
+			n, err := skipJSONPathParens(s, i+1)
+			if err != nil {
+				return 0, err
+			}
+			i = n
+		case c == '-' || c == ':' || (c >= '0' && c <= '9'):
+			start := i
+			for i < len(s) && (s[i] == '-' || s[i] == ':' || (s[i] >= '0' && s[i] <= '9')) {
+				i++
+			}
+			if err := checkJSONPathIndex(s[start:i]); err != nil {
+				return 0, err
+			}
+		default:
+			return 0, fmt.Errorf("unexpected %q at %d", c, i)
+		}
+
+		i = skipJSONPathSpaces(s, i)
+		if i >= len(s) {
+			return 0, fmt.Errorf("unterminated bracket")
+		}
+		switch s[i] {
+		case ']':
+			return i + 1, nil
+		case ',':
+			i++
+		default:
+			return 0, fmt.Errorf("unexpected %q at %d", s[i], i)
+		}
+	}
+}
+
+// checkJSONPathIndex checks an index or a start:end:step slice.
+func checkJSONPathIndex(s string) error {
+	parts := strings.Split(s, ":")
+	if len(parts) > 3 {
+		return fmt.Errorf("invalid slice %q", s)
+	}
+	for _, part := range parts {
+		if part == "" && len(parts) > 1 {
+			continue
+		}
+		if _, err := strconv.Atoi(part); err != nil {
+			return fmt.Errorf("invalid index %q", s)
+		}
+	}
+	return nil
+}
+
+func skipJSONPathSpaces(s string, i int) int {
+	for i < len(s) && (s[i] == ' ' || s[i] == '\t') {
+		i++
+	}
+	return i
+}
+
+// skipJSONPathString returns the position after the quoted string at s[i].
+func skipJSONPathString(s string, i int) (int, error) {
+	quote := s[i]
+	for i++; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case quote:
+			return i + 1, nil
+		}
+	}
+	return 0, fmt.Errorf("unterminated string")
+}
+
+// skipJSONPathParens returns the position after the parenthesized
+// expression at s[i], skipping the quoted strings in it.
+func skipJSONPathParens(s string, i int) (int, error) {
+	if i >= len(s) || s[i] != '(' {
+		return 0, fmt.Errorf("missing ( at %d", i)
+	}
+	var depth int
+	for i < len(s) {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i + 1, nil
+			}
+		case '\'', '"':
+			n, err := skipJSONPathString(s, i)
+			if err != nil {
+				return 0, err
+			}
+			i = n
+			continue
+		}
+		i++
+	}
+	return 0, fmt.Errorf("unbalanced parentheses")
+}
+
+//------------------------------------------------------------------------------
+
+// jsonReplyCommands reply with JSON serialized values.
+var jsonReplyCommands = map[string]bool{
+	"json.get":       true,
+	"json.numincrby": true,
+	"json.nummultby": true,
+	"json.arrpop":    true,
+}
+
+// JSONPathCmd is the reply of a JSON command in the shape of its path:
+// the values of all the matches of a JSONPath, or the one value of a
+// legacy path. A match that has no value, like the length of a value
+// that isn't a string for JSON.STRLEN, is nil.
+type JSONPathCmd[T any] struct {
+	baseCmd
+
+	val       []*T
+	legacy    bool
+	jsonReply bool
+	codec     JSONCodec
+}
+
+var _ Cmder = (*JSONPathCmd[interface{}])(nil)
+
+func newJSONPathCmd[T any](ctx context.Context, path JSONPath, args ...interface{}) *JSONPathCmd[T] {
+	cmd := &JSONPathCmd[T]{
+		baseCmd: baseCmd{
+			ctx:  ctx,
+			args: args,
+		},
+		legacy: path.IsLegacy(),
+	}
+	cmd.jsonReply = jsonReplyCommands[cmd.Name()]
+	return cmd
+}
+
+func (cmd *JSONPathCmd[T]) SetVal(val []*T) {
+	cmd.val = val
+}
+
+func (cmd *JSONPathCmd[T]) Val() []*T {
+	return cmd.val
+}
+
+func (cmd *JSONPathCmd[T]) Result() ([]*T, error) {
+	return cmd.val, cmd.err
+}
+
+func (cmd *JSONPathCmd[T]) String() string {
+	return cmdString(cmd, cmd.val)
+}
+
+// IsLegacy reports whether the command was sent with a legacy path, so
+// its reply was a single value.
+func (cmd *JSONPathCmd[T]) IsLegacy() bool {
+	return cmd.legacy
+}
+
+func (cmd *JSONPathCmd[T]) setJSONCodec(codec JSONCodec) {
+	cmd.codec = codec
+}
+
+func (cmd *JSONPathCmd[T]) readReply(rd *proto.Reader) error {
+	reply, err := rd.ReadReply()
+	if err != nil {
+		return err
+	}
+	codec := cmd.codec
+	if codec == nil {
+		codec = defaultJSONCodec
+	}
+
+	if cmd.legacy {
+		val, err := cmd.decodeValue(codec, reply)
+		if err != nil {
+			return err
+		}
+		cmd.val = []*T{val}
+		return nil
+	}
+
+	switch reply := reply.(type) {
+	case []interface{}:
+		cmd.val = make([]*T, len(reply))
+		for i, r := range reply {
+			if cmd.val[i], err = cmd.decodeValue(codec, r); err != nil {
+				return err
+			}
+		}
+		return nil
+	case string:
+		if cmd.jsonReply {
+			cmd.val = nil
+			return codec.Unmarshal([]byte(reply), &cmd.val)
+		}
+	}
+	return fmt.Errorf("redis: unexpected %s reply type %T for a JSONPath", cmd.Name(), reply)
+}
+
+func (cmd *JSONPathCmd[T]) decodeValue(codec JSONCodec, reply interface{}) (*T, error) {
+	if reply == nil {
+		return nil, nil
+	}
+	if err, ok := reply.(error); ok {
+		return nil, err
+	}
+	var val T
+	if s, ok := reply.(string); ok && cmd.jsonReply {
+		if err := codec.Unmarshal([]byte(s), &val); err != nil {
+			return nil, err
+		}
+		return &val, nil
+	}
+	val, err := DecodeReply[T](reply)
+	if err != nil {
+		return nil, err
+	}
+	return &val, nil
+}
+
+// JSONDoPath sends the JSON command with key and path followed by args and
+// decodes the reply in the shape of the path, see JSONPathCmd. The values
+// returned by JSON.GET, JSON.NUMINCRBY, JSON.NUMMULTBY and JSON.ARRPOP are
+// unmarshaled with the JSONCodec of the client, the others with DecodeReply.
+// The path is checked before the command is sent.
+//
+//	lens, err := redis.JSONDoPath[int64](ctx, rdb, "JSON.STRLEN", "doc", path).Result()
+func JSONDoPath[T any](
+	ctx context.Context, c Processor, command, key string, path JSONPath, args ...interface{},
+) *JSONPathCmd[T] {
+	cmdArgs := make([]interface{}, 0, 3+len(args))
+	cmdArgs = append(cmdArgs, command, key, path.String())
+	cmdArgs = append(cmdArgs, args...)
+	cmd := newJSONPathCmd[T](ctx, path, cmdArgs...)
+
+	err := path.Err()
+	if err == nil {
+		err = validateJSONPath(path.String())
+	}
+	if err != nil {
+		cmd.SetErr(err)
+		return cmd
+	}
+	_ = c.Process(ctx, cmd)
+	return cmd
+}
+
+// JSONGetPath gets the values at path of the JSON document key, see
+// JSONDoPath.
+func JSONGetPath[T any](ctx context.Context, c Processor, key string, path JSONPath) *JSONPathCmd[T] {
+	return JSONDoPath[T](ctx, c, "JSON.GET", key, path)
+}
@@ -0,0 +1,119 @@
+package redis
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/redis/go-redis/v9/internal/proto"
+)
+
+func TestJSONPathBuilder(t *testing.T) {
+	tests := []struct {
+		path   JSONPath
+		want   string
+		legacy bool
+	}{
+		{JSONPathRoot(), "$", false},
+		{JSONPathRoot().Key("users").Wildcard().Key("first name"), `$.users[*]["first name"]`, false},
+		{JSONPathRoot().Key(`a"b`).Index(-1), `$["a\"b"][-1]`, false},
+		{JSONPathRoot().Descendant("price"), "$..price", false},
+		{JSONPathRoot().Key("items").Filter(`@.name == "a)b"`), `$.items[?(@.name == "a)b")]`, false},
+		{JSONLegacyPathRoot(), ".", true},
+		{JSONLegacyPathRoot().Key("a").Index(0), ".a[0]", true},
+		{JSONLegacyPathRoot().Key("a b"), `["a b"]`, true},
+	}
+	for _, test := range tests {
+		if got := test.path.String(); got != test.want {
+			t.Errorf("got %s, wanted %s", got, test.want)
+		}
+		if test.path.IsLegacy() != test.legacy {
+			t.Errorf("%s: got legacy %v", test.want, test.path.IsLegacy())
+		}
+		if err := test.path.Err(); err != nil {
+			t.Errorf("%s: %v", test.want, err)
+		}
+		if _, err := ParseJSONPath(test.want); err != nil {
+			t.Errorf("%s: %v", test.want, err)
+		}
+	}
+
+	if err := JSONPathRoot().Filter("@.a > (1").Err(); err == nil {
+		t.Error("expected a filter error")
+	}
+}
+
+func TestParseJSONPath(t *testing.T) {
+	valid := []string{
+		"$", ".", "a", "a.b", ".a.b", "$.a[0]", "$.a[*]", "$.a.*", "$..a", "$..*", "$..[0]",
+		"$.a[1:3]", "$.a[:2]", "$.a[::2]", "$.a[0, 2]", `$['a']`, `$["a\"b"]`,
+		"$.a[?(@.b > 1 && (@.c == 'x]'))]", "[0]",
+	}
+	for _, s := range valid {
+		if _, err := ParseJSONPath(s); err != nil {
+			t.Errorf("%s: %v", s, err)
+		}
+	}
+
+	invalid := []string{
+		"", "$.", "$..", "$a", "$.a[", "$.a[1", "$.a[x]", "$.a[1:2:3:4]", `$['a]`,
+		"$.a[?(@.b > 1]", "$.a]", "$.a b",
+	}
+	for _, s := range invalid {
+		if _, err := ParseJSONPath(s); err == nil {
+			t.Errorf("%s: expected an error", s)
+		}
+	}
+}
+
+func TestJSONDoPath(t *testing.T) {
+	ctx := context.Background()
+	var reply string
+	var sent int
+	c := processorFunc(func(ctx context.Context, cmd Cmder) error {
+		sent++
+		if err := applyJSONCodec(nil, cmd); err != nil {
+			return err
+		}
+		err := cmd.readReply(proto.NewReader(strings.NewReader(reply)))
+		cmd.SetErr(err)
+		return err
+	})
+
+	reply = "*3\r\n:3\r\n_\r\n:5\r\n"
+	lens := JSONDoPath[int64](ctx, c, "JSON.STRLEN", "doc", JSONPathRoot().Descendant("a"))
+	if lens.Err() != nil || lens.IsLegacy() {
+		t.Fatal(lens.Err())
+	}
+	if vals := lens.Val(); len(vals) != 3 || *vals[0] != 3 || vals[1] != nil || *vals[2] != 5 {
+		t.Fatalf("got %v", vals)
+	}
+
+	reply = ":3\r\n"
+	lens = JSONDoPath[int64](ctx, c, "JSON.STRLEN", "doc", JSONLegacyPathRoot().Key("a"))
+	if vals := lens.Val(); !lens.IsLegacy() || len(vals) != 1 || *vals[0] != 3 {
+		t.Fatalf("got %v, %v", vals, lens.Err())
+	}
+
+	reply = "$14\r\n[{\"b\":1},null]\r\n"
+	docs, err := JSONGetPath[map[string]int](ctx, c, "doc", JSONPathRoot().Key("a")).Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []*map[string]int{{"b": 1}, nil}; !reflect.DeepEqual(docs, want) {
+		t.Fatalf("got %v, wanted %v", docs, want)
+	}
+
+	reply = "$7\r\n{\"b\":1}\r\n"
+	docs, err = JSONGetPath[map[string]int](ctx, c, "doc", JSONLegacyPathRoot().Key("a")).Result()
+	if err != nil || len(docs) != 1 || (*docs[0])["b"] != 1 {
+		t.Fatalf("got %v, %v", docs, err)
+	}
+
+	sent = 0
+	path, _ := ParseJSONPath("$.a")
+	if err := JSONGetPath[int](ctx, c, "doc", path.Filter("(")).Err(); err == nil || sent != 0 {
+		t.Fatalf("got %v after %d commands, wanted an error before sending", err, sent)
+	}
+}
@@ -0,0 +1,923 @@
+package redis
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/redis/go-redis/v9/internal/util"
+)
+
+// JSONSchemaError is returned for a JSON value that doesn't match the JSON
+// Schema registered for its key.
+type JSONSchemaError struct {
+	Key string
+	// Path is the JSONPath of the failing value in the document.
+	Path    string
+	Message string
+}
+
+func (e *JSONSchemaError) Error() string {
+	return fmt.Sprintf("redis: JSON schema of %s: %s: %s", e.Key, e.Path, e.Message)
+}
+
+// JSONValidator checks the values written by JSON.SET, JSON.MSET and
+// JSON.MERGE against the JSON Schema registered for the pattern of their
+// key. It's attached to a client with Options.JSONValidator, which rejects
+// the invalid writes with a *JSONSchemaError before they are sent, or used
+// per call with Validate and ValidateMerge.
+//
+// The values written at a path below the root are checked against the
+// schema of that path, found through properties, additionalProperties, items,
+// $ref and the branches of allOf, anyOf and oneOf. Since the rest of the
+// document isn't known, such a value only has to match the schema of one
+// anyOf or oneOf branch. A JSON.MERGE value is a partial document: its missing members
+// aren't required, but deleting a required member with null is rejected.
+//
+// The values written at a path with a descendant (..), a filter ([?(...)]),
+// a slice or a union segment aren't checked, since the values it selects
+// can have any schema below the segment.
+type JSONValidator struct {
+	mu    sync.RWMutex
+	rules []jsonSchemaRule
+}
+
+type jsonSchemaRule struct {
+	pattern string
+	schema  *JSONSchema
+}
+
+func NewJSONValidator() *JSONValidator {
+	return &JSONValidator{}
+}
+
+// Register sets the schema of the keys matching the glob-style pattern, as
+// used by KEYS. The first registered pattern matching a key is used, and the
+// keys matching no pattern aren't checked.
+func (v *JSONValidator) Register(pattern string, schema *JSONSchema) {
+	v.mu.Lock()
+	v.rules = append(v.rules, jsonSchemaRule{pattern: pattern, schema: schema})
+	v.mu.Unlock()
+}
+
+func (v *JSONValidator) schema(key string) *JSONSchema {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	for _, rule := range v.rules {
+		if util.MatchGlob(rule.pattern, key) {
+			return rule.schema
+		}
+	}
+	return nil
+}
+
+// Validate checks the value set at path of the document key. Strings and
+// []byte are JSON, other values are marshaled with encoding/json.
+func (v *JSONValidator) Validate(key, path string, value interface{}) error {
+	return v.validate(key, path, value, false)
+}
+
+// ValidateMerge checks the value merged at path of the document key.
+func (v *JSONValidator) ValidateMerge(key, path string, value interface{}) error {
+	return v.validate(key, path, value, true)
+}
+
+func (v *JSONValidator) validate(key, path string, value interface{}, merge bool) error {
+	schema := v.schema(key)
+	if schema == nil {
+		return nil
+	}
+
+	var data []byte
+	switch value := value.(type) {
+	case string:
+		data = []byte(value)
+	case []byte:
+		data = value
+	default:
+		b, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		data = b
+	}
+
+	schemaErr := func(path, msg string) error {
+		return &JSONSchemaError{Key: key, Path: path, Message: msg}
+	}
+
+	doc, err := decodeJSONTree(data)
+	if err != nil {
+		return schemaErr(path, "invalid JSON: "+err.Error())
+	}
+	node, err := schema.at(path)
+	if err != nil {
+		return schemaErr(path, err.Error())
+	}
+	if node == nil {
+		return nil
+	}
+	if p, msg, ok := node.check(doc, JSONPath{path: path}, merge); !ok {
+		return schemaErr(p.String(), msg)
+	}
+	return nil
+}
+
+// validateCmd checks the values of the JSON.SET, JSON.MSET and JSON.MERGE
+// commands, whose args are already marshaled.
+func (v *JSONValidator) validateCmd(cmd Cmder) error {
+	args := cmd.Args()
+	var err error
+	switch cmd.Name() {
+	case "json.set":
+		if len(args) >= 4 {
+			err = v.Validate(cmd.stringArg(1), cmd.stringArg(2), args[3])
+		}
+	case "json.merge":
+		if len(args) >= 4 {
+			err = v.ValidateMerge(cmd.stringArg(1), cmd.stringArg(2), args[3])
+		}
+	case "json.mset":
+		for i := 1; i+2 < len(args) && err == nil; i += 3 {
+			err = v.Validate(cmd.stringArg(i), cmd.stringArg(i+1), args[i+2])
+		}
+	}
+	if err != nil {
+		cmd.SetErr(err)
+	}
+	return err
+}
+
+// prepareJSONCmds marshals the JSON values of cmds with codec, see
+// applyJSONCodec, and checks them with validator when it isn't nil.
+func prepareJSONCmds(codec JSONCodec, validator *JSONValidator, cmds ...Cmder) error {
+	if err := applyJSONCodec(codec, cmds...); err != nil {
+		return err
+	}
+	if validator == nil {
+		return nil
+	}
+	for _, cmd := range cmds {
+		if err := validator.validateCmd(cmd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func decodeJSONTree(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var tree interface{}
+	if err := dec.Decode(&tree); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("trailing data after the JSON value")
+	}
+	return tree, nil
+}
+
+//------------------------------------------------------------------------------
+
+// JSONSchema is a compiled JSON Schema. It supports the keywords type, enum,
+// const, properties, required, additionalProperties, minProperties,
+// maxProperties, items, minItems, maxItems, uniqueItems, minLength, maxLength,
+// pattern, minimum, maximum, exclusiveMinimum, exclusiveMaximum, multipleOf,
+// allOf, anyOf, oneOf, not, and $ref to "#", "#/$defs/..." and
+// "#/definitions/...". The other keywords are ignored.
+type JSONSchema struct {
+	root *jsonSchemaNode
+}
+
+// CompileJSONSchema compiles the JSON Schema document data.
+func CompileJSONSchema(data []byte) (*JSONSchema, error) {
+	tree, err := decodeJSONTree(data)
+	if err != nil {
+		return nil, fmt.Errorf("redis: invalid JSON schema: %w", err)
+	}
+	c := &jsonSchemaCompiler{defs: make(map[string]*jsonSchemaNode)}
+	root, err := c.compile(tree)
+	if err != nil {
+		return nil, fmt.Errorf("redis: invalid JSON schema: %w", err)
+	}
+	c.defs["#"] = root
+
+	if m, ok := tree.(map[string]interface{}); ok {
+		for _, prefix := range []string{"$defs", "definitions"} {
+			defs, _ := m[prefix].(map[string]interface{})
+			for name, def := range defs {
+				node, err := c.compile(def)
+				if err != nil {
+					return nil, fmt.Errorf("redis: invalid JSON schema %s/%s: %w", prefix, name, err)
+				}
+				c.defs["#/"+prefix+"/"+name] = node
+			}
+		}
+	}
+	for _, node := range c.refs {
+		if node.ref = c.defs[node.refName]; node.ref == nil {
+			return nil, fmt.Errorf("redis: invalid JSON schema: unsupported $ref %q", node.refName)
+		}
+	}
+	return &JSONSchema{root: root}, nil
+}
+
+// MustCompileJSONSchema is like CompileJSONSchema but panics on errors.
+func MustCompileJSONSchema(data []byte) *JSONSchema {
+	s, err := CompileJSONSchema(data)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+type jsonSchemaNode struct {
+	// never is set for the false schema.
+	never bool
+
+	types    []string
+	enum     []interface{}
+	constVal interface{}
+	hasConst bool
+
+	properties    map[string]*jsonSchemaNode
+	required      []string
+	additional    *jsonSchemaNode
+	noAdditional  bool
+	minProperties int
+	maxProperties int
+
+	items       *jsonSchemaNode
+	minItems    int
+	maxItems    int
+	uniqueItems bool
+
+	minLength int
+	maxLength int
+	pattern   *regexp.Regexp
+
+	minimum          *float64
+	maximum          *float64
+	exclusiveMinimum *float64
+	exclusiveMaximum *float64
+	multipleOf       float64
+
+	allOf []*jsonSchemaNode
+	anyOf []*jsonSchemaNode
+	oneOf []*jsonSchemaNode
+	not   *jsonSchemaNode
+
+	refName string
+	ref     *jsonSchemaNode
+}
+
+// newJSONSchemaNode returns a schema that matches any value.
+func newJSONSchemaNode() *jsonSchemaNode {
+	return &jsonSchemaNode{maxItems: -1, maxLength: -1, maxProperties: -1}
+}
+
+type jsonSchemaCompiler struct {
+	defs map[string]*jsonSchemaNode
+	refs []*jsonSchemaNode
+}
+
+func (c *jsonSchemaCompiler) compile(tree interface{}) (*jsonSchemaNode, error) {
+	switch tree := tree.(type) {
+	case bool:
+		n := newJSONSchemaNode()
+		n.never = !tree
+		return n, nil
+	case map[string]interface{}:
+		return c.compileObject(tree)
+	default:
+		return nil, fmt.Errorf("got %T, wanted an object or a boolean", tree)
+	}
+}
+
+func (c *jsonSchemaCompiler) compileObject(m map[string]interface{}) (*jsonSchemaNode, error) {
+	n := newJSONSchemaNode()
+	var err error
+
+	if ref, ok := m["$ref"].(string); ok {
+		n.refName = ref
+		c.refs = append(c.refs, n)
+	}
+
+	switch t := m["type"].(type) {
+	case nil:
+	case string:
+		n.types = []string{t}
+	case []interface{}:
+		for _, v := range t {
+			s, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid type %v", v)
+			}
+			n.types = append(n.types, s)
+		}
+	default:
+		return nil, fmt.Errorf("invalid type %v", t)
+	}
+	for _, t := range n.types {
+		switch t {
+		case "null", "boolean", "object", "array", "number", "integer", "string":
+		default:
+			return nil, fmt.Errorf("unknown type %q", t)
+		}
+	}
+
+	if enum, ok := m["enum"].([]interface{}); ok {
+		n.enum = enum
+	}
+	n.constVal, n.hasConst = m["const"]
+
+	if props, ok := m["properties"].(map[string]interface{}); ok {
+		n.properties = make(map[string]*jsonSchemaNode, len(props))
+		for name, prop := range props {
+			if n.properties[name], err = c.compile(prop); err != nil {
+				return nil, fmt.Errorf("properties/%s: %w", name, err)
+			}
+		}
+	}
+	if required, ok := m["required"].([]interface{}); ok {
+		for _, r := range required {
+			s, ok := r.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid required %v", r)
+			}
+			n.required = append(n.required, s)
+		}
+	}
+	switch additional := m["additionalProperties"].(type) {
+	case nil:
+	case bool:
+		n.noAdditional = !additional
+	default:
+		if n.additional, err = c.compile(additional); err != nil {
+			return nil, fmt.Errorf("additionalProperties: %w", err)
+		}
+	}
+	if items, ok := m["items"]; ok {
+		if n.items, err = c.compile(items); err != nil {
+			return nil, fmt.Errorf("items: %w", err)
+		}
+	}
+	n.uniqueItems, _ = m["uniqueItems"].(bool)
+
+	ints := []struct {
+		name string
+		dst  *int
+	}{
+		{"minProperties", &n.minProperties},
+		{"maxProperties", &n.maxProperties},
+		{"minItems", &n.minItems},
+		{"maxItems", &n.maxItems},
+		{"minLength", &n.minLength},
+		{"maxLength", &n.maxLength},
+	}
+	for _, kw := range ints {
+		if v, ok := m[kw.name]; ok {
+			if *kw.dst, err = jsonSchemaInt(v); err != nil {
+				return nil, fmt.Errorf("%s: %w", kw.name, err)
+			}
+		}
+	}
+
+	floats := []struct {
+		name string
+		dst  **float64
+	}{
+		{"minimum", &n.minimum},
+		{"maximum", &n.maximum},
+		{"exclusiveMinimum", &n.exclusiveMinimum},
+		{"exclusiveMaximum", &n.exclusiveMaximum},
+	}
+	for _, kw := range floats {
+		if v, ok := m[kw.name]; ok {
+			f, ok := jsonNumber(v)
+			if !ok {
+				return nil, fmt.Errorf("%s: got %v, wanted a number", kw.name, v)
+			}
+			*kw.dst = &f
+		}
+	}
+	if v, ok := m["multipleOf"]; ok {
+		f, ok := jsonNumber(v)
+		if !ok || f <= 0 {
+			return nil, fmt.Errorf("multipleOf: got %v, wanted a positive number", v)
+		}
+		n.multipleOf = f
+	}
+
+	if pattern, ok := m["pattern"].(string); ok {
+		if n.pattern, err = regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("pattern: %w", err)
+		}
+	}
+
+	lists := []struct {
+		name string
+		dst  *[]*jsonSchemaNode
+	}{
+		{"allOf", &n.allOf},
+		{"anyOf", &n.anyOf},
+		{"oneOf", &n.oneOf},
+	}
+	for _, kw := range lists {
+		list, ok := m[kw.name].([]interface{})
+		if !ok {
+			continue
+		}
+		for i, s := range list {
+			node, err := c.compile(s)
+			if err != nil {
+				return nil, fmt.Errorf("%s/%d: %w", kw.name, i, err)
+			}
+			*kw.dst = append(*kw.dst, node)
+		}
+	}
+	if not, ok := m["not"]; ok {
+		if n.not, err = c.compile(not); err != nil {
+			return nil, fmt.Errorf("not: %w", err)
+		}
+	}
+	return n, nil
+}
+
+func jsonSchemaInt(v interface{}) (int, error) {
+	f, ok := jsonNumber(v)
+	if !ok || f < 0 || f != math.Trunc(f) {
+		return 0, fmt.Errorf("got %v, wanted a non-negative integer", v)
+	}
+	return int(f), nil
+}
+
+func jsonNumber(v interface{}) (float64, bool) {
+	num, ok := v.(json.Number)
+	if !ok {
+		return 0, false
+	}
+	f, err := num.Float64()
+	return f, err == nil
+}
+
+// at returns the schema of the values at path, or nil when they can be
+// anything or path can't be resolved against the schema.
+func (s *JSONSchema) at(path string) (*jsonSchemaNode, error) {
+	segments, ok, err := jsonPathSegments(path)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return s.root.at(segments)
+}
+
+// at returns the schema of the values at the path segments below n, or nil
+// when they can be anything. The branches of allOf, anyOf and oneOf are
+// walked too: the values must match the schemas of every allOf branch, and
+// of one anyOf or oneOf branch at least, since the branch the whole document
+// matches can't be known from a part of it.
+func (n *jsonSchemaNode) at(segments []string) (*jsonSchemaNode, error) {
+	for n != nil && n.ref != nil {
+		n = n.ref
+	}
+	if n == nil || n.never || len(segments) == 0 {
+		return n, nil
+	}
+
+	var all []*jsonSchemaNode
+	child, err := n.child(segments[0])
+	if err != nil {
+		return nil, err
+	}
+	if child, err = child.at(segments[1:]); err != nil {
+		return nil, err
+	}
+	if child != nil {
+		all = append(all, child)
+	}
+
+	for _, branch := range n.allOf {
+		node, err := branch.at(segments)
+		if err != nil {
+			return nil, err
+		}
+		if node != nil {
+			all = append(all, node)
+		}
+	}
+
+	for _, branches := range [][]*jsonSchemaNode{n.anyOf, n.oneOf} {
+		if len(branches) == 0 {
+			continue
+		}
+		anyNode := newJSONSchemaNode()
+		var firstErr error
+		for _, branch := range branches {
+			node, err := branch.at(segments)
+			if err != nil {
+				// The document can't match this branch.
+				if firstErr == nil {
+					firstErr = err
+				}
+				continue
+			}
+			if node == nil {
+				anyNode = nil
+				break
+			}
+			anyNode.anyOf = append(anyNode.anyOf, node)
+		}
+		if anyNode == nil {
+			continue
+		}
+		if len(anyNode.anyOf) == 0 {
+			return nil, firstErr
+		}
+		all = append(all, anyNode)
+	}
+
+	switch len(all) {
+	case 0:
+		return nil, nil
+	case 1:
+		return all[0], nil
+	}
+	node := newJSONSchemaNode()
+	node.allOf = all
+	return node, nil
+}
+
+// child returns the schema of the member or item seg of n.
+func (n *jsonSchemaNode) child(seg string) (*jsonSchemaNode, error) {
+	isItem := seg == "*" || isJSONIndex(seg)
+	parent := "object"
+	if isItem {
+		parent = "array"
+	}
+	if len(n.types) > 0 {
+		var ok bool
+		for _, t := range n.types {
+			ok = ok || t == parent
+		}
+		if !ok {
+			return nil, fmt.Errorf("got %s, wanted %s", parent, strings.Join(n.types, " or "))
+		}
+	}
+
+	if isItem {
+		return n.items, nil
+	}
+	name := seg[1:] // .name
+	if prop, ok := n.properties[name]; ok {
+		return prop, nil
+	}
+	if n.noAdditional {
+		return nil, fmt.Errorf("member %q is not allowed by the schema", name)
+	}
+	return n.additional, nil
+}
+
+func isJSONIndex(seg string) bool {
+	_, err := strconv.Atoi(seg)
+	return err == nil
+}
+
+// jsonPathSegments splits a path made of member names, prefixed with a dot,
+// array indexes and wildcards, like $.a["b c"][0][*]. It returns false for
+// the other paths, like $..a or $.a[?(@.b>1)].
+func jsonPathSegments(path string) ([]string, bool, error) {
+	if err := validateJSONPath(path); err != nil {
+		return nil, false, err
+	}
+
+	s := path
+	switch {
+	case s == "$" || s == ".":
+		return nil, true, nil
+	case strings.HasPrefix(s, "$"):
+		s = s[1:]
+	case !strings.HasPrefix(s, ".") && !strings.HasPrefix(s, "["):
+		s = "." + s
+	}
+
+	var segments []string
+	for len(s) > 0 {
+		switch {
+		case strings.HasPrefix(s, ".."):
+			return nil, false, nil
+		case strings.HasPrefix(s, ".*"):
+			segments = append(segments, "*")
+			s = s[2:]
+		case s[0] == '.':
+			n := jsonPathNameLen(s[1:])
+			segments = append(segments, "."+s[1:1+n])
+			s = s[1+n:]
+		case strings.HasPrefix(s, "[*]"):
+			segments = append(segments, "*")
+			s = s[3:]
+		case strings.HasPrefix(s, "['") || strings.HasPrefix(s, `["`):
+			end, err := skipJSONPathString(s, 1)
+			if err != nil || end >= len(s) || s[end] != ']' {
+				return nil, false, nil
+			}
+			name, err := unquoteJSONPathString(s[1:end])
+			if err != nil {
+				return nil, false, nil
+			}
+			segments = append(segments, "."+name)
+			s = s[end+1:]
+		default:
+			end := strings.IndexByte(s, ']')
+			if end < 0 || !isJSONIndex(s[1:end]) {
+				return nil, false, nil
+			}
+			segments = append(segments, s[1:end])
+			s = s[end+1:]
+		}
+	}
+	return segments, true, nil
+}
+
+func unquoteJSONPathString(s string) (string, error) {
+	if s[0] == '\'' {
+		s = `"` + strings.ReplaceAll(strings.ReplaceAll(s[1:len(s)-1], `\'`, `'`), `"`, `\"`) + `"`
+	}
+	return strconv.Unquote(s)
+}
+
+// check returns the path of the first value of v that doesn't match the
+// schema and why. A partial value is a JSON.MERGE patch.
+func (n *jsonSchemaNode) check(v interface{}, path JSONPath, partial bool) (JSONPath, string, bool) {
+	for n.ref != nil {
+		n = n.ref
+	}
+	if n.never {
+		return path, "no value is allowed", false
+	}
+
+	if len(n.types) > 0 && !n.hasType(v) {
+		return path, fmt.Sprintf("got %s, wanted %s", jsonTypeOf(v), strings.Join(n.types, " or ")), false
+	}
+	if n.enum != nil {
+		var found bool
+		for _, e := range n.enum {
+			if jsonEqual(v, e) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return path, "value is not one of the enum values", false
+		}
+	}
+	if n.hasConst && !jsonEqual(v, n.constVal) {
+		return path, "value is not the const value", false
+	}
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if p, msg, ok := n.checkObject(v, path, partial); !ok {
+			return p, msg, false
+		}
+	case []interface{}:
+		if p, msg, ok := n.checkArray(v, path); !ok {
+			return p, msg, false
+		}
+	case string:
+		if msg, ok := n.checkString(v); !ok {
+			return path, msg, false
+		}
+	case json.Number:
+		if msg, ok := n.checkNumber(v); !ok {
+			return path, msg, false
+		}
+	}
+
+	for _, s := range n.allOf {
+		if p, msg, ok := s.check(v, path, partial); !ok {
+			return p, msg, false
+		}
+	}
+	if len(n.anyOf) > 0 {
+		var matched bool
+		for _, s := range n.anyOf {
+			if _, _, ok := s.check(v, path, partial); ok {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return path, "value matches no schema of anyOf", false
+		}
+	}
+	if len(n.oneOf) > 0 {
+		var matched int
+		for _, s := range n.oneOf {
+			if _, _, ok := s.check(v, path, partial); ok {
+				matched++
+			}
+		}
+		if matched != 1 {
+			return path, fmt.Sprintf("value matches %d schemas of oneOf, wanted 1", matched), false
+		}
+	}
+	if n.not != nil {
+		if _, _, ok := n.not.check(v, path, partial); ok {
+			return path, "value matches the schema of not", false
+		}
+	}
+	return path, "", true
+}
+
+func (n *jsonSchemaNode) checkObject(m map[string]interface{}, path JSONPath, partial bool) (JSONPath, string, bool) {
+	for _, name := range n.required {
+		v, ok := m[name]
+		switch {
+		case partial && ok && v == nil:
+			return path.Key(name), "required member can't be deleted", false
+		case !partial && !ok:
+			return path, fmt.Sprintf("missing required member %q", name), false
+		}
+	}
+	if !partial {
+		if len(m) < n.minProperties {
+			return path, fmt.Sprintf("got %d members, wanted at least %d", len(m), n.minProperties), false
+		}
+		if n.maxProperties >= 0 && len(m) > n.maxProperties {
+			return path, fmt.Sprintf("got %d members, wanted at most %d", len(m), n.maxProperties), false
+		}
+	}
+
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		v := m[name]
+		if partial && v == nil {
+			// null deletes the member.
+			continue
+		}
+		s, ok := n.properties[name]
+		if !ok {
+			if n.noAdditional {
+				return path.Key(name), "member is not allowed", false
+			}
+			s = n.additional
+		}
+		if s == nil {
+			continue
+		}
+		if p, msg, ok := s.check(v, path.Key(name), partial); !ok {
+			return p, msg, false
+		}
+	}
+	return path, "", true
+}
+
+func (n *jsonSchemaNode) checkArray(a []interface{}, path JSONPath) (JSONPath, string, bool) {
+	if len(a) < n.minItems {
+		return path, fmt.Sprintf("got %d items, wanted at least %d", len(a), n.minItems), false
+	}
+	if n.maxItems >= 0 && len(a) > n.maxItems {
+		return path, fmt.Sprintf("got %d items, wanted at most %d", len(a), n.maxItems), false
+	}
+	if n.uniqueItems {
+		for i := range a {
+			for j := 0; j < i; j++ {
+				if jsonEqual(a[i], a[j]) {
+					return path.Index(i), fmt.Sprintf("item is a duplicate of item %d", j), false
+				}
+			}
+		}
+	}
+	if n.items != nil {
+		// The items of an array are replaced by a merge, not merged.
+		for i, v := range a {
+			if p, msg, ok := n.items.check(v, path.Index(i), false); !ok {
+				return p, msg, false
+			}
+		}
+	}
+	return path, "", true
+}
+
+func (n *jsonSchemaNode) checkString(s string) (string, bool) {
+	if n.minLength > 0 || n.maxLength >= 0 {
+		l := utf8.RuneCountInString(s)
+		if l < n.minLength {
+			return fmt.Sprintf("got %d characters, wanted at least %d", l, n.minLength), false
+		}
+		if n.maxLength >= 0 && l > n.maxLength {
+			return fmt.Sprintf("got %d characters, wanted at most %d", l, n.maxLength), false
+		}
+	}
+	if n.pattern != nil && !n.pattern.MatchString(s) {
+		return fmt.Sprintf("value doesn't match the pattern %q", n.pattern), false
+	}
+	return "", true
+}
+
+func (n *jsonSchemaNode) checkNumber(num json.Number) (string, bool) {
+	f, err := num.Float64()
+	if err != nil {
+		return "invalid number", false
+	}
+	switch {
+	case n.minimum != nil && f < *n.minimum:
+		return fmt.Sprintf("got %s, wanted at least %v", num, *n.minimum), false
+	case n.maximum != nil && f > *n.maximum:
+		return fmt.Sprintf("got %s, wanted at most %v", num, *n.maximum), false
+	case n.exclusiveMinimum != nil && f <= *n.exclusiveMinimum:
+		return fmt.Sprintf("got %s, wanted more than %v", num, *n.exclusiveMinimum), false
+	case n.exclusiveMaximum != nil && f >= *n.exclusiveMaximum:
+		return fmt.Sprintf("got %s, wanted less than %v", num, *n.exclusiveMaximum), false
+	}
+	if n.multipleOf > 0 {
+		if q := f / n.multipleOf; math.Abs(q-math.Round(q)) > 1e-9 {
+			return fmt.Sprintf("got %s, wanted a multiple of %v", num, n.multipleOf), false
+		}
+	}
+	return "", true
+}
+
+func (n *jsonSchemaNode) hasType(v interface{}) bool {
+	t := jsonTypeOf(v)
+	for _, want := range n.types {
+		if want == t || (want == "number" && t == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+func jsonTypeOf(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case json.Number:
+		if f, err := v.Float64(); err == nil && f == math.Trunc(f) && !math.IsInf(f, 0) {
+			return "integer"
+		}
+		return "number"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+// jsonEqual compares decoded JSON values, with the numbers compared by value.
+func jsonEqual(a, b interface{}) bool {
+	if na, ok := a.(json.Number); ok {
+		nb, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		fa, errA := na.Float64()
+		fb, errB := nb.Float64()
+		return errA == nil && errB == nil && fa == fb
+	}
+	switch a := a.(type) {
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for k, v := range a {
+			w, ok := b[k]
+			if !ok || !jsonEqual(v, w) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !jsonEqual(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
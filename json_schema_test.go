@@ -0,0 +1,246 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+const testUserSchema = `{
+	"type": "object",
+	"required": ["name", "age"],
+	"properties": {
+		"name": {"type": "string", "minLength": 1},
+		"age": {"type": "integer", "minimum": 0},
+		"email": {"type": "string", "pattern": "^[^@]+@[^@]+$"},
+		"tags": {"type": "array", "items": {"$ref": "#/$defs/tag"}, "uniqueItems": true},
+		"role": {"enum": ["admin", "user"]}
+	},
+	"additionalProperties": false,
+	"$defs": {
+		"tag": {"type": "string", "maxLength": 3}
+	}
+}`
+
+func TestJSONValidator(t *testing.T) {
+	v := NewJSONValidator()
+	v.Register("user:*", MustCompileJSONSchema([]byte(testUserSchema)))
+
+	tests := []struct {
+		key, path string
+		value     interface{}
+		merge     bool
+		errPath   string
+	}{
+		{key: "user:1", path: "$", value: `{"name":"ann","age":3,"tags":["a","b"]}`},
+		{key: "user:1", path: "$", value: jsonUser{Name: "ann", Age: 3}},
+		{key: "user:1", path: "$", value: `{"name":"ann"}`, errPath: "$"},
+		{key: "user:1", path: "$", value: `{"name":"","age":3}`, errPath: "$.name"},
+		{key: "user:1", path: "$", value: `{"name":"ann","age":3.5}`, errPath: "$.age"},
+		{key: "user:1", path: "$", value: `{"name":"ann","age":3,"x":1}`, errPath: "$.x"},
+		{key: "user:1", path: "$", value: `{"name":"ann","age":3,"email":"nope"}`, errPath: "$.email"},
+		{key: "user:1", path: "$", value: `{"name":"ann","age":3,"tags":["a","long"]}`, errPath: "$.tags[1]"},
+		{key: "user:1", path: "$", value: `{"name":"ann","age":3,"tags":["a","a"]}`, errPath: "$.tags[1]"},
+		{key: "user:1", path: "$", value: `{"name":"ann","age":3,"role":"root"}`, errPath: "$.role"},
+		{key: "user:1", path: "$", value: `{"name":`, errPath: "$"},
+		{key: "user:1", path: "$.age", value: `-1`, errPath: "$.age"},
+		{key: "user:1", path: "$.age", value: `2`},
+		{key: "user:1", path: "$.tags[0]", value: `"abcd"`, errPath: "$.tags[0]"},
+		{key: "user:1", path: `$["tags"][*]`, value: `"ab"`},
+		{key: "user:1", path: "$.other", value: `1`, errPath: "$.other"},
+		{key: "user:1", path: "$.name.first", value: `"a"`, errPath: "$.name.first"},
+		{key: "user:1", path: "age", value: `"x"`, errPath: "age"},
+		// The paths that can't be resolved against the schema aren't checked.
+		{key: "user:1", path: "$..name", value: `1`},
+		{key: "user:1", path: `$.tags[?(@=="a")]`, value: `1`},
+		{key: "user:1", path: "$.tags[0:1]", value: `1`},
+		{key: "user:1", path: "$.tags[0,1]", value: `1`},
+		{key: "user:1", path: "$.[", value: `1`, errPath: "$.["},
+		{key: "user:1", path: "$", value: `{"age":4}`, merge: true},
+		{key: "user:1", path: "$", value: `{"email":null}`, merge: true},
+		{key: "user:1", path: "$", value: `{"name":null}`, merge: true, errPath: "$.name"},
+		{key: "user:1", path: "$", value: `{"age":"x"}`, merge: true, errPath: "$.age"},
+		{key: "other", path: "$", value: `{"anything":true}`},
+	}
+	for _, test := range tests {
+		var err error
+		if test.merge {
+			err = v.ValidateMerge(test.key, test.path, test.value)
+		} else {
+			err = v.Validate(test.key, test.path, test.value)
+		}
+		if test.errPath == "" {
+			if err != nil {
+				t.Errorf("%s %v: %v", test.path, test.value, err)
+			}
+			continue
+		}
+		var schemaErr *JSONSchemaError
+		if !errors.As(err, &schemaErr) {
+			t.Errorf("%s %v: got %v, wanted a JSONSchemaError", test.path, test.value, err)
+			continue
+		}
+		if schemaErr.Key != test.key || schemaErr.Path != test.errPath {
+			t.Errorf("%s %v: got %v, wanted the path %s", test.path, test.value, err, test.errPath)
+		}
+	}
+}
+
+func TestCompileJSONSchema(t *testing.T) {
+	valid := []string{
+		`true`,
+		`{"anyOf": [{"type": "string"}, {"type": "null"}]}`,
+		`{"type": ["string", "integer"], "$ref": "#"}`,
+	}
+	for _, s := range valid {
+		if _, err := CompileJSONSchema([]byte(s)); err != nil {
+			t.Errorf("%s: %v", s, err)
+		}
+	}
+
+	invalid := []string{
+		`1`,
+		`{"type": "text"}`,
+		`{"minLength": -1}`,
+		`{"pattern": "("}`,
+		`{"multipleOf": 0}`,
+		`{"$ref": "http://example.com/schema"}`,
+		`{"properties": {"a": 1}}`,
+	}
+	for _, s := range invalid {
+		if _, err := CompileJSONSchema([]byte(s)); err == nil {
+			t.Errorf("%s: expected an error", s)
+		}
+	}
+}
+
+func TestJSONSchemaCombinators(t *testing.T) {
+	v := NewJSONValidator()
+	v.Register("*", MustCompileJSONSchema([]byte(`{
+		"oneOf": [{"type": "integer"}, {"type": "number", "multipleOf": 0.5}],
+		"not": {"const": 0}
+	}`)))
+
+	for value, ok := range map[string]bool{
+		`1.5`: true,
+		`1`:   false, // matches both
+		`0.3`: false,
+		`0`:   false,
+	} {
+		err := v.Validate("k", "$", value)
+		if ok != (err == nil) {
+			t.Errorf("%s: got %v", value, err)
+		}
+	}
+}
+
+func TestJSONSchemaComposedPaths(t *testing.T) {
+	v := NewJSONValidator()
+	v.Register("*", MustCompileJSONSchema([]byte(`{
+		"allOf": [
+			{"$ref": "#/$defs/named"},
+			{"properties": {"age": {"type": "integer", "minimum": 0}}}
+		],
+		"properties": {
+			"contact": {
+				"anyOf": [
+					{"type": "null"},
+					{"type": "object", "properties": {"city": {"type": "string"}}, "additionalProperties": false},
+					{"type": "object", "properties": {"city": {"type": "integer"}, "zip": {"type": "string"}}}
+				]
+			},
+			"meta": {"anyOf": [{"type": "object"}, {"type": "object", "properties": {"v": {"type": "integer"}}}]}
+		},
+		"$defs": {
+			"named": {"properties": {"name": {"type": "string", "minLength": 1}}}
+		}
+	}`)))
+
+	for _, test := range []struct {
+		path, value string
+		ok          bool
+	}{
+		{"$.name", `"ann"`, true},
+		{"$.name", `""`, false},
+		{"$.name", `1`, false},
+		{"$.age", `3`, true},
+		{"$.age", `-1`, false},
+		{"$.contact.city", `"Paris"`, true},
+		{"$.contact.city", `75`, true},
+		{"$.contact.city", `true`, false},
+		{"$.contact.zip", `"75001"`, true},
+		{"$.contact.zip", `1`, false},
+		{"$.meta.v", `"x"`, true}, // the first branch allows anything
+		{"$.other", `1`, true},
+	} {
+		err := v.Validate("k", test.path, test.value)
+		if test.ok != (err == nil) {
+			t.Errorf("%s %s: got %v", test.path, test.value, err)
+		}
+	}
+}
+
+func TestJSONValidatorCmds(t *testing.T) {
+	ctx := context.Background()
+	v := NewJSONValidator()
+	v.Register("user:*", MustCompileJSONSchema([]byte(testUserSchema)))
+
+	var sent int
+	c := cmdable(func(ctx context.Context, cmd Cmder) error {
+		if err := prepareJSONCmds(nil, v, cmd); err != nil {
+			return err
+		}
+		sent++
+		return nil
+	})
+
+	if err := c.JSONSet(ctx, "user:1", "$", jsonUser{Name: "ann", Age: 1}).Err(); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.JSONSetMode(ctx, "user:1", "$.age", 2, "XX").Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	var schemaErr *JSONSchemaError
+	cmds := []Cmder{
+		c.JSONSet(ctx, "user:1", "$", jsonUser{Age: 1}),
+		c.JSONSetMode(ctx, "user:1", "$.age", "x", "NX"),
+		c.JSONMerge(ctx, "user:1", "$", map[string]interface{}{"name": nil}),
+		c.JSONMSetArgs(ctx, []JSONSetArgs{
+			{Key: "other", Path: "$", Value: 1},
+			{Key: "user:2", Path: "$.name", Value: 1},
+		}),
+	}
+	for _, cmd := range cmds {
+		if !errors.As(cmd.Err(), &schemaErr) {
+			t.Errorf("%v: got %v, wanted a JSONSchemaError", cmd.Args(), cmd.Err())
+		}
+	}
+	if sent != 2 {
+		t.Fatalf("got %d commands sent, wanted 2", sent)
+	}
+	if schemaErr.Key != "user:2" || schemaErr.Path != "$.name" {
+		t.Fatalf("got %v", schemaErr)
+	}
+}
+
+func TestJSONValidatorOption(t *testing.T) {
+	ctx := context.Background()
+	v := NewJSONValidator()
+	v.Register("user:*", MustCompileJSONSchema([]byte(testUserSchema)))
+
+	// The address is never dialed for a rejected value.
+	rdb := NewClient(&Options{Addr: "127.0.0.1:0", JSONValidator: v, MaxRetries: -1})
+	defer rdb.Close()
+
+	var schemaErr *JSONSchemaError
+	if err := rdb.JSONSet(ctx, "user:1", "$.age", -1).Err(); !errors.As(err, &schemaErr) {
+		t.Fatalf("got %v, wanted a JSONSchemaError", err)
+	}
+
+	pipe := rdb.Pipeline()
+	set := pipe.JSONSet(ctx, "user:1", "$", `{"age":1}`)
+	if _, err := pipe.Exec(ctx); !errors.As(err, &schemaErr) || !errors.As(set.Err(), &schemaErr) {
+		t.Fatalf("got %v, wanted a JSONSchemaError", err)
+	}
+}
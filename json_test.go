@@ -2,6 +2,7 @@ package redis_test
 
 import (
 	"context"
+	"encoding/json"
 
 	. "github.com/bsm/ginkgo/v2"
 	. "github.com/bsm/gomega"
@@ -657,4 +658,116 @@ var _ = Describe("JSON Commands", Label("json"), func() {
 			Expect(cmd2.Val()[0]).To(Or(Equal([]interface{}{"boolean"}), Equal("boolean")))
 		})
 	})
+
+	Describe("patch", Label("json.patch"), func() {
+		const doc = `{"name":"ann","age":30,"tags":["a","b"],"addr":{"city":"Paris"},"a/b":1}`
+
+		BeforeEach(func() {
+			Expect(client.JSONSet(ctx, "doc", "$", doc).Err()).NotTo(HaveOccurred())
+		})
+
+		It("should apply each op", Label("json.patch", "json"), func() {
+			err := client.JSONPatch(ctx, "doc", []redis.PatchOp{
+				{Op: "add", Path: "/email", Value: "ann@example.com"},
+				{Op: "add", Path: "/tags/1", Value: "x"},
+				{Op: "add", Path: "/tags/-", Value: "c"},
+				{Op: "remove", Path: "/age"},
+				{Op: "remove", Path: "/a~1b"},
+				{Op: "replace", Path: "/addr/city", Value: "Lyon"},
+				{Op: "test", Path: "/name", Value: "ann"},
+				{Op: "move", Path: "/first", From: "/name"},
+				{Op: "copy", Path: "/addr/town", From: "/addr/city"},
+			}).Err()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(client.JSONGet(ctx, "doc").Val()).To(MatchJSON(`{
+				"first": "ann",
+				"email": "ann@example.com",
+				"tags": ["a", "x", "b", "c"],
+				"addr": {"city": "Lyon", "town": "Lyon"}
+			}`))
+		})
+
+		It("should replace with null and the root", Label("json.patch", "json"), func() {
+			err := client.JSONPatch(ctx, "doc", []redis.PatchOp{
+				{Op: "replace", Path: "/name", Value: nil},
+				{Op: "test", Path: "/name", Value: nil},
+			}).Err()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(client.JSONGet(ctx, "doc", "$.name").Val()).To(MatchJSON(`[null]`))
+
+			err = client.JSONPatch(ctx, "doc", []redis.PatchOp{
+				{Op: "replace", Path: "", Value: []int{1, 2}},
+			}).Err()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(client.JSONGet(ctx, "doc").Val()).To(MatchJSON(`[1, 2]`))
+		})
+
+		It("should leave the document unchanged when an op fails", Label("json.patch", "json"), func() {
+			for _, patch := range [][]redis.PatchOp{
+				{
+					{Op: "replace", Path: "/name", Value: "bob"},
+					{Op: "test", Path: "/age", Value: 31},
+					{Op: "add", Path: "/email", Value: "bob@example.com"},
+				},
+				{
+					{Op: "remove", Path: "/tags/0"},
+					{Op: "move", Path: "/addr/city/x", From: "/addr"},
+				},
+				{
+					{Op: "add", Path: "/tags/3", Value: "z"},
+				},
+				{
+					{Op: "remove", Path: "/missing"},
+				},
+				{
+					{Op: "copy", Path: "/name", From: "/missing"},
+				},
+			} {
+				err := client.JSONPatch(ctx, "doc", patch).Err()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(HavePrefix("JSONPATCH op "))
+				Expect(client.JSONGet(ctx, "doc").Val()).To(MatchJSON(doc))
+			}
+
+			err := client.JSONPatch(ctx, "doc", []redis.PatchOp{
+				{Op: "replace", Path: "/name", Value: "bob"},
+				{Op: "test", Path: "/age", Value: 31},
+			}).Err()
+			Expect(err).To(MatchError("JSONPATCH op 2 test /age: test failed"))
+		})
+
+		It("should patch a missing key", Label("json.patch", "json"), func() {
+			err := client.JSONPatch(ctx, "missing", []redis.PatchOp{
+				{Op: "add", Path: "/a", Value: 1},
+			}).Err()
+			Expect(err).To(HaveOccurred())
+			Expect(client.Exists(ctx, "missing").Val()).To(BeZero())
+
+			err = client.JSONPatch(ctx, "missing", []redis.PatchOp{
+				{Op: "add", Path: "", Value: map[string]int{"a": 1}},
+				{Op: "add", Path: "/b", Value: 2},
+			}).Err()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(client.JSONGet(ctx, "missing").Val()).To(MatchJSON(`{"a": 1, "b": 2}`))
+		})
+
+		It("should apply the patch of JSONDiff", Label("json.patch", "json"), func() {
+			from := map[string]interface{}{}
+			Expect(json.Unmarshal([]byte(doc), &from)).NotTo(HaveOccurred())
+			to := map[string]interface{}{
+				"name": "ann",
+				"tags": []string{"b"},
+				"addr": map[string]interface{}{"city": "Lyon", "zip": "69001"},
+			}
+
+			patch, err := redis.JSONDiff(from, to)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(client.JSONPatch(ctx, "doc", patch).Err()).NotTo(HaveOccurred())
+			Expect(client.JSONGet(ctx, "doc").Val()).To(MatchJSON(`{
+				"name": "ann",
+				"tags": ["b"],
+				"addr": {"city": "Lyon", "zip": "69001"}
+			}`))
+		})
+	})
 })
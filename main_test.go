@@ -15,6 +15,7 @@ import (
 	. "github.com/bsm/gomega"
 
 	"github.com/redis/go-redis/v9"
+	"github.com/redis/go-redis/v9/redistest"
 )
 
 const (
@@ -67,6 +68,21 @@ var cluster = &clusterScenario{
 
 var RECluster = false
 
+// hermetic is set when neither REDIS_PORT nor the redis-server binary built by
+// `make testdeps` are available: the specs of the files in hermeticFiles run
+// against in-memory redistest servers instead, except the ones labeled
+// NonRedistest, e.g. because they use scripting or restart the servers.
+var (
+	hermetic      = os.Getenv("REDIS_PORT") == "" && !fileExists(redisServerBin)
+	hermeticFiles = []string{
+		"redis_test.go",
+		"ring_test.go",
+		"pipeline_test.go",
+		"tx_test.go",
+	}
+	testServers []*redistest.Server
+)
+
 func registerProcess(port string, p *redisProcess) {
 	if processes == nil {
 		processes = make(map[string]*redisProcess)
@@ -83,7 +99,11 @@ var _ = BeforeSuite(func() {
 	var err error
 	RECluster, _ = strconv.ParseBool(os.Getenv("RE_CLUSTER"))
 
-	if !RECluster {
+	if hermetic {
+		for _, port := range []string{redisPort, ringShard1Port, ringShard2Port, ringShard3Port} {
+			Expect(startTestServer(port)).NotTo(HaveOccurred())
+		}
+	} else if !RECluster {
 
 		redisMain, err = startRedis(redisPort)
 		Expect(err).NotTo(HaveOccurred())
@@ -125,7 +145,12 @@ var _ = BeforeSuite(func() {
 })
 
 var _ = AfterSuite(func() {
-	if !RECluster {
+	for _, srv := range testServers {
+		Expect(srv.Close()).NotTo(HaveOccurred())
+	}
+	testServers = nil
+
+	if !hermetic && !RECluster {
 		Expect(cluster.Close()).NotTo(HaveOccurred())
 
 		for _, p := range processes {
@@ -137,7 +162,17 @@ var _ = AfterSuite(func() {
 
 func TestGinkgoSuite(t *testing.T) {
 	RegisterFailHandler(Fail)
-	RunSpecs(t, "go-redis")
+
+	suiteConfig, reporterConfig := GinkgoConfiguration()
+	if hermetic {
+		suiteConfig.FocusFiles = append(suiteConfig.FocusFiles, hermeticFiles...)
+		if suiteConfig.LabelFilter != "" {
+			suiteConfig.LabelFilter = "(" + suiteConfig.LabelFilter + ") && !NonRedistest"
+		} else {
+			suiteConfig.LabelFilter = "!NonRedistest"
+		}
+	}
+	RunSpecs(t, "go-redis", suiteConfig, reporterConfig)
 }
 
 //------------------------------------------------------------------------------
@@ -367,6 +402,25 @@ func startRedis(port string, args ...string) (*redisProcess, error) {
 	return p, nil
 }
 
+// startTestServer starts an in-memory server listening on the port.
+func startTestServer(port string) error {
+	ln, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		return err
+	}
+	srv := redistest.NewServer()
+	go func() {
+		_ = srv.Serve(ln)
+	}()
+	testServers = append(testServers, srv)
+	return nil
+}
+
+func fileExists(name string) bool {
+	_, err := os.Stat(name)
+	return err == nil
+}
+
 func startSentinel(port, masterName, masterPort string) (*redisProcess, error) {
 	dir, err := redisDir(port)
 	if err != nil {
@@ -147,6 +147,50 @@ type Options struct {
 
 	// Add suffix to client name. Default is empty.
 	IdentitySuffix string
+
+	// MultiplexConns enables multiplexing when greater than 0: the commands
+	// of all goroutines are pipelined on MultiplexConns shared connections
+	// and the replies are matched in order. Blocking commands, transactions,
+	// Conn and PubSub still use dedicated connections limited by PoolSize.
+	// A network error or a timeout fails all the commands waiting for
+	// a reply on the same connection, so commands fail if ContextTimeoutEnabled
+	// is set too. Shared connections are replaced once they reach ConnMaxLifetime
+	// or ConnMaxIdleTime.
+	// Default is 0, every command uses its own connection.
+	MultiplexConns int
+
+	// ClientCache enables client-side caching of the replies of read-only commands
+	// like GET, HGETALL and JSON.GET, using CLIENT TRACKING to drop the replies
+	// of modified keys. Pipelines, transactions and commands sent through Conn
+	// are never cached. The cache is disabled with a logged warning if the server
+	// rejects CLIENT TRACKING.
+	// Default is nil, caching is disabled.
+	ClientCache *ClientCacheOptions
+
+	// AutoPipeline enables automatic pipelining: commands processed concurrently
+	// are collected and sent together in a single pipeline on one connection.
+	// Blocking commands, pipelines and transactions are never batched.
+	// A command returns as soon as its context is done; a batch is sent
+	// with the earliest deadline of the contexts of its commands.
+	// Default is false.
+	AutoPipeline bool
+	// AutoPipelineWindow is the amount of time to wait for more commands
+	// before a batch is sent.
+	// Default is 100 microseconds.
+	AutoPipelineWindow time.Duration
+	// AutoPipelineMaxBatch is the maximum number of commands in a batch.
+	// A full batch is sent without waiting for AutoPipelineWindow.
+	// Default is 100.
+	AutoPipelineMaxBatch int
+
+	// JSONCodec marshals the values of the JSON commands and unmarshals
+	// the documents returned by JSONCmd and JSONGetInto.
+	// Default is encoding/json.
+	JSONCodec JSONCodec
+
+	// JSONValidator checks the values of JSON.SET, JSON.MSET and JSON.MERGE
+	// against the JSON Schema registered for their key before they are sent.
+	JSONValidator *JSONValidator
 }
 
 func (opt *Options) init() {
@@ -213,6 +257,12 @@ func (opt *Options) init() {
 	case 0:
 		opt.MaxRetryBackoff = 512 * time.Millisecond
 	}
+	if opt.AutoPipelineWindow == 0 {
+		opt.AutoPipelineWindow = 100 * time.Microsecond
+	}
+	if opt.AutoPipelineMaxBatch == 0 {
+		opt.AutoPipelineMaxBatch = 100
+	}
 }
 
 func (opt *Options) clone() *Options {
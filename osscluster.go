@@ -89,6 +89,20 @@ type ClusterOptions struct {
 	DisableIndentity bool // Disable set-lib on connect. Default is false.
 
 	IdentitySuffix string // Add suffix to client name. Default is empty.
+
+	// MultiplexConns applies per cluster node, see Options.MultiplexConns.
+	MultiplexConns int
+
+	// AutoPipeline batches concurrent commands per cluster node.
+	// See Options.AutoPipeline.
+	AutoPipeline         bool
+	AutoPipelineWindow   time.Duration
+	AutoPipelineMaxBatch int
+
+	// JSONCodec is used by the cluster nodes, see Options.JSONCodec.
+	JSONCodec JSONCodec
+	// JSONValidator is used by the cluster nodes, see Options.JSONValidator.
+	JSONValidator *JSONValidator
 }
 
 func (opt *ClusterOptions) init() {
@@ -297,6 +311,15 @@ func (opt *ClusterOptions) clientOptions() *Options {
 		DisableIndentity: opt.DisableIndentity,
 		IdentitySuffix:   opt.IdentitySuffix,
 		TLSConfig:        opt.TLSConfig,
+
+		MultiplexConns: opt.MultiplexConns,
+
+		AutoPipeline:         opt.AutoPipeline,
+		AutoPipelineWindow:   opt.AutoPipelineWindow,
+		AutoPipelineMaxBatch: opt.AutoPipelineMaxBatch,
+
+		JSONCodec:     opt.JSONCodec,
+		JSONValidator: opt.JSONValidator,
 		// If ClusterSlots is populated, then we probably have an artificial
 		// cluster whose nodes are not in clustering mode (otherwise there isn't
 		// much use for ClusterSlots config).  This means we cannot execute the
@@ -851,6 +874,7 @@ type ClusterClient struct {
 	nodes         *clusterNodes
 	state         *clusterStateHolder
 	cmdsInfoCache *cmdsInfoCache
+	push          *pushHandlers
 	cmdable
 	hooksMixin
 }
@@ -863,7 +887,9 @@ func NewClusterClient(opt *ClusterOptions) *ClusterClient {
 	c := &ClusterClient{
 		opt:   opt,
 		nodes: newClusterNodes(opt),
+		push:  new(pushHandlers),
 	}
+	c.nodes.OnNewNode(c.push.attach)
 
 	c.state = newClusterStateHolder(c.loadState)
 	c.cmdsInfoCache = newCmdsInfoCache(c.cmdsInfo)
@@ -1214,6 +1240,11 @@ func (c *ClusterClient) Pipelined(ctx context.Context, fn func(Pipeliner) error)
 }
 
 func (c *ClusterClient) processPipeline(ctx context.Context, cmds []Cmder) error {
+	if err := prepareJSONCmds(c.opt.JSONCodec, c.opt.JSONValidator, cmds...); err != nil {
+		setCmdsErr(cmds, err)
+		return err
+	}
+
 	cmdsMap := newCmdsMap()
 
 	if err := c.mapCmdsByNode(ctx, cmdsMap, cmds); err != nil {
@@ -1340,7 +1371,7 @@ func (c *ClusterClient) pipelineReadCmds(
 	failedCmds *cmdsMap,
 ) error {
 	for i, cmd := range cmds {
-		err := cmd.readReply(rd)
+		err := readCmdReply(rd, cmd)
 		cmd.SetErr(err)
 
 		if err == nil {
@@ -1419,6 +1450,11 @@ func (c *ClusterClient) processTxPipeline(ctx context.Context, cmds []Cmder) err
 	// Trim multi .. exec.
 	cmds = cmds[1 : len(cmds)-1]
 
+	if err := prepareJSONCmds(c.opt.JSONCodec, c.opt.JSONValidator, cmds...); err != nil {
+		setCmdsErr(cmds, err)
+		return err
+	}
+
 	state, err := c.state.Get(ctx)
 	if err != nil {
 		setCmdsErr(cmds, err)
@@ -4,6 +4,8 @@ import (
 	"context"
 	"sync"
 	"sync/atomic"
+
+	"github.com/redis/go-redis/v9/internal/hashtag"
 )
 
 func (c *ClusterClient) DBSize(ctx context.Context) *IntCmd {
@@ -107,3 +109,17 @@ func (c *ClusterClient) ScriptExists(ctx context.Context, hashes ...string) *Boo
 	})
 	return cmd
 }
+
+// FTAggregateIterator runs FT.AGGREGATE with WITHCURSOR on a node and keeps
+// reading the cursor from that node, since cursors are local to a node.
+func (c *ClusterClient) FTAggregateIterator(
+	ctx context.Context, index string, query string, options *FTAggregateOptions,
+) *AggregateIterator {
+	node, err := c.cmdNode(ctx, "ft.aggregate", hashtag.RandomSlot())
+	if err != nil {
+		cmd := NewFTAggregateCmd(ctx, "FT.AGGREGATE", index, query)
+		cmd.SetErr(err)
+		return &AggregateIterator{cmd: cmd}
+	}
+	return newAggregateIterator(ctx, node.Client.Process, index, query, options)
+}
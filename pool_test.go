@@ -128,3 +128,74 @@ var _ = Describe("pool", func() {
 		Expect(stats.Timeouts).To(Equal(uint32(0)))
 	})
 })
+
+var _ = Describe("multiplexed pool", func() {
+	var client *redis.Client
+
+	BeforeEach(func() {
+		opt := redisOptions()
+		opt.MultiplexConns = 2
+		opt.ContextTimeoutEnabled = false
+		client = redis.NewClient(opt)
+		Expect(client.FlushDB(ctx).Err()).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(client.Close()).NotTo(HaveOccurred())
+	})
+
+	It("shares connections", func() {
+		perform(1000, func(id int) {
+			Expect(client.Incr(ctx, "counter").Err()).NotTo(HaveOccurred())
+		})
+		Expect(client.Get(ctx, "counter").Val()).To(Equal("1000"))
+		Expect(client.Pool().Len()).To(Equal(2))
+	})
+
+	It("uses dedicated connections for blocking commands", func() {
+		done := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			defer close(done)
+
+			val, err := client.BLPop(ctx, 5*time.Second, "list").Result()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(val).To(Equal([]string{"list", "a"}))
+		}()
+
+		// The shared connections are not held up by BLPOP.
+		Eventually(func() int {
+			return client.Pool().Len()
+		}).Should(Equal(2))
+		Expect(client.Ping(ctx).Err()).NotTo(HaveOccurred())
+		Expect(client.Pool().Len()).To(Equal(3))
+		Expect(client.RPush(ctx, "list", "a").Err()).NotTo(HaveOccurred())
+		Eventually(done).Should(BeClosed())
+	})
+
+	It("uses dedicated connections for transactions", func() {
+		err := client.Watch(ctx, func(tx *redis.Tx) error {
+			n, err := tx.Get(ctx, "key").Int()
+			if err != nil && err != redis.Nil {
+				return err
+			}
+			_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+				pipe.Set(ctx, "key", n+1, 0)
+				return nil
+			})
+			return err
+		}, "key")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(client.Get(ctx, "key").Val()).To(Equal("1"))
+	})
+	It("rejects ContextTimeoutEnabled", func() {
+		opt := redisOptions()
+		opt.MultiplexConns = 2
+		opt.ContextTimeoutEnabled = true
+		client := redis.NewClient(opt)
+		defer client.Close()
+
+		err := client.Ping(ctx).Err()
+		Expect(err).To(MatchError("redis: ContextTimeoutEnabled can't be used with MultiplexConns"))
+	})
+})
@@ -0,0 +1,91 @@
+package redis
+
+import (
+	"sync"
+)
+
+// PushHandler handles a RESP3 push message received out-of-band on a pooled connection.
+// Kind is the first element of the push message, e.g. "invalidate", and payload holds the rest.
+// It is called by the goroutine reading the reply of a command, so it must not block.
+type PushHandler func(kind string, payload []interface{})
+
+// pushHandlers dispatches push messages to the handler registered for their kind.
+// Kinds without a handler are looked up in the fallback, e.g. the handlers of
+// the ClusterClient or Ring owning the node client.
+type pushHandlers struct {
+	mu       sync.RWMutex
+	handlers map[string]PushHandler
+	fallback *pushHandlers
+}
+
+func (hs *pushHandlers) register(kind string, handler PushHandler) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+
+	if handler == nil {
+		delete(hs.handlers, kind)
+		return
+	}
+	if hs.handlers == nil {
+		hs.handlers = make(map[string]PushHandler)
+	}
+	hs.handlers[kind] = handler
+}
+
+// attach makes the node client rdb fall back to the handlers hs.
+func (hs *pushHandlers) attach(rdb *Client) {
+	if rdb.push == nil {
+		return
+	}
+	rdb.push.mu.Lock()
+	rdb.push.fallback = hs
+	rdb.push.mu.Unlock()
+}
+
+func (hs *pushHandlers) handler(kind string) PushHandler {
+	hs.mu.RLock()
+	defer hs.mu.RUnlock()
+
+	if handler := hs.handlers[kind]; handler != nil {
+		return handler
+	}
+	if hs.fallback != nil {
+		return hs.fallback.handler(kind)
+	}
+	return nil
+}
+
+// dispatch calls the handler of the push message. Push messages without a handler,
+// e.g. keyspace notifications nobody listens to, are dropped silently.
+func (hs *pushHandlers) dispatch(kind string, payload []interface{}) {
+	if handler := hs.handler(kind); handler != nil {
+		handler(kind, payload)
+	}
+}
+
+// RegisterPushHandler registers the handler of the RESP3 push messages of the kind,
+// replacing the previous one. A nil handler unregisters it.
+//
+// Push messages received on pooled connections, e.g. client tracking invalidations
+// or messages emitted by modules, are dispatched to the handlers before the reply of the
+// pending command is read. Push messages without a handler are dropped.
+// Messages received by PubSub are not dispatched, use PubSub.Channel to receive them.
+func (c *Client) RegisterPushHandler(kind string, handler PushHandler) {
+	c.push.register(kind, handler)
+}
+
+// RegisterPushHandler registers the handler of the RESP3 push messages of the kind
+// received on the connections of every node, replacing the previous one.
+// A nil handler unregisters it. Handlers registered on a node client
+// returned by OnNewNode or ForEachShard take precedence.
+func (c *ClusterClient) RegisterPushHandler(kind string, handler PushHandler) {
+	c.push.register(kind, handler)
+}
+
+// RegisterPushHandler registers the handler of the RESP3 push messages of the kind
+// received on the connections of every shard, replacing the previous one.
+// A nil handler unregisters it. Handlers registered on a shard client
+// returned by OnNewNode or ForEachShard take precedence.
+func (c *Ring) RegisterPushHandler(kind string, handler PushHandler) {
+	c.push.register(kind, handler)
+}
@@ -0,0 +1,96 @@
+package redis_test
+
+import (
+	"context"
+	"sync"
+
+	. "github.com/bsm/ginkgo/v2"
+	. "github.com/bsm/gomega"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var _ = Describe("push messages", func() {
+	var client *redis.Client
+
+	BeforeEach(func() {
+		opt := redisOptions()
+		opt.Protocol = 3
+		client = redis.NewClient(opt)
+		Expect(client.FlushDB(ctx).Err()).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(client.Close()).NotTo(HaveOccurred())
+	})
+
+	It("dispatches push messages received before a reply", func() {
+		var mu sync.Mutex
+		var invalidated []interface{}
+		client.RegisterPushHandler("invalidate", func(kind string, payload []interface{}) {
+			mu.Lock()
+			defer mu.Unlock()
+			invalidated = append(invalidated, payload...)
+		})
+
+		conn := client.Conn()
+		defer conn.Close()
+
+		Expect(conn.Process(ctx, redis.NewStatusCmd(ctx, "client", "tracking", "on"))).NotTo(HaveOccurred())
+		Expect(conn.Get(ctx, "key").Err()).To(Equal(redis.Nil))
+		Expect(client.Set(ctx, "key", "value", 0).Err()).NotTo(HaveOccurred())
+
+		Expect(conn.Ping(ctx).Val()).To(Equal("PONG"))
+		Expect(conn.Get(ctx, "key").Val()).To(Equal("value"))
+
+		mu.Lock()
+		defer mu.Unlock()
+		Expect(invalidated).To(Equal([]interface{}{[]interface{}{"key"}}))
+	})
+
+	It("drops push messages without a handler", func() {
+		conn := client.Conn()
+		defer conn.Close()
+
+		Expect(conn.Process(ctx, redis.NewStatusCmd(ctx, "client", "tracking", "on"))).NotTo(HaveOccurred())
+		Expect(conn.Get(ctx, "key").Err()).To(Equal(redis.Nil))
+		Expect(client.Set(ctx, "key", "value", 0).Err()).NotTo(HaveOccurred())
+
+		Expect(conn.Ping(ctx).Val()).To(Equal("PONG"))
+	})
+	It("dispatches push messages of Ring shards to the Ring handlers", func() {
+		opt := redisRingOptions()
+		opt.Protocol = 3
+		ring := redis.NewRing(opt)
+		defer ring.Close()
+
+		var mu sync.Mutex
+		var invalidated []interface{}
+		ring.RegisterPushHandler("invalidate", func(kind string, payload []interface{}) {
+			mu.Lock()
+			defer mu.Unlock()
+			invalidated = append(invalidated, payload...)
+		})
+
+		err := ring.ForEachShard(ctx, func(ctx context.Context, shard *redis.Client) error {
+			conn := shard.Conn()
+			defer conn.Close()
+
+			if err := conn.Process(ctx, redis.NewStatusCmd(ctx, "client", "tracking", "on")); err != nil {
+				return err
+			}
+			if err := conn.Get(ctx, "push-key").Err(); err != nil && err != redis.Nil {
+				return err
+			}
+			if err := shard.Set(ctx, "push-key", "value", 0).Err(); err != nil {
+				return err
+			}
+			return conn.Ping(ctx).Err()
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		mu.Lock()
+		defer mu.Unlock()
+		Expect(invalidated).To(HaveLen(2))
+	})
+})
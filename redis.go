@@ -198,6 +198,10 @@ func (hs *hooksMixin) processTxPipelineHook(ctx context.Context, cmds []Cmder) e
 type baseClient struct {
 	opt      *Options
 	connPool pool.Pooler
+	push     *pushHandlers
+	cache    *clientCache
+
+	autoPipeline *autoPipeliner
 
 	onClose func() error // hook called when client is closed
 }
@@ -214,10 +218,22 @@ func (c *baseClient) withTimeout(timeout time.Duration) *baseClient {
 
 	clone := c.clone()
 	clone.opt = opt
+	if c.autoPipeline != nil {
+		clone.autoPipeline = newAutoPipeliner(clone)
+	}
 
 	return clone
 }
 
+// dedicatedConnPool returns the pool of the connections
+// that are not shared with other goroutines.
+func (c *baseClient) dedicatedConnPool() pool.Pooler {
+	if p, ok := c.connPool.(*pool.MuxConnPool); ok {
+		return p.Dedicated()
+	}
+	return c.connPool
+}
+
 func (c *baseClient) String() string {
 	return fmt.Sprintf("Redis<%s db:%d>", c.getAddr(), c.opt.DB)
 }
@@ -237,7 +253,14 @@ func (c *baseClient) newConn(ctx context.Context) (*pool.Conn, error) {
 	return cn, nil
 }
 
+// errMultiplexContextTimeout is returned when the deadline of the context of a caller
+// would break the connections shared with the other callers.
+var errMultiplexContextTimeout = errors.New("redis: ContextTimeoutEnabled can't be used with MultiplexConns")
+
 func (c *baseClient) getConn(ctx context.Context) (*pool.Conn, error) {
+	if c.opt.ContextTimeoutEnabled && c.opt.MultiplexConns > 0 {
+		return nil, errMultiplexContextTimeout
+	}
 	if c.opt.Limiter != nil {
 		err := c.opt.Limiter.Allow()
 		if err != nil {
@@ -262,16 +285,24 @@ func (c *baseClient) _getConn(ctx context.Context) (*pool.Conn, error) {
 		return nil, err
 	}
 
-	if cn.Inited {
-		return cn, nil
+	if !cn.Inited {
+		if c.push != nil {
+			cn.SetPushHandler(c.push.dispatch)
+		}
+		if err := c.initConn(ctx, cn); err != nil {
+			c.connPool.Remove(ctx, cn, err)
+			if err := errors.Unwrap(err); err != nil {
+				return nil, err
+			}
+			return nil, err
+		}
 	}
 
-	if err := c.initConn(ctx, cn); err != nil {
-		c.connPool.Remove(ctx, cn, err)
-		if err := errors.Unwrap(err); err != nil {
+	if c.cache != nil {
+		if err := c.cache.track(ctx, c, cn); err != nil {
+			c.connPool.Remove(ctx, cn, err)
 			return nil, err
 		}
-		return nil, err
 	}
 
 	return cn, nil
@@ -393,6 +424,31 @@ func (c *baseClient) dial(ctx context.Context, network, addr string) (net.Conn,
 }
 
 func (c *baseClient) process(ctx context.Context, cmd Cmder) error {
+	if err := prepareJSONCmds(c.opt.JSONCodec, c.opt.JSONValidator, cmd); err != nil {
+		return err
+	}
+
+	if c.cache != nil {
+		if ok, err := c.cache.load(ctx, cmd); ok {
+			return err
+		}
+	}
+
+	if cmd.readTimeout() != nil {
+		if p, ok := c.connPool.(*pool.MuxConnPool); ok {
+			// Blocking commands would hold up the replies on the shared connections.
+			dedicated := c.clone()
+			dedicated.connPool = p.Dedicated()
+			return dedicated.process(ctx, cmd)
+		}
+	}
+
+	// Blocking commands would hold up the whole batch,
+	// and streamed replies would be buffered by it.
+	if c.autoPipeline != nil && cmd.readTimeout() == nil && !isWriterCmd(cmd) {
+		return c.autoPipeline.process(ctx, cmd)
+	}
+
 	var lastErr error
 	for attempt := 0; attempt <= c.opt.MaxRetries; attempt++ {
 		attempt := attempt
@@ -416,6 +472,17 @@ func (c *baseClient) _process(ctx context.Context, cmd Cmder, attempt int) (bool
 
 	retryTimeout := uint32(0)
 	if err := c.withConn(ctx, func(ctx context.Context, cn *pool.Conn) error {
+		readReply := func(rd *proto.Reader) error {
+			return readCmdReply(rd, cmd)
+		}
+		if c.cache != nil {
+			if e := c.cache.reserve(ctx, cn, cmd); e != nil {
+				readReply = func(rd *proto.Reader) error {
+					return c.cache.readReply(rd, e, cmd)
+				}
+			}
+		}
+
 		if err := cn.WithWriter(c.context(ctx), c.opt.WriteTimeout, func(wr *proto.Writer) error {
 			return writeCmd(wr, cmd)
 		}); err != nil {
@@ -423,7 +490,7 @@ func (c *baseClient) _process(ctx context.Context, cmd Cmder, attempt int) (bool
 			return err
 		}
 
-		if err := cn.WithReader(c.context(ctx), c.cmdTimeout(cmd), cmd.readReply); err != nil {
+		if err := cn.WithReader(c.context(ctx), c.cmdTimeout(cmd), readReply); err != nil {
 			if cmd.readTimeout() == nil {
 				atomic.StoreUint32(&retryTimeout, 1)
 			} else {
@@ -478,6 +545,10 @@ func (c *baseClient) getAddr() string {
 }
 
 func (c *baseClient) processPipeline(ctx context.Context, cmds []Cmder) error {
+	if err := prepareJSONCmds(c.opt.JSONCodec, c.opt.JSONValidator, cmds...); err != nil {
+		setCmdsErr(cmds, err)
+		return err
+	}
 	if err := c.generalProcessPipeline(ctx, cmds, c.pipelineProcessCmds); err != nil {
 		return err
 	}
@@ -485,6 +556,10 @@ func (c *baseClient) processPipeline(ctx context.Context, cmds []Cmder) error {
 }
 
 func (c *baseClient) processTxPipeline(ctx context.Context, cmds []Cmder) error {
+	if err := prepareJSONCmds(c.opt.JSONCodec, c.opt.JSONValidator, cmds...); err != nil {
+		setCmdsErr(cmds, err)
+		return err
+	}
 	if err := c.generalProcessPipeline(ctx, cmds, c.txPipelineProcessCmds); err != nil {
 		return err
 	}
@@ -540,7 +615,7 @@ func (c *baseClient) pipelineProcessCmds(
 
 func pipelineReadCmds(rd *proto.Reader, cmds []Cmder) error {
 	for i, cmd := range cmds {
-		err := cmd.readReply(rd)
+		err := readCmdReply(rd, cmd)
 		cmd.SetErr(err)
 		if err != nil && !isRedisError(err) {
 			setCmdsErr(cmds[i+1:], err)
@@ -638,12 +713,27 @@ func NewClient(opt *Options) *Client {
 		},
 	}
 	c.init()
-	c.connPool = newConnPool(opt, c.dialHook)
+	connPool := newConnPool(opt, c.dialHook)
+	c.connPool = connPool
+	if opt.MultiplexConns > 0 {
+		c.connPool = pool.NewMuxConnPool(connPool, opt.MultiplexConns)
+	}
+
+	if opt.ClientCache != nil {
+		c.cache = newClientCache(opt.ClientCache, c.baseClient)
+		c.onClose = c.cache.Close
+	}
+	if opt.AutoPipeline {
+		c.autoPipeline = newAutoPipeliner(c.baseClient)
+	}
 
 	return &c
 }
 
 func (c *Client) init() {
+	if c.push == nil {
+		c.push = new(pushHandlers)
+	}
 	c.cmdable = c.Process
 	c.initHooks(hooks{
 		dial:       c.baseClient.dial,
@@ -661,7 +751,10 @@ func (c *Client) WithTimeout(timeout time.Duration) *Client {
 }
 
 func (c *Client) Conn() *Conn {
-	return newConn(c.opt, pool.NewStickyConnPool(c.connPool))
+	conn := newConn(c.opt, pool.NewStickyConnPool(c.dedicatedConnPool()))
+	conn.push = c.push
+	// The cache is not shared: SELECT would change the database of the connection.
+	return conn
 }
 
 // Do create a Cmd from the args and processes the cmd.
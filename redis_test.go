@@ -507,7 +507,7 @@ var _ = Describe("Conn", func() {
 		Expect(err).NotTo(HaveOccurred())
 	})
 
-	It("TxPipeline", Label("NonRedisEnterprise"), func() {
+	It("TxPipeline", Label("NonRedisEnterprise", "NonRedistest"), func() {
 		tx := client.Conn().TxPipeline()
 		tx.SwapDB(ctx, 0, 2)
 		tx.SwapDB(ctx, 1, 0)
@@ -563,7 +563,7 @@ var _ = Describe("Hook", func() {
 		}))
 	})
 
-	It("wrapped error in a hook", func() {
+	It("wrapped error in a hook", Label("NonRedistest"), func() {
 		client.AddHook(&hook{
 			processHook: func(hook redis.ProcessHook) redis.ProcessHook {
 				return func(ctx context.Context, cmd redis.Cmder) error {
@@ -0,0 +1,410 @@
+package redistest
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	msgSyntax     = "ERR syntax error"
+	msgNotInteger = "ERR value is not an integer or out of range"
+	msgNotFloat   = "ERR value is not a valid float"
+)
+
+const (
+	// flagNoQueue commands are run right away inside MULTI.
+	flagNoQueue = 1 << iota
+	// flagPubSub commands are allowed in the RESP2 pub/sub context.
+	flagPubSub
+)
+
+type command struct {
+	fn func(c *client, args []string)
+	// arity is the exact number of arguments, including the command name,
+	// or the minimum number of arguments if negative.
+	arity int
+	flags int
+}
+
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		// Connection and server.
+		"auth":     {fn: authCmd, arity: -2, flags: flagNoQueue},
+		"client":   {fn: clientCmd, arity: -2},
+		"dbsize":   {fn: dbsizeCmd, arity: 1},
+		"echo":     {fn: echoCmd, arity: 2},
+		"flushall": {fn: flushallCmd, arity: -1},
+		"flushdb":  {fn: flushdbCmd, arity: -1},
+		"hello":    {fn: helloCmd, arity: -1, flags: flagNoQueue},
+		"info":     {fn: infoCmd, arity: -1},
+		"ping":     {fn: pingCmd, arity: -1, flags: flagPubSub},
+		"quit":     {fn: quitCmd, arity: 1, flags: flagNoQueue | flagPubSub},
+		"select":   {fn: selectCmd, arity: 2},
+		"time":     {fn: timeCmd, arity: 1},
+
+		// Keys.
+		"del":       {fn: delCmd, arity: -2},
+		"exists":    {fn: existsCmd, arity: -2},
+		"expire":    {fn: expireCmd(time.Second, false), arity: -3},
+		"expireat":  {fn: expireCmd(time.Second, true), arity: -3},
+		"keys":      {fn: keysCmd, arity: 2},
+		"persist":   {fn: persistCmd, arity: 2},
+		"pexpire":   {fn: expireCmd(time.Millisecond, false), arity: -3},
+		"pexpireat": {fn: expireCmd(time.Millisecond, true), arity: -3},
+		"pttl":      {fn: ttlCmd(time.Millisecond), arity: 2},
+		"rename":    {fn: renameCmd(false), arity: 3},
+		"renamenx":  {fn: renameCmd(true), arity: 3},
+		"scan":      {fn: scanCmd, arity: -2},
+		"ttl":       {fn: ttlCmd(time.Second), arity: 2},
+		"type":      {fn: typeCmd, arity: 2},
+		"unlink":    {fn: delCmd, arity: -2},
+
+		// Strings.
+		"append":      {fn: appendCmd, arity: 3},
+		"decr":        {fn: incrCmd(-1, false), arity: 2},
+		"decrby":      {fn: incrCmd(-1, true), arity: 3},
+		"get":         {fn: getCmd, arity: 2},
+		"getdel":      {fn: getdelCmd, arity: 2},
+		"getrange":    {fn: getrangeCmd, arity: 4},
+		"getset":      {fn: getsetCmd, arity: 3},
+		"incr":        {fn: incrCmd(1, false), arity: 2},
+		"incrby":      {fn: incrCmd(1, true), arity: 3},
+		"incrbyfloat": {fn: incrbyfloatCmd, arity: 3},
+		"mget":        {fn: mgetCmd, arity: -2},
+		"mset":        {fn: msetCmd(false), arity: -3},
+		"msetnx":      {fn: msetCmd(true), arity: -3},
+		"psetex":      {fn: setexCmd(time.Millisecond), arity: 4},
+		"set":         {fn: setCmd, arity: -3},
+		"setex":       {fn: setexCmd(time.Second), arity: 4},
+		"setnx":       {fn: setnxCmd, arity: 3},
+		"strlen":      {fn: strlenCmd, arity: 2},
+
+		// Hashes.
+		"hdel":         {fn: hdelCmd, arity: -3},
+		"hexists":      {fn: hexistsCmd, arity: 3},
+		"hget":         {fn: hgetCmd, arity: 3},
+		"hgetall":      {fn: hgetallCmd, arity: 2},
+		"hincrby":      {fn: hincrbyCmd, arity: 4},
+		"hincrbyfloat": {fn: hincrbyfloatCmd, arity: 4},
+		"hkeys":        {fn: hkeysCmd, arity: 2},
+		"hlen":         {fn: hlenCmd, arity: 2},
+		"hmget":        {fn: hmgetCmd, arity: -3},
+		"hmset":        {fn: hsetCmd, arity: -4},
+		"hset":         {fn: hsetCmd, arity: -4},
+		"hsetnx":       {fn: hsetnxCmd, arity: 4},
+		"hstrlen":      {fn: hstrlenCmd, arity: 3},
+		"hvals":        {fn: hvalsCmd, arity: 2},
+
+		// Lists.
+		"blpop":     {fn: bpopCmd(true), arity: -3},
+		"brpop":     {fn: bpopCmd(false), arity: -3},
+		"lindex":    {fn: lindexCmd, arity: 3},
+		"linsert":   {fn: linsertCmd, arity: 5},
+		"llen":      {fn: llenCmd, arity: 2},
+		"lmove":     {fn: lmoveCmd, arity: 5},
+		"lpop":      {fn: popCmd(true), arity: -2},
+		"lpush":     {fn: pushCmd(true, false), arity: -3},
+		"lpushx":    {fn: pushCmd(true, true), arity: -3},
+		"lrange":    {fn: lrangeCmd, arity: 4},
+		"lrem":      {fn: lremCmd, arity: 4},
+		"lset":      {fn: lsetCmd, arity: 4},
+		"ltrim":     {fn: ltrimCmd, arity: 4},
+		"rpop":      {fn: popCmd(false), arity: -2},
+		"rpoplpush": {fn: rpoplpushCmd, arity: 3},
+		"rpush":     {fn: pushCmd(false, false), arity: -3},
+		"rpushx":    {fn: pushCmd(false, true), arity: -3},
+
+		// Sets.
+		"sadd":        {fn: saddCmd, arity: -3},
+		"scard":       {fn: scardCmd, arity: 2},
+		"sdiff":       {fn: setOpCmd(setDiff, false), arity: -2},
+		"sdiffstore":  {fn: setOpCmd(setDiff, true), arity: -3},
+		"sinter":      {fn: setOpCmd(setInter, false), arity: -2},
+		"sinterstore": {fn: setOpCmd(setInter, true), arity: -3},
+		"sismember":   {fn: sismemberCmd, arity: 3},
+		"smembers":    {fn: smembersCmd, arity: 2},
+		"smismember":  {fn: smismemberCmd, arity: -3},
+		"spop":        {fn: spopCmd, arity: -2},
+		"srem":        {fn: sremCmd, arity: -3},
+		"sunion":      {fn: setOpCmd(setUnion, false), arity: -2},
+		"sunionstore": {fn: setOpCmd(setUnion, true), arity: -3},
+
+		// Sorted sets.
+		"zadd":             {fn: zaddCmd, arity: -4},
+		"zcard":            {fn: zcardCmd, arity: 2},
+		"zcount":           {fn: zcountCmd, arity: 4},
+		"zincrby":          {fn: zincrbyCmd, arity: 4},
+		"zmscore":          {fn: zmscoreCmd, arity: -3},
+		"zpopmax":          {fn: zpopCmd(true), arity: -2},
+		"zpopmin":          {fn: zpopCmd(false), arity: -2},
+		"zrange":           {fn: zrangeCmd, arity: -4},
+		"zrangebyscore":    {fn: zrangebyscoreCmd(false), arity: -4},
+		"zrank":            {fn: zrankCmd(false), arity: 3},
+		"zrem":             {fn: zremCmd, arity: -3},
+		"zremrangebyrank":  {fn: zremrangebyrankCmd, arity: 4},
+		"zremrangebyscore": {fn: zremrangebyscoreCmd, arity: 4},
+		"zrevrange":        {fn: zrevrangeCmd, arity: -4},
+		"zrevrangebyscore": {fn: zrangebyscoreCmd(true), arity: -4},
+		"zrevrank":         {fn: zrankCmd(true), arity: 3},
+		"zscore":           {fn: zscoreCmd, arity: 3},
+
+		// Transactions.
+		"discard": {fn: discardCmd, arity: 1, flags: flagNoQueue},
+		"exec":    {fn: execCmd, arity: 1, flags: flagNoQueue},
+		"multi":   {fn: multiCmd, arity: 1, flags: flagNoQueue},
+		"unwatch": {fn: unwatchCmd, arity: 1, flags: flagNoQueue},
+		"watch":   {fn: watchCmd, arity: -2, flags: flagNoQueue},
+
+		// Pub/sub.
+		"psubscribe":   {fn: subscribeCmd(true), arity: -2, flags: flagPubSub},
+		"publish":      {fn: publishCmd, arity: 3},
+		"pubsub":       {fn: pubsubCmd, arity: -2},
+		"punsubscribe": {fn: unsubscribeCmd(true), arity: -1, flags: flagPubSub},
+		"subscribe":    {fn: subscribeCmd(false), arity: -2, flags: flagPubSub},
+		"unsubscribe":  {fn: unsubscribeCmd(false), arity: -1, flags: flagPubSub},
+	}
+}
+
+//------------------------------------------------------------------------------
+
+func authCmd(c *client, args []string) {
+	if len(args) > 3 {
+		c.w.error(msgSyntax)
+		return
+	}
+	c.w.ok()
+}
+
+func helloCmd(c *client, args []string) {
+	protover := 2
+	if c.w.resp3 {
+		protover = 3
+	}
+
+	if len(args) > 1 {
+		v, err := strconv.Atoi(args[1])
+		if err != nil {
+			c.w.error("ERR Protocol version is not an integer or out of range")
+			return
+		}
+		if v != 2 && v != 3 {
+			c.w.error("NOPROTO unsupported protocol version")
+			return
+		}
+		protover = v
+	}
+
+	for i := 2; i < len(args); i++ {
+		switch strings.ToLower(args[i]) {
+		case "auth":
+			if i+2 >= len(args) {
+				c.w.error(msgSyntax)
+				return
+			}
+			i += 2
+		case "setname":
+			if i+1 >= len(args) {
+				c.w.error(msgSyntax)
+				return
+			}
+			i++
+			c.name = args[i]
+		default:
+			c.w.errorf("ERR Syntax error in HELLO option '%s'", args[i])
+			return
+		}
+	}
+
+	c.w.resp3 = protover == 3
+
+	c.w.mapLen(7)
+	c.w.bulk("server")
+	c.w.bulk("redis")
+	c.w.bulk("version")
+	c.w.bulk("7.2.0")
+	c.w.bulk("proto")
+	c.w.int(int64(protover))
+	c.w.bulk("id")
+	c.w.int(c.id)
+	c.w.bulk("mode")
+	c.w.bulk("standalone")
+	c.w.bulk("role")
+	c.w.bulk("master")
+	c.w.bulk("modules")
+	c.w.array(0)
+}
+
+func clientCmd(c *client, args []string) {
+	switch sub := strings.ToLower(args[1]); sub {
+	case "id":
+		c.w.int(c.id)
+	case "getname":
+		if c.name == "" {
+			c.w.null()
+		} else {
+			c.w.bulk(c.name)
+		}
+	case "setname":
+		if len(args) != 3 {
+			c.w.errorf("ERR wrong number of arguments for 'client|%s' command", sub)
+			return
+		}
+		c.name = args[2]
+		c.w.ok()
+	case "setinfo":
+		if len(args) != 4 {
+			c.w.errorf("ERR wrong number of arguments for 'client|%s' command", sub)
+			return
+		}
+		c.w.ok()
+	case "list":
+		clients := make([]*client, 0, len(c.srv.clients))
+		for cl := range c.srv.clients {
+			clients = append(clients, cl)
+		}
+		sort.Slice(clients, func(i, j int) bool {
+			return clients[i].id < clients[j].id
+		})
+
+		var b strings.Builder
+		for _, cl := range clients {
+			fmt.Fprintf(&b, "id=%d addr=%s name=%s db=%d\n", cl.id, cl.netConn.RemoteAddr(), cl.name, cl.db)
+		}
+		c.w.bulk(b.String())
+	case "pause":
+		// Only the ALL mode is supported, WRITE pauses all the commands too.
+		if len(args) != 3 && len(args) != 4 {
+			c.w.errorf("ERR wrong number of arguments for 'client|%s' command", sub)
+			return
+		}
+		ms, ok := parseInt(args[2])
+		if !ok || ms < 0 {
+			c.w.error("ERR timeout is not an integer or out of range")
+			return
+		}
+		c.srv.pausedUntil = time.Now().Add(time.Duration(ms) * time.Millisecond)
+		c.w.ok()
+	case "unpause":
+		c.srv.pausedUntil = time.Time{}
+		c.w.ok()
+	default:
+		c.w.errorf("ERR unknown subcommand '%s'. Try CLIENT HELP.", args[1])
+	}
+}
+
+func dbsizeCmd(c *client, args []string) {
+	c.w.int(int64(len(c.currentDB().liveKeys())))
+}
+
+func echoCmd(c *client, args []string) {
+	c.w.bulk(args[1])
+}
+
+func flushallCmd(c *client, args []string) {
+	for _, d := range c.srv.dbs {
+		d.flush()
+	}
+	c.w.ok()
+}
+
+func flushdbCmd(c *client, args []string) {
+	c.currentDB().flush()
+	c.w.ok()
+}
+
+func infoCmd(c *client, args []string) {
+	c.w.bulk("# Server\r\nredis_version:7.2.0\r\nredis_mode:standalone\r\n")
+}
+
+func pingCmd(c *client, args []string) {
+	if len(args) > 2 {
+		c.w.error("ERR wrong number of arguments for 'ping' command")
+		return
+	}
+
+	if !c.w.resp3 && c.subscribed() {
+		c.w.array(2)
+		c.w.bulk("pong")
+		if len(args) == 2 {
+			c.w.bulk(args[1])
+		} else {
+			c.w.bulk("")
+		}
+		return
+	}
+
+	if len(args) == 2 {
+		c.w.bulk(args[1])
+	} else {
+		c.w.status("PONG")
+	}
+}
+
+func quitCmd(c *client, args []string) {
+	c.w.ok()
+}
+
+func selectCmd(c *client, args []string) {
+	n, err := strconv.Atoi(args[1])
+	if err != nil {
+		c.w.error(msgNotInteger)
+		return
+	}
+	if n < 0 || n >= numDBs {
+		c.w.error("ERR DB index is out of range")
+		return
+	}
+	c.db = n
+	c.w.ok()
+}
+
+func timeCmd(c *client, args []string) {
+	now := time.Now()
+	c.w.array(2)
+	c.w.bulk(strconv.FormatInt(now.Unix(), 10))
+	c.w.bulk(strconv.Itoa(now.Nanosecond() / 1000))
+}
+
+//------------------------------------------------------------------------------
+
+func parseInt(s string) (int64, bool) {
+	n, err := strconv.ParseInt(s, 10, 64)
+	return n, err == nil
+}
+
+func parseFloat(s string) (float64, bool) {
+	switch strings.ToLower(s) {
+	case "inf", "+inf":
+		s = "+Inf"
+	case "-inf":
+		s = "-Inf"
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	return f, err == nil
+}
+
+// normalizeRange converts the inclusive start and stop indexes, which may be
+// negative, to a slice range of a sequence of n elements.
+func normalizeRange(start, stop int64, n int) (int, int) {
+	if start < 0 {
+		start += int64(n)
+	}
+	if stop < 0 {
+		stop += int64(n)
+	}
+	if start < 0 {
+		start = 0
+	}
+	if stop >= int64(n) {
+		stop = int64(n) - 1
+	}
+	if start > stop || start >= int64(n) {
+		return 0, 0
+	}
+	return int(start), int(stop) + 1
+}
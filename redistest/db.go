@@ -0,0 +1,215 @@
+package redistest
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+var errWrongType = errors.New("WRONGTYPE Operation against a key holding the wrong kind of value")
+
+type entry struct {
+	// val is a string, a hash, a *list, a set or a *zset.
+	val      interface{}
+	expireAt time.Time
+}
+
+type (
+	hash map[string]string
+	set  map[string]struct{}
+	list struct {
+		items []string
+	}
+)
+
+type zset struct {
+	scores map[string]float64
+}
+
+type zmember struct {
+	member string
+	score  float64
+}
+
+// sorted returns the members ordered by score, then by member.
+func (z *zset) sorted() []zmember {
+	ms := make([]zmember, 0, len(z.scores))
+	for member, score := range z.scores {
+		ms = append(ms, zmember{member: member, score: score})
+	}
+	sort.Slice(ms, func(i, j int) bool {
+		if ms[i].score != ms[j].score {
+			return ms[i].score < ms[j].score
+		}
+		return ms[i].member < ms[j].member
+	})
+	return ms
+}
+
+//------------------------------------------------------------------------------
+
+type db struct {
+	srv      *Server
+	keys     map[string]*entry
+	versions map[string]uint64
+}
+
+func newDB(srv *Server) *db {
+	return &db{
+		srv:      srv,
+		keys:     make(map[string]*entry),
+		versions: make(map[string]uint64),
+	}
+}
+
+// lookup returns the entry of key, deleting it if it is expired.
+func (d *db) lookup(key string) *entry {
+	e, ok := d.keys[key]
+	if !ok {
+		return nil
+	}
+	if !e.expireAt.IsZero() && !time.Now().Before(e.expireAt) {
+		d.del(key)
+		return nil
+	}
+	return e
+}
+
+func (d *db) put(key string, val interface{}) *entry {
+	e := &entry{val: val}
+	d.keys[key] = e
+	d.touch(key)
+	return e
+}
+
+func (d *db) del(key string) bool {
+	if _, ok := d.keys[key]; !ok {
+		return false
+	}
+	delete(d.keys, key)
+	d.touch(key)
+	return true
+}
+
+// touch marks key as modified, failing the transactions watching it.
+func (d *db) touch(key string) {
+	d.versions[key] = d.srv.nextVersion()
+}
+
+// modified marks key as modified, deleting it if it is now an empty container.
+func (d *db) modified(key string) {
+	e, ok := d.keys[key]
+	if !ok {
+		return
+	}
+
+	var n int
+	switch v := e.val.(type) {
+	case string:
+		n = 1
+	case hash:
+		n = len(v)
+	case set:
+		n = len(v)
+	case *list:
+		n = len(v.items)
+	case *zset:
+		n = len(v.scores)
+	}
+	if n == 0 {
+		delete(d.keys, key)
+	}
+	d.touch(key)
+}
+
+func (d *db) flush() {
+	for key := range d.keys {
+		d.touch(key)
+	}
+	d.keys = make(map[string]*entry)
+}
+
+// liveKeys returns the keys that are not expired.
+func (d *db) liveKeys() []string {
+	keys := make([]string, 0, len(d.keys))
+	for key := range d.keys {
+		if d.lookup(key) != nil {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (d *db) str(key string) (string, bool, error) {
+	e := d.lookup(key)
+	if e == nil {
+		return "", false, nil
+	}
+	s, ok := e.val.(string)
+	if !ok {
+		return "", false, errWrongType
+	}
+	return s, true, nil
+}
+
+// hash returns the hash of key, created if missing and create is set.
+func (d *db) hash(key string, create bool) (hash, error) {
+	e := d.lookup(key)
+	if e == nil {
+		if !create {
+			return nil, nil
+		}
+		e = d.put(key, make(hash))
+	}
+	h, ok := e.val.(hash)
+	if !ok {
+		return nil, errWrongType
+	}
+	return h, nil
+}
+
+func (d *db) list(key string, create bool) (*list, error) {
+	e := d.lookup(key)
+	if e == nil {
+		if !create {
+			return nil, nil
+		}
+		e = d.put(key, new(list))
+	}
+	l, ok := e.val.(*list)
+	if !ok {
+		return nil, errWrongType
+	}
+	return l, nil
+}
+
+func (d *db) set(key string, create bool) (set, error) {
+	e := d.lookup(key)
+	if e == nil {
+		if !create {
+			return nil, nil
+		}
+		e = d.put(key, make(set))
+	}
+	s, ok := e.val.(set)
+	if !ok {
+		return nil, errWrongType
+	}
+	return s, nil
+}
+
+func (d *db) zset(key string, create bool) (*zset, error) {
+	e := d.lookup(key)
+	if e == nil {
+		if !create {
+			return nil, nil
+		}
+		e = d.put(key, &zset{scores: make(map[string]float64)})
+	}
+	z, ok := e.val.(*zset)
+	if !ok {
+		return nil, errWrongType
+	}
+	return z, nil
+}
@@ -0,0 +1,233 @@
+package redistest
+
+import (
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9/internal/util"
+)
+
+func delCmd(c *client, args []string) {
+	d := c.currentDB()
+
+	var n int64
+	for _, key := range args[1:] {
+		if d.lookup(key) != nil && d.del(key) {
+			n++
+		}
+	}
+	c.w.int(n)
+}
+
+func existsCmd(c *client, args []string) {
+	d := c.currentDB()
+
+	var n int64
+	for _, key := range args[1:] {
+		if d.lookup(key) != nil {
+			n++
+		}
+	}
+	c.w.int(n)
+}
+
+// expireCmd implements EXPIRE, PEXPIRE, EXPIREAT and PEXPIREAT,
+// unit is the unit of the argument and at is set if it is a unix time.
+func expireCmd(unit time.Duration, at bool) func(c *client, args []string) {
+	return func(c *client, args []string) {
+		n, ok := parseInt(args[2])
+		if !ok {
+			c.w.error(msgNotInteger)
+			return
+		}
+
+		var nx, xx, gt, lt bool
+		for _, opt := range args[3:] {
+			switch strings.ToLower(opt) {
+			case "nx":
+				nx = true
+			case "xx":
+				xx = true
+			case "gt":
+				gt = true
+			case "lt":
+				lt = true
+			default:
+				c.w.errorf("ERR Unsupported option %s", opt)
+				return
+			}
+		}
+		if nx && (xx || gt || lt) || gt && lt {
+			c.w.error("ERR NX and XX, GT or LT options at the same time are not compatible")
+			return
+		}
+
+		var expireAt time.Time
+		if at {
+			expireAt = time.Unix(0, 0).Add(time.Duration(n) * unit)
+		} else {
+			expireAt = time.Now().Add(time.Duration(n) * unit)
+		}
+
+		d := c.currentDB()
+		key := args[1]
+		e := d.lookup(key)
+		if e == nil {
+			c.w.int(0)
+			return
+		}
+
+		// A key without TTL has an infinite TTL.
+		persistent := e.expireAt.IsZero()
+		switch {
+		case nx && !persistent,
+			xx && persistent,
+			gt && (persistent || !expireAt.After(e.expireAt)),
+			lt && !persistent && !expireAt.Before(e.expireAt):
+			c.w.int(0)
+			return
+		}
+
+		if !expireAt.After(time.Now()) {
+			d.del(key)
+		} else {
+			e.expireAt = expireAt
+			d.touch(key)
+		}
+		c.w.int(1)
+	}
+}
+
+func ttlCmd(unit time.Duration) func(c *client, args []string) {
+	return func(c *client, args []string) {
+		e := c.currentDB().lookup(args[1])
+		switch {
+		case e == nil:
+			c.w.int(-2)
+		case e.expireAt.IsZero():
+			c.w.int(-1)
+		default:
+			ttl := time.Until(e.expireAt)
+			c.w.int(int64((ttl + unit/2) / unit))
+		}
+	}
+}
+
+func persistCmd(c *client, args []string) {
+	d := c.currentDB()
+	e := d.lookup(args[1])
+	if e == nil || e.expireAt.IsZero() {
+		c.w.int(0)
+		return
+	}
+	e.expireAt = time.Time{}
+	d.touch(args[1])
+	c.w.int(1)
+}
+
+func keysCmd(c *client, args []string) {
+	var keys []string
+	for _, key := range c.currentDB().liveKeys() {
+		if util.MatchGlob(args[1], key) {
+			keys = append(keys, key)
+		}
+	}
+	c.w.bulks(keys)
+}
+
+// scanCmd returns all the keys at once.
+func scanCmd(c *client, args []string) {
+	if _, ok := parseInt(args[1]); !ok {
+		c.w.error("ERR invalid cursor")
+		return
+	}
+
+	pattern := "*"
+	var typ string
+	for i := 2; i < len(args); i++ {
+		if i+1 >= len(args) {
+			c.w.error(msgSyntax)
+			return
+		}
+		switch strings.ToLower(args[i]) {
+		case "match":
+			pattern = args[i+1]
+		case "count":
+			if n, ok := parseInt(args[i+1]); !ok || n < 1 {
+				c.w.error(msgSyntax)
+				return
+			}
+		case "type":
+			typ = strings.ToLower(args[i+1])
+		default:
+			c.w.error(msgSyntax)
+			return
+		}
+		i++
+	}
+
+	d := c.currentDB()
+	var keys []string
+	for _, key := range d.liveKeys() {
+		if !util.MatchGlob(pattern, key) {
+			continue
+		}
+		if typ != "" && typeName(d.lookup(key)) != typ {
+			continue
+		}
+		keys = append(keys, key)
+	}
+
+	c.w.array(2)
+	c.w.bulk("0")
+	c.w.bulks(keys)
+}
+
+func renameCmd(nx bool) func(c *client, args []string) {
+	return func(c *client, args []string) {
+		d := c.currentDB()
+		src, dst := args[1], args[2]
+
+		e := d.lookup(src)
+		if e == nil {
+			c.w.error("ERR no such key")
+			return
+		}
+		if nx && d.lookup(dst) != nil {
+			c.w.int(0)
+			return
+		}
+
+		d.del(src)
+		d.put(dst, e.val).expireAt = e.expireAt
+
+		if nx {
+			c.w.int(1)
+		} else {
+			c.w.ok()
+		}
+	}
+}
+
+func typeCmd(c *client, args []string) {
+	c.w.status(typeName(c.currentDB().lookup(args[1])))
+}
+
+func typeName(e *entry) string {
+	if e == nil {
+		return "none"
+	}
+	switch e.val.(type) {
+	case string:
+		return "string"
+	case hash:
+		return "hash"
+	case *list:
+		return "list"
+	case set:
+		return "set"
+	case *zset:
+		return "zset"
+	}
+	return "none"
+}
@@ -0,0 +1,247 @@
+package redistest
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func (h hash) fields() []string {
+	fields := make([]string, 0, len(h))
+	for field := range h {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// hsetCmd implements HSET and HMSET.
+func hsetCmd(c *client, args []string) {
+	if len(args)%2 != 0 {
+		c.w.errorf("ERR wrong number of arguments for '%s' command", strings.ToLower(args[0]))
+		return
+	}
+
+	d := c.currentDB()
+	h, err := d.hash(args[1], true)
+	if err != nil {
+		c.w.error(err.Error())
+		return
+	}
+
+	var n int64
+	for i := 2; i < len(args); i += 2 {
+		if _, ok := h[args[i]]; !ok {
+			n++
+		}
+		h[args[i]] = args[i+1]
+	}
+	d.modified(args[1])
+
+	if strings.EqualFold(args[0], "hmset") {
+		c.w.ok()
+	} else {
+		c.w.int(n)
+	}
+}
+
+func hsetnxCmd(c *client, args []string) {
+	d := c.currentDB()
+	h, err := d.hash(args[1], true)
+	if err != nil {
+		c.w.error(err.Error())
+		return
+	}
+
+	if _, ok := h[args[2]]; ok {
+		c.w.int(0)
+		return
+	}
+	h[args[2]] = args[3]
+	d.modified(args[1])
+	c.w.int(1)
+}
+
+func hgetCmd(c *client, args []string) {
+	h, err := c.currentDB().hash(args[1], false)
+	if err != nil {
+		c.w.error(err.Error())
+		return
+	}
+
+	if val, ok := h[args[2]]; ok {
+		c.w.bulk(val)
+	} else {
+		c.w.null()
+	}
+}
+
+func hmgetCmd(c *client, args []string) {
+	h, err := c.currentDB().hash(args[1], false)
+	if err != nil {
+		c.w.error(err.Error())
+		return
+	}
+
+	c.w.array(len(args) - 2)
+	for _, field := range args[2:] {
+		if val, ok := h[field]; ok {
+			c.w.bulk(val)
+		} else {
+			c.w.null()
+		}
+	}
+}
+
+func hgetallCmd(c *client, args []string) {
+	h, err := c.currentDB().hash(args[1], false)
+	if err != nil {
+		c.w.error(err.Error())
+		return
+	}
+
+	c.w.mapLen(len(h))
+	for _, field := range h.fields() {
+		c.w.bulk(field)
+		c.w.bulk(h[field])
+	}
+}
+
+func hdelCmd(c *client, args []string) {
+	d := c.currentDB()
+	h, err := d.hash(args[1], false)
+	if err != nil {
+		c.w.error(err.Error())
+		return
+	}
+
+	var n int64
+	for _, field := range args[2:] {
+		if _, ok := h[field]; ok {
+			delete(h, field)
+			n++
+		}
+	}
+	if n > 0 {
+		d.modified(args[1])
+	}
+	c.w.int(n)
+}
+
+func hexistsCmd(c *client, args []string) {
+	h, err := c.currentDB().hash(args[1], false)
+	if err != nil {
+		c.w.error(err.Error())
+		return
+	}
+
+	_, ok := h[args[2]]
+	c.w.bool(ok)
+}
+
+func hlenCmd(c *client, args []string) {
+	h, err := c.currentDB().hash(args[1], false)
+	if err != nil {
+		c.w.error(err.Error())
+		return
+	}
+	c.w.int(int64(len(h)))
+}
+
+func hstrlenCmd(c *client, args []string) {
+	h, err := c.currentDB().hash(args[1], false)
+	if err != nil {
+		c.w.error(err.Error())
+		return
+	}
+	c.w.int(int64(len(h[args[2]])))
+}
+
+func hkeysCmd(c *client, args []string) {
+	h, err := c.currentDB().hash(args[1], false)
+	if err != nil {
+		c.w.error(err.Error())
+		return
+	}
+	c.w.bulks(h.fields())
+}
+
+func hvalsCmd(c *client, args []string) {
+	h, err := c.currentDB().hash(args[1], false)
+	if err != nil {
+		c.w.error(err.Error())
+		return
+	}
+
+	fields := h.fields()
+	c.w.array(len(fields))
+	for _, field := range fields {
+		c.w.bulk(h[field])
+	}
+}
+
+func hincrbyCmd(c *client, args []string) {
+	delta, ok := parseInt(args[3])
+	if !ok {
+		c.w.error(msgNotInteger)
+		return
+	}
+
+	d := c.currentDB()
+	h, err := d.hash(args[1], true)
+	if err != nil {
+		c.w.error(err.Error())
+		return
+	}
+
+	var n int64
+	if s, exists := h[args[2]]; exists {
+		if n, ok = parseInt(s); !ok {
+			c.w.error("ERR hash value is not an integer")
+			return
+		}
+	}
+	if delta > 0 && n > math.MaxInt64-delta || delta < 0 && n < math.MinInt64-delta {
+		c.w.error("ERR increment or decrement would overflow")
+		return
+	}
+
+	n += delta
+	h[args[2]] = strconv.FormatInt(n, 10)
+	d.modified(args[1])
+	c.w.int(n)
+}
+
+func hincrbyfloatCmd(c *client, args []string) {
+	delta, ok := parseFloat(args[3])
+	if !ok {
+		c.w.error(msgNotFloat)
+		return
+	}
+
+	d := c.currentDB()
+	h, err := d.hash(args[1], true)
+	if err != nil {
+		c.w.error(err.Error())
+		return
+	}
+
+	var f float64
+	if s, exists := h[args[2]]; exists {
+		if f, ok = parseFloat(s); !ok {
+			c.w.error("ERR hash value is not a float")
+			return
+		}
+	}
+
+	f += delta
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		c.w.error("ERR increment would produce NaN or Infinity")
+		return
+	}
+
+	h[args[2]] = formatFloat(f)
+	d.modified(args[1])
+	c.w.bulk(formatFloat(f))
+}
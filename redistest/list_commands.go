@@ -0,0 +1,410 @@
+package redistest
+
+import (
+	"strings"
+	"time"
+)
+
+func (l *list) push(left bool, vals ...string) {
+	if !left {
+		l.items = append(l.items, vals...)
+		return
+	}
+	items := make([]string, 0, len(vals)+len(l.items))
+	for i := len(vals) - 1; i >= 0; i-- {
+		items = append(items, vals[i])
+	}
+	l.items = append(items, l.items...)
+}
+
+func (l *list) pop(left bool) string {
+	var val string
+	if left {
+		val = l.items[0]
+		l.items = l.items[1:]
+	} else {
+		val = l.items[len(l.items)-1]
+		l.items = l.items[:len(l.items)-1]
+	}
+	return val
+}
+
+// pushCmd implements LPUSH, RPUSH, LPUSHX and RPUSHX.
+func pushCmd(left, exists bool) func(c *client, args []string) {
+	return func(c *client, args []string) {
+		d := c.currentDB()
+		l, err := d.list(args[1], !exists)
+		if err != nil {
+			c.w.error(err.Error())
+			return
+		}
+		if l == nil {
+			c.w.int(0)
+			return
+		}
+
+		l.push(left, args[2:]...)
+		d.modified(args[1])
+		c.srv.notify()
+		c.w.int(int64(len(l.items)))
+	}
+}
+
+// popCmd implements LPOP and RPOP.
+func popCmd(left bool) func(c *client, args []string) {
+	return func(c *client, args []string) {
+		if len(args) > 3 {
+			c.w.error(msgSyntax)
+			return
+		}
+
+		count := int64(-1)
+		if len(args) == 3 {
+			n, ok := parseInt(args[2])
+			if !ok || n < 0 {
+				c.w.error("ERR value is out of range, must be positive")
+				return
+			}
+			count = n
+		}
+
+		d := c.currentDB()
+		l, err := d.list(args[1], false)
+		if err != nil {
+			c.w.error(err.Error())
+			return
+		}
+		if l == nil {
+			if count < 0 {
+				c.w.null()
+			} else {
+				c.w.nullArray()
+			}
+			return
+		}
+
+		if count < 0 {
+			c.w.bulk(l.pop(left))
+			d.modified(args[1])
+			return
+		}
+
+		if count > int64(len(l.items)) {
+			count = int64(len(l.items))
+		}
+		vals := make([]string, count)
+		for i := range vals {
+			vals[i] = l.pop(left)
+		}
+		d.modified(args[1])
+		c.w.bulks(vals)
+	}
+}
+
+// bpopCmd implements BLPOP and BRPOP.
+func bpopCmd(left bool) func(c *client, args []string) {
+	return func(c *client, args []string) {
+		timeout, ok := parseFloat(args[len(args)-1])
+		if !ok {
+			c.w.error("ERR timeout is not a float or out of range")
+			return
+		}
+		if timeout < 0 {
+			c.w.error("ERR timeout is negative")
+			return
+		}
+
+		var deadline time.Time
+		if timeout > 0 {
+			deadline = time.Now().Add(time.Duration(timeout * float64(time.Second)))
+		}
+
+		keys := args[1 : len(args)-1]
+		for {
+			d := c.currentDB()
+			for _, key := range keys {
+				l, err := d.list(key, false)
+				if err != nil {
+					c.w.error(err.Error())
+					return
+				}
+				if l == nil {
+					continue
+				}
+
+				val := l.pop(left)
+				d.modified(key)
+				c.w.array(2)
+				c.w.bulk(key)
+				c.w.bulk(val)
+				return
+			}
+
+			// Blocking commands don't block in transactions.
+			if c.execing || !c.srv.wait(c, deadline) {
+				c.w.nullArray()
+				return
+			}
+		}
+	}
+}
+
+func llenCmd(c *client, args []string) {
+	l, err := c.currentDB().list(args[1], false)
+	if err != nil {
+		c.w.error(err.Error())
+		return
+	}
+	if l == nil {
+		c.w.int(0)
+		return
+	}
+	c.w.int(int64(len(l.items)))
+}
+
+func lrangeCmd(c *client, args []string) {
+	start, ok1 := parseInt(args[2])
+	stop, ok2 := parseInt(args[3])
+	if !ok1 || !ok2 {
+		c.w.error(msgNotInteger)
+		return
+	}
+
+	l, err := c.currentDB().list(args[1], false)
+	if err != nil {
+		c.w.error(err.Error())
+		return
+	}
+	if l == nil {
+		c.w.array(0)
+		return
+	}
+
+	lo, hi := normalizeRange(start, stop, len(l.items))
+	c.w.bulks(l.items[lo:hi])
+}
+
+func lindexCmd(c *client, args []string) {
+	i, ok := parseInt(args[2])
+	if !ok {
+		c.w.error(msgNotInteger)
+		return
+	}
+
+	l, err := c.currentDB().list(args[1], false)
+	if err != nil {
+		c.w.error(err.Error())
+		return
+	}
+	if l == nil {
+		c.w.null()
+		return
+	}
+
+	if i < 0 {
+		i += int64(len(l.items))
+	}
+	if i < 0 || i >= int64(len(l.items)) {
+		c.w.null()
+		return
+	}
+	c.w.bulk(l.items[i])
+}
+
+func lsetCmd(c *client, args []string) {
+	i, ok := parseInt(args[2])
+	if !ok {
+		c.w.error(msgNotInteger)
+		return
+	}
+
+	d := c.currentDB()
+	l, err := d.list(args[1], false)
+	if err != nil {
+		c.w.error(err.Error())
+		return
+	}
+	if l == nil {
+		c.w.error("ERR no such key")
+		return
+	}
+
+	if i < 0 {
+		i += int64(len(l.items))
+	}
+	if i < 0 || i >= int64(len(l.items)) {
+		c.w.error("ERR index out of range")
+		return
+	}
+	l.items[i] = args[3]
+	d.modified(args[1])
+	c.w.ok()
+}
+
+func linsertCmd(c *client, args []string) {
+	var before bool
+	switch strings.ToLower(args[2]) {
+	case "before":
+		before = true
+	case "after":
+	default:
+		c.w.error(msgSyntax)
+		return
+	}
+
+	d := c.currentDB()
+	l, err := d.list(args[1], false)
+	if err != nil {
+		c.w.error(err.Error())
+		return
+	}
+	if l == nil {
+		c.w.int(0)
+		return
+	}
+
+	for i, item := range l.items {
+		if item != args[3] {
+			continue
+		}
+		if !before {
+			i++
+		}
+		l.items = append(l.items[:i], append([]string{args[4]}, l.items[i:]...)...)
+		d.modified(args[1])
+		c.w.int(int64(len(l.items)))
+		return
+	}
+	c.w.int(-1)
+}
+
+func lremCmd(c *client, args []string) {
+	count, ok := parseInt(args[2])
+	if !ok {
+		c.w.error(msgNotInteger)
+		return
+	}
+
+	d := c.currentDB()
+	l, err := d.list(args[1], false)
+	if err != nil {
+		c.w.error(err.Error())
+		return
+	}
+	if l == nil {
+		c.w.int(0)
+		return
+	}
+
+	// A negative count removes the elements from tail to head.
+	items := l.items
+	reverse := count < 0
+	if reverse {
+		items = reversed(items)
+		count = -count
+	}
+
+	var n int64
+	kept := make([]string, 0, len(items))
+	for _, item := range items {
+		if item == args[3] && (count == 0 || n < count) {
+			n++
+			continue
+		}
+		kept = append(kept, item)
+	}
+	if reverse {
+		kept = reversed(kept)
+	}
+	l.items = kept
+
+	if n > 0 {
+		d.modified(args[1])
+	}
+	c.w.int(n)
+}
+
+func reversed(ss []string) []string {
+	r := make([]string, len(ss))
+	for i, s := range ss {
+		r[len(ss)-1-i] = s
+	}
+	return r
+}
+
+func ltrimCmd(c *client, args []string) {
+	start, ok1 := parseInt(args[2])
+	stop, ok2 := parseInt(args[3])
+	if !ok1 || !ok2 {
+		c.w.error(msgNotInteger)
+		return
+	}
+
+	d := c.currentDB()
+	l, err := d.list(args[1], false)
+	if err != nil {
+		c.w.error(err.Error())
+		return
+	}
+	if l == nil {
+		c.w.ok()
+		return
+	}
+
+	lo, hi := normalizeRange(start, stop, len(l.items))
+	l.items = append([]string(nil), l.items[lo:hi]...)
+	d.modified(args[1])
+	c.w.ok()
+}
+
+func lmoveCmd(c *client, args []string) {
+	var fromLeft, toLeft bool
+	for i, arg := range args[3:5] {
+		var left bool
+		switch strings.ToLower(arg) {
+		case "left":
+			left = true
+		case "right":
+		default:
+			c.w.error(msgSyntax)
+			return
+		}
+		if i == 0 {
+			fromLeft = left
+		} else {
+			toLeft = left
+		}
+	}
+	move(c, args[1], args[2], fromLeft, toLeft)
+}
+
+func rpoplpushCmd(c *client, args []string) {
+	move(c, args[1], args[2], false, true)
+}
+
+func move(c *client, src, dst string, fromLeft, toLeft bool) {
+	d := c.currentDB()
+	from, err := d.list(src, false)
+	if err != nil {
+		c.w.error(err.Error())
+		return
+	}
+	if from == nil {
+		c.w.null()
+		return
+	}
+	if _, err := d.list(dst, false); err != nil {
+		c.w.error(err.Error())
+		return
+	}
+
+	val := from.pop(fromLeft)
+	d.modified(src)
+
+	to, _ := d.list(dst, true)
+	to.push(toLeft, val)
+	d.modified(dst)
+	c.srv.notify()
+
+	c.w.bulk(val)
+}
@@ -0,0 +1,174 @@
+package redistest
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/redis/go-redis/v9/internal/util"
+)
+
+// subscribeCmd implements SUBSCRIBE and PSUBSCRIBE.
+func subscribeCmd(pattern bool) func(c *client, args []string) {
+	return func(c *client, args []string) {
+		kind := "subscribe"
+		if pattern {
+			kind = "psubscribe"
+		}
+
+		for _, name := range args[1:] {
+			subs := c.subscriptions(pattern)
+			subs[name] = struct{}{}
+			c.subscription(kind, name)
+		}
+	}
+}
+
+// unsubscribeCmd implements UNSUBSCRIBE and PUNSUBSCRIBE.
+func unsubscribeCmd(pattern bool) func(c *client, args []string) {
+	return func(c *client, args []string) {
+		kind := "unsubscribe"
+		if pattern {
+			kind = "punsubscribe"
+		}
+
+		subs := c.subscriptions(pattern)
+		names := args[1:]
+		if len(names) == 0 {
+			for name := range subs {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+		}
+
+		if len(names) == 0 {
+			c.w.pushLen(3)
+			c.w.bulk(kind)
+			c.w.null()
+			c.w.int(int64(len(c.channels) + len(c.patterns)))
+			return
+		}
+
+		for _, name := range names {
+			delete(subs, name)
+			c.subscription(kind, name)
+		}
+	}
+}
+
+func (c *client) subscriptions(pattern bool) map[string]struct{} {
+	if pattern {
+		if c.patterns == nil {
+			c.patterns = make(map[string]struct{})
+		}
+		return c.patterns
+	}
+	if c.channels == nil {
+		c.channels = make(map[string]struct{})
+	}
+	return c.channels
+}
+
+func (c *client) subscription(kind, name string) {
+	c.w.pushLen(3)
+	c.w.bulk(kind)
+	c.w.bulk(name)
+	c.w.int(int64(len(c.channels) + len(c.patterns)))
+}
+
+func publishCmd(c *client, args []string) {
+	channel, msg := args[1], args[2]
+
+	var n int64
+	for sub := range c.srv.clients {
+		if _, ok := sub.channels[channel]; ok {
+			sub.message(c, func(w *writer) {
+				w.pushLen(3)
+				w.bulk("message")
+				w.bulk(channel)
+				w.bulk(msg)
+			})
+			n++
+		}
+
+		for pattern := range sub.patterns {
+			if !util.MatchGlob(pattern, channel) {
+				continue
+			}
+			sub.message(c, func(w *writer) {
+				w.pushLen(4)
+				w.bulk("pmessage")
+				w.bulk(pattern)
+				w.bulk(channel)
+				w.bulk(msg)
+			})
+			n++
+		}
+	}
+	c.w.int(n)
+}
+
+// message writes a message published by the client pub to c.
+func (c *client) message(pub *client, write func(w *writer)) {
+	if c == pub {
+		// The reply of PUBLISH is flushed with the message.
+		write(c.w)
+		return
+	}
+
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+
+	write(c.w)
+	_ = c.w.bw.Flush()
+}
+
+func pubsubCmd(c *client, args []string) {
+	switch sub := strings.ToLower(args[1]); sub {
+	case "channels":
+		if len(args) > 3 {
+			c.w.errorf("ERR wrong number of arguments for 'pubsub|%s' command", sub)
+			return
+		}
+
+		pattern := "*"
+		if len(args) == 3 {
+			pattern = args[2]
+		}
+
+		channels := make(map[string]struct{})
+		for cl := range c.srv.clients {
+			for channel := range cl.channels {
+				if util.MatchGlob(pattern, channel) {
+					channels[channel] = struct{}{}
+				}
+			}
+		}
+
+		names := make([]string, 0, len(channels))
+		for channel := range channels {
+			names = append(names, channel)
+		}
+		sort.Strings(names)
+		c.w.bulks(names)
+	case "numsub":
+		c.w.mapLen(len(args) - 2)
+		for _, channel := range args[2:] {
+			var n int64
+			for cl := range c.srv.clients {
+				if _, ok := cl.channels[channel]; ok {
+					n++
+				}
+			}
+			c.w.bulk(channel)
+			c.w.int(n)
+		}
+	case "numpat":
+		var n int64
+		for cl := range c.srv.clients {
+			n += int64(len(cl.patterns))
+		}
+		c.w.int(n)
+	default:
+		c.w.errorf("ERR unknown subcommand '%s'. Try PUBSUB HELP.", args[1])
+	}
+}
@@ -0,0 +1,481 @@
+package redistest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/redis/go-redis/v9/internal/proto"
+)
+
+var errMalformed = errors.New("redistest: malformed RESP")
+
+// Recorder records the traffic of a client: every command sent to the
+// server followed by its raw RESP reply, including the commands of pipelines
+// and transactions and the connection handshake. The recording can be served
+// back to a client by a Replayer.
+//
+// Commands and replies are recorded as raw bytes by the connections of the
+// Dialer, so that the replies are recorded exactly as they were sent. The
+// Dialer is used as the Options.Dialer of the client, so that no connection
+// escapes the recording:
+//
+//	rec := redistest.NewRecorder(f)
+//	rdb := redis.NewClient(&redis.Options{
+//		Addr:   addr,
+//		Dialer: rec.Dialer(nil),
+//	})
+//
+// The handshake is recorded as sent too, so a recording only replays for a
+// client configured like the recorded one, CLIENT SETINFO aside, see Replayer.
+//
+// Pub/Sub connections are not supported.
+type Recorder struct {
+	mu  sync.Mutex
+	w   io.Writer
+	err error
+}
+
+// NewRecorder returns a Recorder that writes the traffic to w.
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{w: w}
+}
+
+// Err returns the first error that occurred writing the recording.
+func (r *Recorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// Dialer returns a dialer that records the traffic of the connections dialed
+// by dial, to be used as the Options.Dialer of a client. A nil dial dials
+// over TCP, use redis.NewDialer to dial like a client would with TLS.
+func (r *Recorder) Dialer(
+	dial func(ctx context.Context, network, addr string) (net.Conn, error),
+) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	if dial == nil {
+		var d net.Dialer
+		dial = d.DialContext
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		return &recordConn{Conn: conn, rec: r}, nil
+	}
+}
+
+func (r *Recorder) record(cmd, reply []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err != nil {
+		return
+	}
+	if _, err := r.w.Write(cmd); err != nil {
+		r.err = err
+		return
+	}
+	if _, err := r.w.Write(reply); err != nil {
+		r.err = err
+	}
+}
+
+func (r *Recorder) fail(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+// recordConn splits the traffic of a connection into commands and
+// replies and pairs them in order.
+type recordConn struct {
+	net.Conn
+	rec *Recorder
+
+	mu      sync.Mutex
+	wbuf    []byte
+	wscan   respScanner
+	rbuf    []byte
+	rscan   respScanner
+	pending [][]byte
+	// reply holds the push messages received before the next reply.
+	reply  []byte
+	broken bool
+}
+
+func (c *recordConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.broken {
+		return n, err
+	}
+
+	c.wbuf = append(c.wbuf, b[:n]...)
+	for {
+		m, scanErr := c.wscan.scan(c.wbuf)
+		if scanErr != nil {
+			c.fail(scanErr)
+			break
+		}
+		if m == 0 {
+			break
+		}
+		c.pending = append(c.pending, append([]byte(nil), c.wbuf[:m]...))
+		c.wbuf = c.wbuf[m:]
+	}
+	return n, err
+}
+
+func (c *recordConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.broken {
+		return n, err
+	}
+
+	c.rbuf = append(c.rbuf, b[:n]...)
+	for {
+		m, scanErr := c.rscan.scan(c.rbuf)
+		if scanErr != nil {
+			c.fail(scanErr)
+			break
+		}
+		if m == 0 {
+			break
+		}
+
+		c.reply = append(c.reply, c.rbuf[:m]...)
+		isPush := c.rbuf[0] == proto.RespPush
+		c.rbuf = c.rbuf[m:]
+
+		if isPush || len(c.pending) == 0 {
+			continue
+		}
+		c.rec.record(c.pending[0], c.reply)
+		c.pending = c.pending[1:]
+		c.reply = nil
+	}
+	return n, err
+}
+
+func (c *recordConn) fail(err error) {
+	c.broken = true
+	c.wbuf, c.rbuf, c.pending, c.reply = nil, nil, nil, nil
+	c.rec.fail(err)
+}
+
+//------------------------------------------------------------------------------
+
+// Replayer serves the replies recorded by a Recorder. Its Dial method
+// is used as the Options.Dialer of a client, which then works without
+// a server:
+//
+//	rp, err := redistest.NewReplayer(f)
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	rdb := redis.NewClient(&redis.Options{Dialer: rp.Dial})
+//
+// The client must be configured like the recorded one, so that it
+// sends the same handshake. Each command gets the recorded replies of
+// the same command in order and the last one when they run out.
+// Commands that were not recorded get an error reply, except CLIENT SETINFO
+// which gets OK, so that recordings keep working when the library version
+// sent in the handshake changes.
+type Replayer struct {
+	mu      sync.Mutex
+	replies map[string]*replies
+}
+
+type replies struct {
+	list [][]byte
+	next int
+}
+
+// NewReplayer reads a recording written by a Recorder.
+func NewReplayer(r io.Reader) (*Replayer, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	rp := &Replayer{
+		replies: make(map[string]*replies),
+	}
+
+	for len(b) > 0 {
+		n, err := scanValue(b)
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		cmd := string(b[:n])
+		b = b[n:]
+
+		// Push messages are replayed with the reply that follows them.
+		var reply []byte
+		for {
+			m, err := scanValue(b)
+			if err != nil {
+				return nil, err
+			}
+			if m == 0 {
+				return nil, io.ErrUnexpectedEOF
+			}
+			reply = append(reply, b[:m]...)
+			isPush := b[0] == proto.RespPush
+			b = b[m:]
+			if !isPush {
+				break
+			}
+		}
+
+		rs, ok := rp.replies[cmd]
+		if !ok {
+			rs = new(replies)
+			rp.replies[cmd] = rs
+		}
+		rs.list = append(rs.list, reply)
+	}
+
+	return rp, nil
+}
+
+// Dial returns a connection served by the Replayer.
+func (rp *Replayer) Dial(ctx context.Context, network, addr string) (net.Conn, error) {
+	client, server := net.Pipe()
+	go rp.serve(server)
+	return client, nil
+}
+
+func (rp *Replayer) serve(conn net.Conn) {
+	defer conn.Close()
+
+	// net.Pipe is not buffered, so the replies are written by another
+	// goroutine while the client is still writing a pipeline.
+	var (
+		mu      sync.Mutex
+		pending []byte
+	)
+	signal := make(chan struct{}, 1)
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		for {
+			select {
+			case <-signal:
+			case <-done:
+				return
+			}
+
+			mu.Lock()
+			b := pending
+			pending = nil
+			mu.Unlock()
+
+			if _, err := conn.Write(b); err != nil {
+				_ = conn.Close()
+				return
+			}
+		}
+	}()
+
+	var (
+		buf  []byte
+		scan respScanner
+	)
+	chunk := make([]byte, 4096)
+	for {
+		n, err := conn.Read(chunk)
+		if err != nil {
+			return
+		}
+		buf = append(buf, chunk[:n]...)
+
+		for {
+			m, err := scan.scan(buf)
+			if err != nil {
+				return
+			}
+			if m == 0 {
+				break
+			}
+
+			reply := rp.reply(buf[:m])
+			buf = buf[m:]
+
+			mu.Lock()
+			pending = append(pending, reply...)
+			mu.Unlock()
+
+			select {
+			case signal <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+func (rp *Replayer) reply(cmd []byte) []byte {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+
+	rs, ok := rp.replies[string(cmd)]
+	if !ok {
+		if isClientSetInfo(cmd) {
+			return []byte("+OK\r\n")
+		}
+		return []byte(fmt.Sprintf("-ERR redistest: no recorded reply for '%s'\r\n", formatCmd(cmd)))
+	}
+
+	reply := rs.list[rs.next]
+	if rs.next < len(rs.list)-1 {
+		rs.next++
+	}
+	return reply
+}
+
+func isClientSetInfo(cmd []byte) bool {
+	args := cmdArgs(cmd)
+	return len(args) > 1 &&
+		strings.EqualFold(fmt.Sprint(args[0]), "client") &&
+		strings.EqualFold(fmt.Sprint(args[1]), "setinfo")
+}
+
+func cmdArgs(cmd []byte) []interface{} {
+	v, err := proto.NewReader(bytes.NewReader(cmd)).ReadReply()
+	if err != nil {
+		return nil
+	}
+	args, _ := v.([]interface{})
+	return args
+}
+
+func formatCmd(cmd []byte) string {
+	args := cmdArgs(cmd)
+	if args == nil {
+		return ""
+	}
+
+	ss := make([]string, len(args))
+	for i, arg := range args {
+		ss[i] = fmt.Sprint(arg)
+	}
+	return strings.Join(ss, " ")
+}
+
+//------------------------------------------------------------------------------
+
+// scanValue returns the length of the RESP value at the start of b,
+// or 0 if b does not hold all of it yet.
+func scanValue(b []byte) (int, error) {
+	var s respScanner
+	return s.scan(b)
+}
+
+// respScanner finds the end of the RESP values received in chunks. The bytes
+// scanned while a value is incomplete are not scanned again on the next call.
+type respScanner struct {
+	off   int   // length of the complete elements scanned so far
+	stack []int // number of elements left in each aggregate being scanned
+}
+
+// scan returns the length of the RESP value at the start of b, or 0 if b
+// does not hold all of it yet. b must start with the bytes passed to the
+// previous call until it returns the length of a value.
+func (s *respScanner) scan(b []byte) (int, error) {
+	for s.off < len(b) {
+		i := bytes.Index(b[s.off:], []byte("\r\n"))
+		if i < 0 {
+			return 0, nil
+		}
+		if i == 0 {
+			return 0, s.fail()
+		}
+		line := b[s.off : s.off+i]
+		n := i + 2
+
+		switch line[0] {
+		case proto.RespStatus, proto.RespError, proto.RespInt, proto.RespNil,
+			proto.RespFloat, proto.RespBool, proto.RespBigInt:
+		case proto.RespString, proto.RespBlobError, proto.RespVerbatim:
+			size, err := strconv.Atoi(string(line[1:]))
+			if err != nil {
+				return 0, s.fail()
+			}
+			if size >= 0 {
+				if len(b) < s.off+n+size+2 {
+					return 0, nil
+				}
+				n += size + 2
+			}
+		case proto.RespArray, proto.RespMap, proto.RespSet, proto.RespAttr, proto.RespPush:
+			count, err := strconv.Atoi(string(line[1:]))
+			if err != nil {
+				return 0, s.fail()
+			}
+			if line[0] == proto.RespMap || line[0] == proto.RespAttr {
+				count *= 2
+			}
+			// Attributes are followed by the reply they describe.
+			if line[0] == proto.RespAttr {
+				count++
+			}
+			if count > 0 {
+				s.off += n
+				s.stack = append(s.stack, count)
+				continue
+			}
+		default:
+			return 0, s.fail()
+		}
+
+		s.off += n
+		if s.complete() {
+			n := s.off
+			s.off = 0
+			return n, nil
+		}
+	}
+	return 0, nil
+}
+
+// complete counts an element as scanned and reports whether the value is.
+func (s *respScanner) complete() bool {
+	for len(s.stack) > 0 {
+		top := len(s.stack) - 1
+		s.stack[top]--
+		if s.stack[top] > 0 {
+			return false
+		}
+		s.stack = s.stack[:top]
+	}
+	return true
+}
+
+func (s *respScanner) fail() error {
+	s.off = 0
+	s.stack = s.stack[:0]
+	return errMalformed
+}
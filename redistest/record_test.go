@@ -0,0 +1,171 @@
+package redistest_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+
+	. "github.com/bsm/ginkgo/v2"
+	. "github.com/bsm/gomega"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/redis/go-redis/v9/redistest"
+)
+
+var _ = Describe("Recorder", func() {
+	for _, protocol := range []int{2, 3} {
+		protocol := protocol
+
+		It(fmt.Sprintf("replays a RESP%d session", protocol), func() {
+			srv, err := redistest.Start()
+			Expect(err).NotTo(HaveOccurred())
+			defer srv.Close()
+
+			var buf bytes.Buffer
+			rec := redistest.NewRecorder(&buf)
+
+			session := func(client *redis.Client) {
+				Expect(client.Set(ctx, "key", "hello", 0).Err()).NotTo(HaveOccurred())
+				Expect(client.Get(ctx, "key").Val()).To(Equal("hello"))
+				Expect(client.Get(ctx, "missing").Err()).To(Equal(redis.Nil))
+
+				cmds, err := client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+					pipe.Incr(ctx, "counter")
+					pipe.Incr(ctx, "counter")
+					pipe.HSet(ctx, "hash", "field", "value")
+					return nil
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cmds[1].(*redis.IntCmd).Val()).To(Equal(int64(2)))
+
+				var get *redis.MapStringStringCmd
+				_, err = client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+					pipe.Incr(ctx, "counter")
+					get = pipe.HGetAll(ctx, "hash")
+					return nil
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(get.Val()).To(Equal(map[string]string{"field": "value"}))
+
+				Expect(client.Get(ctx, "counter").Val()).To(Equal("3"))
+			}
+
+			client := redis.NewClient(&redis.Options{
+				Addr:     srv.Addr(),
+				Protocol: protocol,
+				Dialer:   rec.Dialer(nil),
+			})
+			session(client)
+			Expect(client.Close()).NotTo(HaveOccurred())
+			Expect(rec.Err()).NotTo(HaveOccurred())
+
+			rp, err := redistest.NewReplayer(&buf)
+			Expect(err).NotTo(HaveOccurred())
+
+			client = redis.NewClient(&redis.Options{
+				Protocol: protocol,
+				Dialer:   rp.Dial,
+			})
+			defer client.Close()
+			session(client)
+
+			err = client.Get(ctx, "unknown").Err()
+			Expect(err).To(MatchError("ERR redistest: no recorded reply for 'get unknown'"))
+		})
+	}
+
+	It("replays with another library identity", func() {
+		srv, err := redistest.Start()
+		Expect(err).NotTo(HaveOccurred())
+		defer srv.Close()
+
+		var buf bytes.Buffer
+		rec := redistest.NewRecorder(&buf)
+
+		client := redis.NewClient(&redis.Options{Addr: srv.Addr(), Dialer: rec.Dialer(nil)})
+		Expect(client.Set(ctx, "key", "hello", 0).Err()).NotTo(HaveOccurred())
+		Expect(client.Close()).NotTo(HaveOccurred())
+
+		rp, err := redistest.NewReplayer(&buf)
+		Expect(err).NotTo(HaveOccurred())
+
+		client = redis.NewClient(&redis.Options{
+			Dialer:         rp.Dial,
+			IdentitySuffix: "replayed",
+		})
+		defer client.Close()
+		Expect(client.Set(ctx, "key", "hello", 0).Err()).NotTo(HaveOccurred())
+		Expect(client.Do(ctx, "client", "setinfo", "lib-ver", "0.0.0").Err()).NotTo(HaveOccurred())
+	})
+
+	It("records large replies read in chunks", func() {
+		srv, err := redistest.Start()
+		Expect(err).NotTo(HaveOccurred())
+		defer srv.Close()
+
+		var buf bytes.Buffer
+		rec := redistest.NewRecorder(&buf)
+
+		value := strings.Repeat("x", 1<<20)
+		client := redis.NewClient(&redis.Options{Addr: srv.Addr(), Dialer: rec.Dialer(nil)})
+		Expect(client.Set(ctx, "key", value, 0).Err()).NotTo(HaveOccurred())
+		Expect(client.Get(ctx, "key").Val()).To(Equal(value))
+		Expect(client.Close()).NotTo(HaveOccurred())
+		Expect(rec.Err()).NotTo(HaveOccurred())
+
+		rp, err := redistest.NewReplayer(&buf)
+		Expect(err).NotTo(HaveOccurred())
+
+		client = redis.NewClient(&redis.Options{Dialer: rp.Dial})
+		defer client.Close()
+		Expect(client.Set(ctx, "key", value, 0).Err()).NotTo(HaveOccurred())
+		Expect(client.Get(ctx, "key").Val()).To(Equal(value))
+	})
+
+	It("records the connections dialed before the first command", func() {
+		srv, err := redistest.Start()
+		Expect(err).NotTo(HaveOccurred())
+		defer srv.Close()
+
+		var buf bytes.Buffer
+		rec := redistest.NewRecorder(&buf)
+
+		// The idle connections are dialed as the client is created.
+		client := redis.NewClient(&redis.Options{
+			Addr:         srv.Addr(),
+			MinIdleConns: 4,
+			Dialer:       rec.Dialer(nil),
+		})
+		Eventually(func() uint32 {
+			return client.PoolStats().IdleConns
+		}).Should(BeEquivalentTo(4))
+
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer GinkgoRecover()
+				defer wg.Done()
+				Expect(client.Set(ctx, fmt.Sprint("key", i), i, 0).Err()).NotTo(HaveOccurred())
+			}(i)
+		}
+		wg.Wait()
+		Expect(client.Close()).NotTo(HaveOccurred())
+		Expect(rec.Err()).NotTo(HaveOccurred())
+
+		rp, err := redistest.NewReplayer(&buf)
+		Expect(err).NotTo(HaveOccurred())
+
+		client = redis.NewClient(&redis.Options{Dialer: rp.Dial})
+		defer client.Close()
+		for i := 0; i < 4; i++ {
+			Expect(client.Set(ctx, fmt.Sprint("key", i), i, 0).Err()).NotTo(HaveOccurred())
+		}
+	})
+
+	It("rejects truncated recordings", func() {
+		_, err := redistest.NewReplayer(bytes.NewBufferString("*1\r\n$4\r\nping\r\n"))
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,442 @@
+// Package redistest implements an in-memory Redis server for tests.
+//
+// The server speaks RESP2 and RESP3 and supports a subset of the Redis
+// commands: strings, hashes, lists, sets, sorted sets, key expiry,
+// MULTI/EXEC, WATCH and pub/sub. It is meant to run the tests using
+// a Client, a Ring or a Pipeline without a real redis-server:
+//
+//	srv, err := redistest.Start()
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	defer srv.Close()
+//
+//	rdb := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+//
+// The package also records the traffic of a client with the Dialer of a
+// Recorder and replays it with the Dialer of a Replayer.
+package redistest
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9/internal/proto"
+)
+
+const numDBs = 16
+
+var errServerClosed = errors.New("redistest: server closed")
+
+// Server is an in-memory Redis server.
+type Server struct {
+	mu sync.Mutex
+
+	ln      net.Listener
+	dbs     [numDBs]*db
+	clients map[*client]struct{}
+	lastID  int64
+	version uint64
+	closed  bool
+
+	// changed is closed and replaced when a list is pushed to,
+	// waking up the clients blocked in BLPOP and BRPOP.
+	changed chan struct{}
+
+	// pausedUntil delays the commands of all the clients, see CLIENT PAUSE.
+	pausedUntil time.Time
+
+	wg sync.WaitGroup
+}
+
+// NewServer returns a server with empty databases.
+// Use Serve to accept connections.
+func NewServer() *Server {
+	s := &Server{
+		clients: make(map[*client]struct{}),
+		changed: make(chan struct{}),
+	}
+	for i := range s.dbs {
+		s.dbs[i] = newDB(s)
+	}
+	return s
+}
+
+// Start returns a server listening on a random local port.
+func Start() (*Server, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	s := NewServer()
+	if err := s.listen(ln); err != nil {
+		return nil, err
+	}
+	go func() {
+		_ = s.accept(ln)
+	}()
+	return s, nil
+}
+
+// Serve accepts connections on ln until the server is closed.
+func (s *Server) Serve(ln net.Listener) error {
+	if err := s.listen(ln); err != nil {
+		return err
+	}
+	return s.accept(ln)
+}
+
+func (s *Server) listen(ln net.Listener) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		_ = ln.Close()
+		return errServerClosed
+	}
+	if s.ln != nil {
+		_ = ln.Close()
+		return errors.New("redistest: server is already serving")
+	}
+	s.ln = ln
+	return nil
+}
+
+func (s *Server) accept(ln net.Listener) error {
+	for {
+		netConn, err := ln.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return errServerClosed
+			}
+			return err
+		}
+
+		c := s.newClient(netConn)
+		if c == nil {
+			_ = netConn.Close()
+			return errServerClosed
+		}
+
+		go func() {
+			defer s.wg.Done()
+			c.serve()
+		}()
+	}
+}
+
+// Addr returns the address the server listens on,
+// empty if the server is not serving.
+func (s *Server) Addr() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ln == nil {
+		return ""
+	}
+	return s.ln.Addr().String()
+}
+
+// Close stops the server and closes all the client connections.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return errServerClosed
+	}
+	s.closed = true
+
+	var err error
+	if s.ln != nil {
+		err = s.ln.Close()
+	}
+	for c := range s.clients {
+		c.close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+	return err
+}
+
+// FlushAll removes all the keys from all the databases.
+func (s *Server) FlushAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, d := range s.dbs {
+		d.flush()
+	}
+}
+
+func (s *Server) newClient(netConn net.Conn) *client {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil
+	}
+
+	s.lastID++
+	c := &client{
+		srv:     s,
+		id:      s.lastID,
+		netConn: netConn,
+		rd:      proto.NewReader(netConn),
+		w:       &writer{bw: bufio.NewWriter(netConn)},
+		closed:  make(chan struct{}),
+	}
+	s.clients[c] = struct{}{}
+	s.wg.Add(1)
+	return c
+}
+
+func (s *Server) removeClient(c *client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.clients, c)
+	c.channels = nil
+	c.patterns = nil
+}
+
+// nextVersion returns the version of a key modified now, see WATCH.
+func (s *Server) nextVersion() uint64 {
+	s.version++
+	return s.version
+}
+
+// notify wakes up the blocked clients.
+func (s *Server) notify() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// wait releases the server lock of the blocked client c until
+// the data changes or the deadline is reached, it reports false
+// on timeout. A zero deadline waits forever.
+func (s *Server) wait(c *client, deadline time.Time) bool {
+	changed := s.changed
+
+	var timeout <-chan time.Time
+	if !deadline.IsZero() {
+		d := time.Until(deadline)
+		if d <= 0 {
+			return false
+		}
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	c.wmu.Unlock()
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		c.wmu.Lock()
+	}()
+
+	select {
+	case <-changed:
+		return true
+	case <-timeout:
+		return false
+	case <-c.closed:
+		return false
+	}
+}
+
+// waitUnpaused releases the server lock until the clients are not paused anymore,
+// it reports false if the client c is closed meanwhile.
+func (s *Server) waitUnpaused(c *client) bool {
+	for {
+		d := time.Until(s.pausedUntil)
+		if d <= 0 {
+			return true
+		}
+
+		s.mu.Unlock()
+		timer := time.NewTimer(d)
+		select {
+		case <-timer.C:
+		case <-c.closed:
+			timer.Stop()
+			s.mu.Lock()
+			return false
+		}
+		s.mu.Lock()
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type client struct {
+	srv *Server
+	id  int64
+
+	netConn net.Conn
+	rd      *proto.Reader
+
+	wmu sync.Mutex // protects w, messages are written by publishers
+	w   *writer
+
+	closeOnce sync.Once
+	closed    chan struct{}
+
+	db   int
+	name string
+
+	multi    bool
+	queued   [][]string
+	multiErr bool
+	execing  bool
+	watched  map[watchKey]uint64
+
+	channels map[string]struct{}
+	patterns map[string]struct{}
+}
+
+type watchKey struct {
+	db  int
+	key string
+}
+
+func (c *client) serve() {
+	defer c.srv.removeClient(c)
+	defer c.close()
+
+	for {
+		args, err := c.readCommand()
+		if err != nil {
+			var redisErr proto.RedisError
+			if errors.As(err, &redisErr) {
+				c.wmu.Lock()
+				c.w.error(redisErr.Error())
+				_ = c.w.bw.Flush()
+				c.wmu.Unlock()
+			}
+			return
+		}
+
+		c.srv.mu.Lock()
+		if !c.srv.waitUnpaused(c) {
+			c.srv.mu.Unlock()
+			return
+		}
+		c.wmu.Lock()
+		quit := c.exec(args)
+		c.srv.mu.Unlock()
+
+		var err2 error
+		if quit || c.rd.Buffered() == 0 {
+			err2 = c.w.bw.Flush()
+		}
+		c.wmu.Unlock()
+
+		if quit || err2 != nil {
+			return
+		}
+	}
+}
+
+func (c *client) close() {
+	c.closeOnce.Do(func() {
+		close(c.closed)
+		_ = c.netConn.Close()
+	})
+}
+
+func (c *client) readCommand() ([]string, error) {
+	typ, err := c.rd.PeekReplyType()
+	if err != nil {
+		return nil, err
+	}
+	if typ != proto.RespArray {
+		return nil, proto.RedisError("ERR Protocol error: expected '*', got '" + string(typ) + "'")
+	}
+
+	vals, err := c.rd.ReadSlice()
+	if err != nil {
+		return nil, err
+	}
+	if len(vals) == 0 {
+		return nil, proto.RedisError("ERR Protocol error: empty command")
+	}
+
+	args := make([]string, len(vals))
+	for i, v := range vals {
+		s, ok := v.(string)
+		if !ok {
+			return nil, proto.RedisError("ERR Protocol error: expected '$'")
+		}
+		args[i] = s
+	}
+	return args, nil
+}
+
+// exec runs a command holding the server lock, it reports whether
+// the connection must be closed.
+func (c *client) exec(args []string) bool {
+	name := strings.ToLower(args[0])
+	cmd, ok := commands[name]
+	if !ok {
+		c.discardMulti()
+		c.w.errorf("ERR unknown command '%s', with args beginning with: %s",
+			args[0], formatArgs(args[1:]))
+		return false
+	}
+
+	if (cmd.arity > 0 && len(args) != cmd.arity) || len(args) < -cmd.arity {
+		c.discardMulti()
+		c.w.errorf("ERR wrong number of arguments for '%s' command", name)
+		return false
+	}
+
+	if !c.w.resp3 && c.subscribed() && cmd.flags&flagPubSub == 0 {
+		c.w.errorf("ERR Can't execute '%s': only (P|S)SUBSCRIBE / (P|S)UNSUBSCRIBE / "+
+			"PING / QUIT / RESET are allowed in this context", name)
+		return false
+	}
+
+	if c.multi && cmd.flags&flagNoQueue == 0 {
+		c.queued = append(c.queued, args)
+		c.w.status("QUEUED")
+		return false
+	}
+
+	cmd.fn(c, args)
+	return name == "quit"
+}
+
+// discardMulti makes EXEC fail after an error queueing a command.
+func (c *client) discardMulti() {
+	if c.multi {
+		c.multiErr = true
+	}
+}
+
+func (c *client) currentDB() *db {
+	return c.srv.dbs[c.db]
+}
+
+func (c *client) subscribed() bool {
+	return len(c.channels)+len(c.patterns) > 0
+}
+
+func formatArgs(args []string) string {
+	var b strings.Builder
+	for _, arg := range args {
+		b.WriteString("'")
+		b.WriteString(arg)
+		b.WriteString("' ")
+	}
+	return b.String()
+}
@@ -0,0 +1,278 @@
+package redistest_test
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/bsm/ginkgo/v2"
+	. "github.com/bsm/gomega"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/redis/go-redis/v9/redistest"
+)
+
+var ctx = context.Background()
+
+func TestGinkgoSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "redistest")
+}
+
+var _ = Describe("Server", func() {
+	var srv *redistest.Server
+
+	BeforeEach(func() {
+		var err error
+		srv, err = redistest.Start()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(srv.Close()).NotTo(HaveOccurred())
+	})
+
+	for _, protocol := range []int{2, 3} {
+		protocol := protocol
+
+		Describe(fmt.Sprintf("RESP%d", protocol), func() {
+			var client *redis.Client
+
+			BeforeEach(func() {
+				client = redis.NewClient(&redis.Options{
+					Addr:     srv.Addr(),
+					Protocol: protocol,
+				})
+			})
+
+			AfterEach(func() {
+				Expect(client.Close()).NotTo(HaveOccurred())
+			})
+
+			It("negotiates the protocol", func() {
+				hello, err := client.Do(ctx, "hello").Result()
+				Expect(err).NotTo(HaveOccurred())
+				if protocol == 3 {
+					Expect(hello).To(HaveKeyWithValue("proto", int64(3)))
+				} else {
+					Expect(hello).To(ContainElement(int64(2)))
+				}
+			})
+
+			It("supports strings", func() {
+				Expect(client.Set(ctx, "key", "hello", 0).Err()).NotTo(HaveOccurred())
+				Expect(client.Get(ctx, "key").Val()).To(Equal("hello"))
+				Expect(client.Get(ctx, "missing").Err()).To(Equal(redis.Nil))
+				Expect(client.SetNX(ctx, "key", "world", 0).Val()).To(BeFalse())
+				Expect(client.Append(ctx, "key", " world").Val()).To(Equal(int64(11)))
+				Expect(client.GetRange(ctx, "key", 0, 4).Val()).To(Equal("hello"))
+
+				Expect(client.Incr(ctx, "n").Val()).To(Equal(int64(1)))
+				Expect(client.IncrBy(ctx, "n", 10).Val()).To(Equal(int64(11)))
+				Expect(client.DecrBy(ctx, "n", 2).Val()).To(Equal(int64(9)))
+				Expect(client.IncrByFloat(ctx, "n", 0.5).Val()).To(Equal(9.5))
+				Expect(client.Incr(ctx, "key").Err()).To(MatchError("ERR value is not an integer or out of range"))
+
+				Expect(client.MSet(ctx, "a", "1", "b", "2").Err()).NotTo(HaveOccurred())
+				Expect(client.MGet(ctx, "a", "b", "c").Val()).To(Equal([]interface{}{"1", "2", nil}))
+
+				prev, err := client.SetArgs(ctx, "a", "3", redis.SetArgs{Get: true}).Result()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(prev).To(Equal("1"))
+			})
+
+			It("supports hashes", func() {
+				Expect(client.HSet(ctx, "hash", "a", "1", "b", "2").Val()).To(Equal(int64(2)))
+				Expect(client.HGet(ctx, "hash", "a").Val()).To(Equal("1"))
+				Expect(client.HGetAll(ctx, "hash").Val()).To(Equal(map[string]string{"a": "1", "b": "2"}))
+				Expect(client.HIncrBy(ctx, "hash", "a", 2).Val()).To(Equal(int64(3)))
+				Expect(client.HMGet(ctx, "hash", "a", "c").Val()).To(Equal([]interface{}{"3", nil}))
+				Expect(client.HKeys(ctx, "hash").Val()).To(Equal([]string{"a", "b"}))
+				Expect(client.HDel(ctx, "hash", "a", "b").Val()).To(Equal(int64(2)))
+				Expect(client.Exists(ctx, "hash").Val()).To(Equal(int64(0)))
+
+				Expect(client.Set(ctx, "key", "value", 0).Err()).NotTo(HaveOccurred())
+				Expect(client.HGet(ctx, "key", "a").Err()).To(MatchError(ContainSubstring("WRONGTYPE")))
+			})
+
+			It("supports lists", func() {
+				Expect(client.RPush(ctx, "list", "b", "c").Val()).To(Equal(int64(2)))
+				Expect(client.LPush(ctx, "list", "a").Val()).To(Equal(int64(3)))
+				Expect(client.LRange(ctx, "list", 0, -1).Val()).To(Equal([]string{"a", "b", "c"}))
+				Expect(client.LIndex(ctx, "list", -1).Val()).To(Equal("c"))
+				Expect(client.LPop(ctx, "list").Val()).To(Equal("a"))
+				Expect(client.RPopCount(ctx, "list", 5).Val()).To(Equal([]string{"c", "b"}))
+				Expect(client.LLen(ctx, "list").Val()).To(Equal(int64(0)))
+			})
+
+			It("supports blocking pops", func() {
+				go func() {
+					defer GinkgoRecover()
+
+					time.Sleep(50 * time.Millisecond)
+					Expect(client.RPush(ctx, "list", "a").Err()).NotTo(HaveOccurred())
+				}()
+
+				Expect(client.BLPop(ctx, time.Second, "list").Val()).To(Equal([]string{"list", "a"}))
+				Expect(client.BLPop(ctx, time.Second, "list").Err()).To(Equal(redis.Nil))
+			})
+
+			It("supports sets", func() {
+				Expect(client.SAdd(ctx, "set1", "a", "b", "c").Val()).To(Equal(int64(3)))
+				Expect(client.SAdd(ctx, "set2", "b", "c", "d").Val()).To(Equal(int64(3)))
+				Expect(client.SIsMember(ctx, "set1", "a").Val()).To(BeTrue())
+				Expect(client.SMembers(ctx, "set1").Val()).To(ConsistOf("a", "b", "c"))
+				Expect(client.SInter(ctx, "set1", "set2").Val()).To(ConsistOf("b", "c"))
+				Expect(client.SUnion(ctx, "set1", "set2").Val()).To(ConsistOf("a", "b", "c", "d"))
+				Expect(client.SDiff(ctx, "set1", "set2").Val()).To(ConsistOf("a"))
+				Expect(client.SRem(ctx, "set1", "a").Val()).To(Equal(int64(1)))
+				Expect(client.SCard(ctx, "set1").Val()).To(Equal(int64(2)))
+			})
+
+			It("supports sorted sets", func() {
+				Expect(client.ZAdd(ctx, "zset",
+					redis.Z{Score: 1, Member: "a"},
+					redis.Z{Score: 2, Member: "b"},
+					redis.Z{Score: 3, Member: "c"},
+				).Val()).To(Equal(int64(3)))
+				Expect(client.ZScore(ctx, "zset", "b").Val()).To(Equal(float64(2)))
+				Expect(client.ZIncrBy(ctx, "zset", 10, "a").Val()).To(Equal(float64(11)))
+				Expect(client.ZRange(ctx, "zset", 0, -1).Val()).To(Equal([]string{"b", "c", "a"}))
+				Expect(client.ZRangeWithScores(ctx, "zset", 0, 0).Val()).To(Equal([]redis.Z{{Score: 2, Member: "b"}}))
+				Expect(client.ZRevRange(ctx, "zset", 0, 0).Val()).To(Equal([]string{"a"}))
+				Expect(client.ZRangeByScore(ctx, "zset", &redis.ZRangeBy{Min: "(2", Max: "+inf"}).Val()).
+					To(Equal([]string{"c", "a"}))
+				Expect(client.ZRank(ctx, "zset", "c").Val()).To(Equal(int64(1)))
+				Expect(client.ZPopMin(ctx, "zset").Val()).To(Equal([]redis.Z{{Score: 2, Member: "b"}}))
+				Expect(client.ZCard(ctx, "zset").Val()).To(Equal(int64(2)))
+			})
+
+			It("expires keys", func() {
+				Expect(client.Set(ctx, "key", "value", 50*time.Millisecond).Err()).NotTo(HaveOccurred())
+				Expect(client.PTTL(ctx, "key").Val()).To(BeNumerically(">", 0))
+				Expect(client.Set(ctx, "persistent", "value", 0).Err()).NotTo(HaveOccurred())
+				Expect(client.TTL(ctx, "persistent").Val()).To(Equal(time.Duration(-1)))
+
+				Eventually(func() error {
+					return client.Get(ctx, "key").Err()
+				}).Should(Equal(redis.Nil))
+				Expect(client.Keys(ctx, "*").Val()).To(Equal([]string{"persistent"}))
+			})
+
+			It("supports pipelines and transactions", func() {
+				cmds, err := client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+					pipe.Incr(ctx, "n")
+					pipe.Incr(ctx, "n")
+					return nil
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cmds).To(HaveLen(2))
+				Expect(cmds[1].(*redis.IntCmd).Val()).To(Equal(int64(2)))
+
+				cmds, err = client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+					pipe.Get(ctx, "n")
+					pipe.Get(ctx, "missing")
+					return nil
+				})
+				Expect(err).To(Equal(redis.Nil))
+				Expect(cmds[0].(*redis.StringCmd).Val()).To(Equal("2"))
+			})
+
+			It("aborts transactions on watched keys", func() {
+				err := client.Watch(ctx, func(tx *redis.Tx) error {
+					Expect(client.Set(ctx, "key", "modified", 0).Err()).NotTo(HaveOccurred())
+					_, err := tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+						pipe.Set(ctx, "key", "tx", 0)
+						return nil
+					})
+					return err
+				}, "key")
+				Expect(err).To(Equal(redis.TxFailedErr))
+				Expect(client.Get(ctx, "key").Val()).To(Equal("modified"))
+			})
+
+			It("publishes messages", func() {
+				pubsub := client.Subscribe(ctx, "chan")
+				defer pubsub.Close()
+				psub := client.PSubscribe(ctx, "ch*")
+				defer psub.Close()
+
+				_, err := pubsub.Receive(ctx)
+				Expect(err).NotTo(HaveOccurred())
+				_, err = psub.Receive(ctx)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(client.Publish(ctx, "chan", "hello").Val()).To(Equal(int64(2)))
+
+				msg, err := pubsub.ReceiveMessage(ctx)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(msg.Channel).To(Equal("chan"))
+				Expect(msg.Payload).To(Equal("hello"))
+
+				msg, err = psub.ReceiveMessage(ctx)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(msg.Pattern).To(Equal("ch*"))
+				Expect(msg.Payload).To(Equal("hello"))
+
+				Expect(pubsub.Ping(ctx)).NotTo(HaveOccurred())
+			})
+		})
+	}
+
+	It("keeps databases apart", func() {
+		db0 := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+		defer db0.Close()
+		db1 := redis.NewClient(&redis.Options{Addr: srv.Addr(), DB: 1})
+		defer db1.Close()
+
+		Expect(db0.Set(ctx, "key", "0", 0).Err()).NotTo(HaveOccurred())
+		Expect(db1.Get(ctx, "key").Err()).To(Equal(redis.Nil))
+		Expect(db1.DBSize(ctx).Val()).To(Equal(int64(0)))
+
+		srv.FlushAll()
+		Expect(db0.Get(ctx, "key").Err()).To(Equal(redis.Nil))
+	})
+
+	It("lists and pauses clients", func() {
+		client := redis.NewClient(&redis.Options{Addr: srv.Addr(), ClientName: "paused"})
+		defer client.Close()
+
+		Expect(client.ClientList(ctx).Val()).To(ContainSubstring("name=paused db=0"))
+
+		Expect(client.ClientPause(ctx, 100*time.Millisecond).Err()).NotTo(HaveOccurred())
+		start := time.Now()
+		Expect(client.Ping(ctx).Err()).NotTo(HaveOccurred())
+		Expect(time.Since(start)).To(BeNumerically(">=", 50*time.Millisecond))
+	})
+
+	It("serves a Ring", func() {
+		other, err := redistest.Start()
+		Expect(err).NotTo(HaveOccurred())
+		defer other.Close()
+
+		ring := redis.NewRing(&redis.RingOptions{
+			Addrs: map[string]string{
+				"shard1": srv.Addr(),
+				"shard2": other.Addr(),
+			},
+		})
+		defer ring.Close()
+
+		for i := 0; i < 100; i++ {
+			key := "key" + strconv.Itoa(i)
+			Expect(ring.Set(ctx, key, i, 0).Err()).NotTo(HaveOccurred())
+		}
+
+		var keys int64
+		Expect(ring.ForEachShard(ctx, func(ctx context.Context, shard *redis.Client) error {
+			n, err := shard.DBSize(ctx).Result()
+			Expect(n).To(BeNumerically(">", 0))
+			atomic.AddInt64(&keys, n)
+			return err
+		})).NotTo(HaveOccurred())
+		Expect(keys).To(Equal(int64(100)))
+	})
+})
@@ -0,0 +1,231 @@
+package redistest
+
+import (
+	"sort"
+)
+
+func (s set) members() []string {
+	members := make([]string, 0, len(s))
+	for member := range s {
+		members = append(members, member)
+	}
+	sort.Strings(members)
+	return members
+}
+
+func (w *writer) set(s set) {
+	members := s.members()
+	w.setLen(len(members))
+	for _, member := range members {
+		w.bulk(member)
+	}
+}
+
+func saddCmd(c *client, args []string) {
+	d := c.currentDB()
+	s, err := d.set(args[1], true)
+	if err != nil {
+		c.w.error(err.Error())
+		return
+	}
+
+	var n int64
+	for _, member := range args[2:] {
+		if _, ok := s[member]; !ok {
+			s[member] = struct{}{}
+			n++
+		}
+	}
+	d.modified(args[1])
+	c.w.int(n)
+}
+
+func sremCmd(c *client, args []string) {
+	d := c.currentDB()
+	s, err := d.set(args[1], false)
+	if err != nil {
+		c.w.error(err.Error())
+		return
+	}
+
+	var n int64
+	for _, member := range args[2:] {
+		if _, ok := s[member]; ok {
+			delete(s, member)
+			n++
+		}
+	}
+	if n > 0 {
+		d.modified(args[1])
+	}
+	c.w.int(n)
+}
+
+func smembersCmd(c *client, args []string) {
+	s, err := c.currentDB().set(args[1], false)
+	if err != nil {
+		c.w.error(err.Error())
+		return
+	}
+	c.w.set(s)
+}
+
+func sismemberCmd(c *client, args []string) {
+	s, err := c.currentDB().set(args[1], false)
+	if err != nil {
+		c.w.error(err.Error())
+		return
+	}
+
+	_, ok := s[args[2]]
+	c.w.bool(ok)
+}
+
+func smismemberCmd(c *client, args []string) {
+	s, err := c.currentDB().set(args[1], false)
+	if err != nil {
+		c.w.error(err.Error())
+		return
+	}
+
+	c.w.array(len(args) - 2)
+	for _, member := range args[2:] {
+		_, ok := s[member]
+		c.w.bool(ok)
+	}
+}
+
+func scardCmd(c *client, args []string) {
+	s, err := c.currentDB().set(args[1], false)
+	if err != nil {
+		c.w.error(err.Error())
+		return
+	}
+	c.w.int(int64(len(s)))
+}
+
+func spopCmd(c *client, args []string) {
+	if len(args) > 3 {
+		c.w.error(msgSyntax)
+		return
+	}
+
+	count := int64(-1)
+	if len(args) == 3 {
+		n, ok := parseInt(args[2])
+		if !ok || n < 0 {
+			c.w.error("ERR value is out of range, must be positive")
+			return
+		}
+		count = n
+	}
+
+	d := c.currentDB()
+	s, err := d.set(args[1], false)
+	if err != nil {
+		c.w.error(err.Error())
+		return
+	}
+
+	// Map iteration order is random enough for tests.
+	var popped []string
+	for member := range s {
+		if count < 0 && len(popped) == 1 || count >= 0 && int64(len(popped)) == count {
+			break
+		}
+		popped = append(popped, member)
+		delete(s, member)
+	}
+	if len(popped) > 0 {
+		d.modified(args[1])
+	}
+
+	switch {
+	case count >= 0:
+		c.w.setLen(len(popped))
+		for _, member := range popped {
+			c.w.bulk(member)
+		}
+	case len(popped) == 0:
+		c.w.null()
+	default:
+		c.w.bulk(popped[0])
+	}
+}
+
+type setOp func(sets []set) set
+
+func setInter(sets []set) set {
+	res := make(set)
+	for member := range sets[0] {
+		in := true
+		for _, s := range sets[1:] {
+			if _, ok := s[member]; !ok {
+				in = false
+				break
+			}
+		}
+		if in {
+			res[member] = struct{}{}
+		}
+	}
+	return res
+}
+
+func setUnion(sets []set) set {
+	res := make(set)
+	for _, s := range sets {
+		for member := range s {
+			res[member] = struct{}{}
+		}
+	}
+	return res
+}
+
+func setDiff(sets []set) set {
+	res := make(set)
+	for member := range sets[0] {
+		res[member] = struct{}{}
+	}
+	for _, s := range sets[1:] {
+		for member := range s {
+			delete(res, member)
+		}
+	}
+	return res
+}
+
+// setOpCmd implements SINTER, SUNION, SDIFF and their STORE variants,
+// which take the destination key first.
+func setOpCmd(op setOp, store bool) func(c *client, args []string) {
+	return func(c *client, args []string) {
+		d := c.currentDB()
+
+		keys := args[1:]
+		if store {
+			keys = args[2:]
+		}
+
+		sets := make([]set, len(keys))
+		for i, key := range keys {
+			s, err := d.set(key, false)
+			if err != nil {
+				c.w.error(err.Error())
+				return
+			}
+			sets[i] = s
+		}
+
+		res := op(sets)
+		if !store {
+			c.w.set(res)
+			return
+		}
+
+		d.del(args[1])
+		if len(res) > 0 {
+			d.put(args[1], res)
+		}
+		c.w.int(int64(len(res)))
+	}
+}
@@ -0,0 +1,568 @@
+package redistest
+
+import (
+	"strings"
+)
+
+// zmembers writes members, with their scores if withScores is set:
+// a flat array in RESP2, an array of pairs in RESP3.
+func (w *writer) zmembers(ms []zmember, withScores bool) {
+	switch {
+	case !withScores:
+		w.array(len(ms))
+		for _, m := range ms {
+			w.bulk(m.member)
+		}
+	case w.resp3:
+		w.array(len(ms))
+		for _, m := range ms {
+			w.array(2)
+			w.bulk(m.member)
+			w.float(m.score)
+		}
+	default:
+		w.array(2 * len(ms))
+		for _, m := range ms {
+			w.bulk(m.member)
+			w.float(m.score)
+		}
+	}
+}
+
+type scoreBound struct {
+	score     float64
+	exclusive bool
+}
+
+func parseScoreBound(s string) (scoreBound, bool) {
+	var b scoreBound
+	if strings.HasPrefix(s, "(") {
+		b.exclusive = true
+		s = s[1:]
+	}
+	var ok bool
+	b.score, ok = parseFloat(s)
+	return b, ok
+}
+
+func inScoreRange(score float64, min, max scoreBound) bool {
+	if min.exclusive && score <= min.score || !min.exclusive && score < min.score {
+		return false
+	}
+	if max.exclusive && score >= max.score || !max.exclusive && score > max.score {
+		return false
+	}
+	return true
+}
+
+func zaddCmd(c *client, args []string) {
+	var nx, xx, gt, lt, ch, incr bool
+	i := 2
+loop:
+	for ; i < len(args); i++ {
+		switch strings.ToLower(args[i]) {
+		case "nx":
+			nx = true
+		case "xx":
+			xx = true
+		case "gt":
+			gt = true
+		case "lt":
+			lt = true
+		case "ch":
+			ch = true
+		case "incr":
+			incr = true
+		default:
+			break loop
+		}
+	}
+
+	pairs := args[i:]
+	if len(pairs) == 0 || len(pairs)%2 != 0 {
+		c.w.error(msgSyntax)
+		return
+	}
+	if nx && xx {
+		c.w.error("ERR XX and NX options at the same time are not compatible")
+		return
+	}
+	if gt && lt || nx && (gt || lt) {
+		c.w.error("ERR GT, LT, and/or NX options at the same time are not compatible")
+		return
+	}
+	if incr && len(pairs) != 2 {
+		c.w.error("ERR INCR option supports a single increment-element pair")
+		return
+	}
+
+	scores := make([]float64, len(pairs)/2)
+	for i := range scores {
+		score, ok := parseFloat(pairs[2*i])
+		if !ok {
+			c.w.error(msgNotFloat)
+			return
+		}
+		scores[i] = score
+	}
+
+	d := c.currentDB()
+	z, err := d.zset(args[1], !xx)
+	if err != nil {
+		c.w.error(err.Error())
+		return
+	}
+	if z == nil {
+		if incr {
+			c.w.null()
+		} else {
+			c.w.int(0)
+		}
+		return
+	}
+
+	var added, changed int64
+	var updated bool
+	var score float64
+	for i, newScore := range scores {
+		member := pairs[2*i+1]
+		old, exists := z.scores[member]
+		if incr {
+			newScore += old
+		}
+		score = newScore
+
+		switch {
+		case nx && exists, xx && !exists,
+			exists && gt && newScore <= old,
+			exists && lt && newScore >= old:
+			continue
+		}
+
+		updated = true
+		z.scores[member] = newScore
+		if !exists {
+			added++
+		} else if newScore != old {
+			changed++
+		}
+	}
+	d.modified(args[1])
+
+	switch {
+	case incr && !updated:
+		c.w.null()
+	case incr:
+		c.w.float(score)
+	case ch:
+		c.w.int(added + changed)
+	default:
+		c.w.int(added)
+	}
+}
+
+func zincrbyCmd(c *client, args []string) {
+	delta, ok := parseFloat(args[2])
+	if !ok {
+		c.w.error(msgNotFloat)
+		return
+	}
+
+	d := c.currentDB()
+	z, err := d.zset(args[1], true)
+	if err != nil {
+		c.w.error(err.Error())
+		return
+	}
+
+	z.scores[args[3]] += delta
+	d.modified(args[1])
+	c.w.float(z.scores[args[3]])
+}
+
+func zscoreCmd(c *client, args []string) {
+	z, err := c.currentDB().zset(args[1], false)
+	if err != nil {
+		c.w.error(err.Error())
+		return
+	}
+
+	if z == nil {
+		c.w.null()
+		return
+	}
+	if score, ok := z.scores[args[2]]; ok {
+		c.w.float(score)
+	} else {
+		c.w.null()
+	}
+}
+
+func zmscoreCmd(c *client, args []string) {
+	z, err := c.currentDB().zset(args[1], false)
+	if err != nil {
+		c.w.error(err.Error())
+		return
+	}
+
+	c.w.array(len(args) - 2)
+	for _, member := range args[2:] {
+		if z == nil {
+			c.w.null()
+		} else if score, ok := z.scores[member]; ok {
+			c.w.float(score)
+		} else {
+			c.w.null()
+		}
+	}
+}
+
+func zcardCmd(c *client, args []string) {
+	z, err := c.currentDB().zset(args[1], false)
+	if err != nil {
+		c.w.error(err.Error())
+		return
+	}
+	if z == nil {
+		c.w.int(0)
+		return
+	}
+	c.w.int(int64(len(z.scores)))
+}
+
+func zremCmd(c *client, args []string) {
+	d := c.currentDB()
+	z, err := d.zset(args[1], false)
+	if err != nil {
+		c.w.error(err.Error())
+		return
+	}
+	if z == nil {
+		c.w.int(0)
+		return
+	}
+
+	var n int64
+	for _, member := range args[2:] {
+		if _, ok := z.scores[member]; ok {
+			delete(z.scores, member)
+			n++
+		}
+	}
+	if n > 0 {
+		d.modified(args[1])
+	}
+	c.w.int(n)
+}
+
+func zcountCmd(c *client, args []string) {
+	min, ok1 := parseScoreBound(args[2])
+	max, ok2 := parseScoreBound(args[3])
+	if !ok1 || !ok2 {
+		c.w.error("ERR min or max is not a float")
+		return
+	}
+
+	z, err := c.currentDB().zset(args[1], false)
+	if err != nil {
+		c.w.error(err.Error())
+		return
+	}
+	if z == nil {
+		c.w.int(0)
+		return
+	}
+
+	var n int64
+	for _, score := range z.scores {
+		if inScoreRange(score, min, max) {
+			n++
+		}
+	}
+	c.w.int(n)
+}
+
+func zrankCmd(rev bool) func(c *client, args []string) {
+	return func(c *client, args []string) {
+		z, err := c.currentDB().zset(args[1], false)
+		if err != nil {
+			c.w.error(err.Error())
+			return
+		}
+		if z == nil {
+			c.w.null()
+			return
+		}
+
+		ms := z.sorted()
+		for i, m := range ms {
+			if m.member != args[2] {
+				continue
+			}
+			if rev {
+				i = len(ms) - 1 - i
+			}
+			c.w.int(int64(i))
+			return
+		}
+		c.w.null()
+	}
+}
+
+type zrangeArgs struct {
+	byScore    bool
+	rev        bool
+	withScores bool
+	limit      bool
+	offset     int64
+	count      int64
+}
+
+func (a *zrangeArgs) parse(opts []string) bool {
+	for i := 0; i < len(opts); i++ {
+		switch strings.ToLower(opts[i]) {
+		case "byscore":
+			a.byScore = true
+		case "rev":
+			a.rev = true
+		case "withscores":
+			a.withScores = true
+		case "limit":
+			if i+2 >= len(opts) {
+				return false
+			}
+			var ok1, ok2 bool
+			a.offset, ok1 = parseInt(opts[i+1])
+			a.count, ok2 = parseInt(opts[i+2])
+			if !ok1 || !ok2 {
+				return false
+			}
+			a.limit = true
+			i += 2
+		default:
+			return false
+		}
+	}
+	return !a.limit || a.byScore
+}
+
+// zrange writes the members of the sorted set of key between start and stop,
+// which are indexes or scores depending on a.
+func zrange(c *client, key, start, stop string, a *zrangeArgs) {
+	var min, max scoreBound
+	var lo, hi int64
+	if a.byScore {
+		var ok1, ok2 bool
+		min, ok1 = parseScoreBound(start)
+		max, ok2 = parseScoreBound(stop)
+		if !ok1 || !ok2 {
+			c.w.error("ERR min or max is not a float")
+			return
+		}
+		if a.rev {
+			min, max = max, min
+		}
+	} else {
+		var ok1, ok2 bool
+		lo, ok1 = parseInt(start)
+		hi, ok2 = parseInt(stop)
+		if !ok1 || !ok2 {
+			c.w.error(msgNotInteger)
+			return
+		}
+	}
+
+	z, err := c.currentDB().zset(key, false)
+	if err != nil {
+		c.w.error(err.Error())
+		return
+	}
+	if z == nil {
+		c.w.array(0)
+		return
+	}
+
+	ms := z.sorted()
+	if a.rev {
+		for i, j := 0, len(ms)-1; i < j; i, j = i+1, j-1 {
+			ms[i], ms[j] = ms[j], ms[i]
+		}
+	}
+
+	if !a.byScore {
+		i, j := normalizeRange(lo, hi, len(ms))
+		c.w.zmembers(ms[i:j], a.withScores)
+		return
+	}
+
+	var res []zmember
+	for _, m := range ms {
+		if inScoreRange(m.score, min, max) {
+			res = append(res, m)
+		}
+	}
+	if a.limit {
+		if a.offset < 0 || a.offset >= int64(len(res)) {
+			res = nil
+		} else {
+			res = res[a.offset:]
+			if a.count >= 0 && a.count < int64(len(res)) {
+				res = res[:a.count]
+			}
+		}
+	}
+	c.w.zmembers(res, a.withScores)
+}
+
+func zrangeCmd(c *client, args []string) {
+	var a zrangeArgs
+	if !a.parse(args[4:]) {
+		c.w.error(msgSyntax)
+		return
+	}
+	zrange(c, args[1], args[2], args[3], &a)
+}
+
+func zrevrangeCmd(c *client, args []string) {
+	a := zrangeArgs{rev: true}
+	if len(args) > 5 || len(args) == 5 && !strings.EqualFold(args[4], "withscores") {
+		c.w.error(msgSyntax)
+		return
+	}
+	a.withScores = len(args) == 5
+	zrange(c, args[1], args[2], args[3], &a)
+}
+
+// zrangebyscoreCmd implements ZRANGEBYSCORE and ZREVRANGEBYSCORE,
+// the latter takes max before min.
+func zrangebyscoreCmd(rev bool) func(c *client, args []string) {
+	return func(c *client, args []string) {
+		a := zrangeArgs{byScore: true, rev: rev}
+		for _, opt := range args[4:] {
+			if strings.EqualFold(opt, "byscore") || strings.EqualFold(opt, "rev") {
+				c.w.error(msgSyntax)
+				return
+			}
+		}
+		if !a.parse(args[4:]) {
+			c.w.error(msgSyntax)
+			return
+		}
+		zrange(c, args[1], args[2], args[3], &a)
+	}
+}
+
+func zpopCmd(max bool) func(c *client, args []string) {
+	return func(c *client, args []string) {
+		if len(args) > 3 {
+			c.w.error(msgSyntax)
+			return
+		}
+
+		count := int64(1)
+		if len(args) == 3 {
+			n, ok := parseInt(args[2])
+			if !ok || n < 0 {
+				c.w.error("ERR value is out of range, must be positive")
+				return
+			}
+			count = n
+		}
+
+		d := c.currentDB()
+		z, err := d.zset(args[1], false)
+		if err != nil {
+			c.w.error(err.Error())
+			return
+		}
+		if z == nil {
+			c.w.array(0)
+			return
+		}
+
+		ms := z.sorted()
+		if max {
+			for i, j := 0, len(ms)-1; i < j; i, j = i+1, j-1 {
+				ms[i], ms[j] = ms[j], ms[i]
+			}
+		}
+		if count < int64(len(ms)) {
+			ms = ms[:count]
+		}
+		for _, m := range ms {
+			delete(z.scores, m.member)
+		}
+		d.modified(args[1])
+
+		// Without count RESP3 replies with a single flat pair.
+		if len(args) == 2 && c.w.resp3 && len(ms) == 1 {
+			c.w.array(2)
+			c.w.bulk(ms[0].member)
+			c.w.float(ms[0].score)
+			return
+		}
+		c.w.zmembers(ms, true)
+	}
+}
+
+func zremrangebyrankCmd(c *client, args []string) {
+	start, ok1 := parseInt(args[2])
+	stop, ok2 := parseInt(args[3])
+	if !ok1 || !ok2 {
+		c.w.error(msgNotInteger)
+		return
+	}
+
+	d := c.currentDB()
+	z, err := d.zset(args[1], false)
+	if err != nil {
+		c.w.error(err.Error())
+		return
+	}
+	if z == nil {
+		c.w.int(0)
+		return
+	}
+
+	ms := z.sorted()
+	lo, hi := normalizeRange(start, stop, len(ms))
+	for _, m := range ms[lo:hi] {
+		delete(z.scores, m.member)
+	}
+	if hi > lo {
+		d.modified(args[1])
+	}
+	c.w.int(int64(hi - lo))
+}
+
+func zremrangebyscoreCmd(c *client, args []string) {
+	min, ok1 := parseScoreBound(args[2])
+	max, ok2 := parseScoreBound(args[3])
+	if !ok1 || !ok2 {
+		c.w.error("ERR min or max is not a float")
+		return
+	}
+
+	d := c.currentDB()
+	z, err := d.zset(args[1], false)
+	if err != nil {
+		c.w.error(err.Error())
+		return
+	}
+	if z == nil {
+		c.w.int(0)
+		return
+	}
+
+	var n int64
+	for member, score := range z.scores {
+		if inScoreRange(score, min, max) {
+			delete(z.scores, member)
+			n++
+		}
+	}
+	if n > 0 {
+		d.modified(args[1])
+	}
+	c.w.int(n)
+}
@@ -0,0 +1,328 @@
+package redistest
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// setStr sets the string value of key, keeping its TTL.
+func (d *db) setStr(key, val string) {
+	if e := d.lookup(key); e != nil {
+		e.val = val
+		d.touch(key)
+		return
+	}
+	d.put(key, val)
+}
+
+func getCmd(c *client, args []string) {
+	s, ok, err := c.currentDB().str(args[1])
+	switch {
+	case err != nil:
+		c.w.error(err.Error())
+	case !ok:
+		c.w.null()
+	default:
+		c.w.bulk(s)
+	}
+}
+
+func getdelCmd(c *client, args []string) {
+	d := c.currentDB()
+	s, ok, err := d.str(args[1])
+	switch {
+	case err != nil:
+		c.w.error(err.Error())
+	case !ok:
+		c.w.null()
+	default:
+		d.del(args[1])
+		c.w.bulk(s)
+	}
+}
+
+func getsetCmd(c *client, args []string) {
+	d := c.currentDB()
+	s, ok, err := d.str(args[1])
+	if err != nil {
+		c.w.error(err.Error())
+		return
+	}
+
+	d.put(args[1], args[2])
+	if ok {
+		c.w.bulk(s)
+	} else {
+		c.w.null()
+	}
+}
+
+func getrangeCmd(c *client, args []string) {
+	start, ok1 := parseInt(args[2])
+	stop, ok2 := parseInt(args[3])
+	if !ok1 || !ok2 {
+		c.w.error(msgNotInteger)
+		return
+	}
+
+	s, _, err := c.currentDB().str(args[1])
+	if err != nil {
+		c.w.error(err.Error())
+		return
+	}
+
+	lo, hi := normalizeRange(start, stop, len(s))
+	c.w.bulk(s[lo:hi])
+}
+
+func setCmd(c *client, args []string) {
+	var nx, xx, get, keepTTL bool
+	var expireAt time.Time
+	for i := 3; i < len(args); i++ {
+		switch opt := strings.ToLower(args[i]); opt {
+		case "nx":
+			nx = true
+		case "xx":
+			xx = true
+		case "get":
+			get = true
+		case "keepttl":
+			keepTTL = true
+		case "ex", "px", "exat", "pxat":
+			if i+1 >= len(args) || keepTTL || !expireAt.IsZero() {
+				c.w.error(msgSyntax)
+				return
+			}
+			i++
+			n, ok := parseInt(args[i])
+			if !ok {
+				c.w.error(msgNotInteger)
+				return
+			}
+			if n <= 0 {
+				c.w.error("ERR invalid expire time in 'set' command")
+				return
+			}
+			switch opt {
+			case "ex":
+				expireAt = time.Now().Add(time.Duration(n) * time.Second)
+			case "px":
+				expireAt = time.Now().Add(time.Duration(n) * time.Millisecond)
+			case "exat":
+				expireAt = time.Unix(n, 0)
+			case "pxat":
+				expireAt = time.UnixMilli(n)
+			}
+		default:
+			c.w.error(msgSyntax)
+			return
+		}
+	}
+	if nx && xx || keepTTL && !expireAt.IsZero() {
+		c.w.error(msgSyntax)
+		return
+	}
+
+	d := c.currentDB()
+	key := args[1]
+
+	old, hasOld, err := d.str(key)
+	if err != nil && get {
+		c.w.error(err.Error())
+		return
+	}
+
+	e := d.lookup(key)
+	if nx && e != nil || xx && e == nil {
+		if get && hasOld {
+			c.w.bulk(old)
+		} else {
+			c.w.null()
+		}
+		return
+	}
+
+	if keepTTL && e != nil {
+		expireAt = e.expireAt
+	}
+	d.put(key, args[2]).expireAt = expireAt
+
+	switch {
+	case !get:
+		c.w.ok()
+	case hasOld:
+		c.w.bulk(old)
+	default:
+		c.w.null()
+	}
+}
+
+func setexCmd(unit time.Duration) func(c *client, args []string) {
+	return func(c *client, args []string) {
+		n, ok := parseInt(args[2])
+		if !ok {
+			c.w.error(msgNotInteger)
+			return
+		}
+		if n <= 0 {
+			c.w.errorf("ERR invalid expire time in '%s' command", strings.ToLower(args[0]))
+			return
+		}
+
+		c.currentDB().put(args[1], args[3]).expireAt = time.Now().Add(time.Duration(n) * unit)
+		c.w.ok()
+	}
+}
+
+func setnxCmd(c *client, args []string) {
+	d := c.currentDB()
+	if d.lookup(args[1]) != nil {
+		c.w.int(0)
+		return
+	}
+	d.put(args[1], args[2])
+	c.w.int(1)
+}
+
+func mgetCmd(c *client, args []string) {
+	d := c.currentDB()
+	c.w.array(len(args) - 1)
+	for _, key := range args[1:] {
+		if s, ok, err := d.str(key); err == nil && ok {
+			c.w.bulk(s)
+		} else {
+			c.w.null()
+		}
+	}
+}
+
+func msetCmd(nx bool) func(c *client, args []string) {
+	return func(c *client, args []string) {
+		if len(args)%2 != 1 {
+			c.w.errorf("ERR wrong number of arguments for '%s' command", strings.ToLower(args[0]))
+			return
+		}
+
+		d := c.currentDB()
+		if nx {
+			for i := 1; i < len(args); i += 2 {
+				if d.lookup(args[i]) != nil {
+					c.w.int(0)
+					return
+				}
+			}
+		}
+
+		for i := 1; i < len(args); i += 2 {
+			d.put(args[i], args[i+1])
+		}
+
+		if nx {
+			c.w.int(1)
+		} else {
+			c.w.ok()
+		}
+	}
+}
+
+// incrCmd implements INCR, DECR, INCRBY and DECRBY,
+// sign is -1 for the DECR commands.
+func incrCmd(sign int64, by bool) func(c *client, args []string) {
+	return func(c *client, args []string) {
+		delta := sign
+		if by {
+			n, ok := parseInt(args[2])
+			if !ok {
+				c.w.error(msgNotInteger)
+				return
+			}
+			if sign < 0 {
+				if n == math.MinInt64 {
+					c.w.error("ERR decrement would overflow")
+					return
+				}
+				n = -n
+			}
+			delta = n
+		}
+
+		d := c.currentDB()
+		s, ok, err := d.str(args[1])
+		if err != nil {
+			c.w.error(err.Error())
+			return
+		}
+
+		var n int64
+		if ok {
+			if n, ok = parseInt(s); !ok {
+				c.w.error(msgNotInteger)
+				return
+			}
+		}
+		if delta > 0 && n > math.MaxInt64-delta || delta < 0 && n < math.MinInt64-delta {
+			c.w.error("ERR increment or decrement would overflow")
+			return
+		}
+
+		n += delta
+		d.setStr(args[1], strconv.FormatInt(n, 10))
+		c.w.int(n)
+	}
+}
+
+func incrbyfloatCmd(c *client, args []string) {
+	delta, ok := parseFloat(args[2])
+	if !ok {
+		c.w.error(msgNotFloat)
+		return
+	}
+
+	d := c.currentDB()
+	s, ok, err := d.str(args[1])
+	if err != nil {
+		c.w.error(err.Error())
+		return
+	}
+
+	var f float64
+	if ok {
+		if f, ok = parseFloat(s); !ok {
+			c.w.error(msgNotFloat)
+			return
+		}
+	}
+
+	f += delta
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		c.w.error("ERR increment would produce NaN or Infinity")
+		return
+	}
+
+	d.setStr(args[1], formatFloat(f))
+	c.w.bulk(formatFloat(f))
+}
+
+func appendCmd(c *client, args []string) {
+	d := c.currentDB()
+	s, _, err := d.str(args[1])
+	if err != nil {
+		c.w.error(err.Error())
+		return
+	}
+
+	s += args[2]
+	d.setStr(args[1], s)
+	c.w.int(int64(len(s)))
+}
+
+func strlenCmd(c *client, args []string) {
+	s, _, err := c.currentDB().str(args[1])
+	if err != nil {
+		c.w.error(err.Error())
+		return
+	}
+	c.w.int(int64(len(s)))
+}
@@ -0,0 +1,97 @@
+package redistest
+
+func multiCmd(c *client, args []string) {
+	if c.multi {
+		c.w.error("ERR MULTI calls can not be nested")
+		return
+	}
+	c.multi = true
+	c.w.ok()
+}
+
+func discardCmd(c *client, args []string) {
+	if !c.multi {
+		c.w.error("ERR DISCARD without MULTI")
+		return
+	}
+	c.resetMulti()
+	c.w.ok()
+}
+
+func execCmd(c *client, args []string) {
+	if !c.multi {
+		c.w.error("ERR EXEC without MULTI")
+		return
+	}
+
+	queued := c.queued
+	failed := c.multiErr
+	dirty := c.watchedModified()
+	c.resetMulti()
+
+	if failed {
+		c.w.error("EXECABORT Transaction discarded because of previous errors.")
+		return
+	}
+	if dirty {
+		c.w.nullArray()
+		return
+	}
+
+	c.execing = true
+	defer func() {
+		c.execing = false
+	}()
+
+	c.w.array(len(queued))
+	for _, args := range queued {
+		c.exec(args)
+	}
+}
+
+func watchCmd(c *client, args []string) {
+	if c.multi {
+		c.w.error("ERR WATCH inside MULTI is not allowed")
+		return
+	}
+
+	if c.watched == nil {
+		c.watched = make(map[watchKey]uint64)
+	}
+	d := c.currentDB()
+	for _, key := range args[1:] {
+		k := watchKey{db: c.db, key: key}
+		if _, ok := c.watched[k]; ok {
+			continue
+		}
+		d.lookup(key)
+		c.watched[k] = d.versions[key]
+	}
+	c.w.ok()
+}
+
+func unwatchCmd(c *client, args []string) {
+	c.watched = nil
+	c.w.ok()
+}
+
+// watchedModified reports whether a watched key was modified since WATCH.
+func (c *client) watchedModified() bool {
+	for k, version := range c.watched {
+		d := c.srv.dbs[k.db]
+		// Expired keys are modified.
+		d.lookup(k.key)
+		if d.versions[k.key] != version {
+			return true
+		}
+	}
+	return false
+}
+
+// resetMulti ends the transaction and unwatches all the keys.
+func (c *client) resetMulti() {
+	c.multi = false
+	c.queued = nil
+	c.multiErr = false
+	c.watched = nil
+}
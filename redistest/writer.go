@@ -0,0 +1,129 @@
+package redistest
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/redis/go-redis/v9/internal/proto"
+)
+
+// writer writes replies using the protocol negotiated with HELLO.
+// Write errors are returned by the next flush of bw.
+type writer struct {
+	bw    *bufio.Writer
+	resp3 bool
+}
+
+func (w *writer) line(typ byte, s string) {
+	_ = w.bw.WriteByte(typ)
+	_, _ = w.bw.WriteString(s)
+	_, _ = w.bw.WriteString("\r\n")
+}
+
+func (w *writer) status(s string) {
+	w.line(proto.RespStatus, s)
+}
+
+func (w *writer) ok() {
+	w.status("OK")
+}
+
+func (w *writer) error(msg string) {
+	w.line(proto.RespError, msg)
+}
+
+func (w *writer) errorf(format string, args ...interface{}) {
+	w.error(fmt.Sprintf(format, args...))
+}
+
+func (w *writer) int(n int64) {
+	w.line(proto.RespInt, strconv.FormatInt(n, 10))
+}
+
+func (w *writer) bool(b bool) {
+	if b {
+		w.int(1)
+	} else {
+		w.int(0)
+	}
+}
+
+func (w *writer) bulk(s string) {
+	w.line(proto.RespString, strconv.Itoa(len(s)))
+	_, _ = w.bw.WriteString(s)
+	_, _ = w.bw.WriteString("\r\n")
+}
+
+func (w *writer) bulks(ss []string) {
+	w.array(len(ss))
+	for _, s := range ss {
+		w.bulk(s)
+	}
+}
+
+// null writes a null bulk string in RESP2.
+func (w *writer) null() {
+	if w.resp3 {
+		w.line(proto.RespNil, "")
+	} else {
+		w.line(proto.RespString, "-1")
+	}
+}
+
+// nullArray writes a null array in RESP2.
+func (w *writer) nullArray() {
+	if w.resp3 {
+		w.line(proto.RespNil, "")
+	} else {
+		w.line(proto.RespArray, "-1")
+	}
+}
+
+func (w *writer) float(f float64) {
+	if w.resp3 {
+		w.line(proto.RespFloat, formatFloat(f))
+	} else {
+		w.bulk(formatFloat(f))
+	}
+}
+
+func (w *writer) array(n int) {
+	w.line(proto.RespArray, strconv.Itoa(n))
+}
+
+// mapLen writes the header of a map of n pairs, a flat array in RESP2.
+func (w *writer) mapLen(n int) {
+	if w.resp3 {
+		w.line(proto.RespMap, strconv.Itoa(n))
+	} else {
+		w.array(2 * n)
+	}
+}
+
+func (w *writer) setLen(n int) {
+	if w.resp3 {
+		w.line(proto.RespSet, strconv.Itoa(n))
+	} else {
+		w.array(n)
+	}
+}
+
+func (w *writer) pushLen(n int) {
+	if w.resp3 {
+		w.line(proto.RespPush, strconv.Itoa(n))
+	} else {
+		w.array(n)
+	}
+}
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "inf"
+	case math.IsInf(f, -1):
+		return "-inf"
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,309 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// RepositoryStorage is how a Repository stores its values.
+type RepositoryStorage int
+
+const (
+	// RepositoryHash stores the values as hashes of their fields with a
+	// redis tag, like HSet and MapStringStringCmd.Scan.
+	RepositoryHash RepositoryStorage = iota
+	// RepositoryJSON stores the values as RedisJSON documents marshaled
+	// with the JSONCodec of the client.
+	RepositoryJSON
+)
+
+// RepositoryOptions configures a Repository of Ts.
+type RepositoryOptions[T any] struct {
+	// ID returns the id of a value, which its key is made of. Required.
+	ID func(v *T) string
+
+	Storage RepositoryStorage
+
+	// Prefix of the keys, followed by the ids.
+	// Default is the lowercase name of T followed by a colon, like "user:".
+	Prefix string
+
+	// TTL of the values, reset by each write.
+	// Default is 0, which means the values don't expire.
+	TTL time.Duration
+
+	// Indexes returns the values of the secondary lookups of a value by
+	// index name, see Repository.FindBy. The ids of the values are kept in
+	// a set per index value, whose key is Prefix + "idx:" + name + ":" + value.
+	//
+	// The index sets are updated in the same transaction as the values, so
+	// with a cluster, Prefix must have a hash tag, like "{user}:", to put
+	// all the keys of the repository in one slot.
+	Indexes func(v *T) map[string]string
+
+	// Maximum number of retries of a write when the watched key is
+	// modified concurrently.
+	// Default is 3 retries; -1 (not 0) disables retries.
+	MaxRetries int
+}
+
+// Repository stores Go structs as hashes or RedisJSON documents with a key
+// per id, and looks them up by id or by the values of secondary indexes.
+//
+//	users := redis.NewRepository(rdb, &redis.RepositoryOptions[User]{
+//		ID: func(u *User) string { return u.ID },
+//		Indexes: func(u *User) map[string]string {
+//			return map[string]string{"email": u.Email}
+//		},
+//	})
+//	err := users.Save(ctx, &User{ID: "1", Email: "ann@example.com"})
+type Repository[T any] struct {
+	c   UniversalClient
+	opt RepositoryOptions[T]
+}
+
+// NewRepository returns a Repository of Ts stored with c. It panics when
+// opt.ID is nil or when opt.Prefix is empty and T has no name.
+func NewRepository[T any](c UniversalClient, opt *RepositoryOptions[T]) *Repository[T] {
+	if opt == nil || opt.ID == nil {
+		panic("redis: RepositoryOptions.ID is required")
+	}
+	r := &Repository[T]{c: c, opt: *opt}
+	if r.opt.Prefix == "" {
+		name := reflect.TypeOf((*T)(nil)).Elem().Name()
+		if name == "" {
+			panic("redis: RepositoryOptions.Prefix is required for an unnamed type")
+		}
+		r.opt.Prefix = strings.ToLower(name) + ":"
+	}
+	switch r.opt.MaxRetries {
+	case -1:
+		r.opt.MaxRetries = 0
+	case 0:
+		r.opt.MaxRetries = 3
+	}
+	return r
+}
+
+// Key returns the key of the value id.
+func (r *Repository[T]) Key(id string) string {
+	return r.opt.Prefix + id
+}
+
+func (r *Repository[T]) indexKey(name, value string) string {
+	return r.opt.Prefix + "idx:" + name + ":" + value
+}
+
+// Save stores v under its id, replacing the previous value and moving its
+// id between the index sets whose values changed.
+func (r *Repository[T]) Save(ctx context.Context, v *T) error {
+	key := r.Key(r.opt.ID(v))
+	return r.watch(ctx, key, func(tx *Tx) error {
+		old, err := r.get(ctx, tx, key)
+		if err != nil && err != Nil {
+			return err
+		}
+		return r.write(ctx, tx, key, r.indexes(old), v)
+	})
+}
+
+// Get returns the value id, or Nil when it doesn't exist.
+func (r *Repository[T]) Get(ctx context.Context, id string) (*T, error) {
+	return r.get(ctx, r.c, r.Key(id))
+}
+
+// Exists reports whether the value id exists.
+func (r *Repository[T]) Exists(ctx context.Context, id string) (bool, error) {
+	n, err := r.c.Exists(ctx, r.Key(id)).Result()
+	return n > 0, err
+}
+
+// Delete deletes the value id and removes it from the index sets. It
+// doesn't fail when the value doesn't exist.
+func (r *Repository[T]) Delete(ctx context.Context, id string) error {
+	key := r.Key(id)
+	return r.watch(ctx, key, func(tx *Tx) error {
+		old, err := r.get(ctx, tx, key)
+		if err == Nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe Pipeliner) error {
+			pipe.Del(ctx, key)
+			for name, value := range r.indexes(old) {
+				pipe.SRem(ctx, r.indexKey(name, value), id)
+			}
+			return nil
+		})
+		return err
+	})
+}
+
+// Update reads the value id, passes it to fn and saves it when fn returns
+// no error. The key is watched, and fn is called again with a fresh value
+// when the value is modified concurrently, up to MaxRetries times; the error
+// is TxFailedErr when all the attempts failed. It returns Nil when the value
+// doesn't exist. fn must not change the id of the value.
+func (r *Repository[T]) Update(ctx context.Context, id string, fn func(v *T) error) (*T, error) {
+	key := r.Key(id)
+	var updated *T
+	err := r.watch(ctx, key, func(tx *Tx) error {
+		old, err := r.get(ctx, tx, key)
+		if err != nil {
+			return err
+		}
+		// v is a shallow copy of old, so the old indexes are computed
+		// before fn can change the slices and maps they are read from.
+		oldIndexes := r.indexes(old)
+		v := new(T)
+		*v = *old
+		if err := fn(v); err != nil {
+			return err
+		}
+		if newID := r.opt.ID(v); newID != id {
+			return fmt.Errorf("redis: Update changed the id %q to %q", id, newID)
+		}
+		if err := r.write(ctx, tx, key, oldIndexes, v); err != nil {
+			return err
+		}
+		updated = v
+		return nil
+	})
+	return updated, err
+}
+
+// FindBy returns the values whose index name is value. The ids of the values
+// that expired are removed from the index set.
+func (r *Repository[T]) FindBy(ctx context.Context, name, value string) ([]*T, error) {
+	setKey := r.indexKey(name, value)
+	ids, err := r.c.SMembers(ctx, setKey).Result()
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+
+	var hashes []*MapStringStringCmd
+	var docs []*JSONValueCmd[T]
+	_, err = r.c.Pipelined(ctx, func(pipe Pipeliner) error {
+		for _, id := range ids {
+			switch r.opt.Storage {
+			case RepositoryJSON:
+				docs = append(docs, JSONGetInto[T](ctx, pipe, r.Key(id)))
+			default:
+				hashes = append(hashes, pipe.HGetAll(ctx, r.Key(id)))
+			}
+		}
+		return nil
+	})
+	if err != nil && err != Nil {
+		return nil, err
+	}
+
+	vals := make([]*T, 0, len(ids))
+	var stale []interface{}
+	for i, id := range ids {
+		var v *T
+		var err error
+		if r.opt.Storage == RepositoryJSON {
+			v, err = docValue(docs[i])
+		} else {
+			v, err = hashValue[T](hashes[i])
+		}
+		if err == Nil {
+			stale = append(stale, id)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		vals = append(vals, v)
+	}
+	if len(stale) > 0 {
+		_ = r.c.SRem(ctx, setKey, stale...).Err()
+	}
+	return vals, nil
+}
+
+// watch calls fn in a transaction watching key, retrying when the
+// transaction fails.
+func (r *Repository[T]) watch(ctx context.Context, key string, fn func(tx *Tx) error) error {
+	var err error
+	for attempt := 0; attempt <= r.opt.MaxRetries; attempt++ {
+		err = r.c.Watch(ctx, fn, key)
+		if err != TxFailedErr {
+			return err
+		}
+	}
+	return err
+}
+
+func (r *Repository[T]) get(ctx context.Context, c Processor, key string) (*T, error) {
+	if r.opt.Storage == RepositoryJSON {
+		return docValue(JSONGetInto[T](ctx, c, key))
+	}
+	cmd := NewMapStringStringCmd(ctx, "hgetall", key)
+	_ = c.Process(ctx, cmd)
+	return hashValue[T](cmd)
+}
+
+// write replaces the value old of key by v in a transaction.
+func (r *Repository[T]) write(ctx context.Context, tx *Tx, key string, oldIndexes map[string]string, v *T) error {
+	id := r.opt.ID(v)
+	newIndexes := r.indexes(v)
+
+	_, err := tx.TxPipelined(ctx, func(pipe Pipeliner) error {
+		pipe.Del(ctx, key)
+		switch r.opt.Storage {
+		case RepositoryJSON:
+			JSONSetValue(ctx, pipe, key, "$", v)
+		default:
+			pipe.HSet(ctx, key, v)
+		}
+		if r.opt.TTL > 0 {
+			pipe.PExpire(ctx, key, r.opt.TTL)
+		}
+		for name, value := range oldIndexes {
+			if newIndexes[name] != value {
+				pipe.SRem(ctx, r.indexKey(name, value), id)
+			}
+		}
+		for name, value := range newIndexes {
+			pipe.SAdd(ctx, r.indexKey(name, value), id)
+		}
+		return nil
+	})
+	return err
+}
+
+func (r *Repository[T]) indexes(v *T) map[string]string {
+	if v == nil || r.opt.Indexes == nil {
+		return nil
+	}
+	return r.opt.Indexes(v)
+}
+
+func docValue[T any](cmd *JSONValueCmd[T]) (*T, error) {
+	v, err := cmd.Result()
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func hashValue[T any](cmd *MapStringStringCmd) (*T, error) {
+	if err := cmd.Err(); err != nil {
+		return nil, err
+	}
+	if len(cmd.Val()) == 0 {
+		return nil, Nil
+	}
+	v := new(T)
+	if err := cmd.Scan(v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
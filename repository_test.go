@@ -0,0 +1,232 @@
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/redis/go-redis/v9/redistest"
+)
+
+type Account struct {
+	ID    string `redis:"id"`
+	Email string `redis:"email"`
+	Plan  string `redis:"plan"`
+	Score int    `redis:"score"`
+}
+
+func TestRepository(t *testing.T) {
+	ctx := context.Background()
+
+	srv, err := redistest.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	defer client.Close()
+
+	accounts := redis.NewRepository(client, &redis.RepositoryOptions[Account]{
+		ID:  func(a *Account) string { return a.ID },
+		TTL: time.Hour,
+		Indexes: func(a *Account) map[string]string {
+			return map[string]string{"plan": a.Plan}
+		},
+	})
+	if key := accounts.Key("1"); key != "account:1" {
+		t.Fatalf("got %q", key)
+	}
+
+	for _, a := range []*Account{
+		{ID: "1", Email: "ann@example.com", Plan: "free"},
+		{ID: "2", Email: "bob@example.com", Plan: "free"},
+	} {
+		if err := accounts.Save(ctx, a); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	a, err := accounts.Get(ctx, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Email != "ann@example.com" || a.Plan != "free" {
+		t.Fatalf("got %+v", a)
+	}
+	if ttl := client.TTL(ctx, "account:1").Val(); ttl <= 0 {
+		t.Fatalf("got TTL %v", ttl)
+	}
+	if _, err := accounts.Get(ctx, "3"); err != redis.Nil {
+		t.Fatalf("got %v, wanted redis.Nil", err)
+	}
+	if ok, err := accounts.Exists(ctx, "2"); err != nil || !ok {
+		t.Fatalf("got %v, %v", ok, err)
+	}
+
+	a, err = accounts.Update(ctx, "1", func(a *Account) error {
+		a.Plan = "pro"
+		a.Score++
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Plan != "pro" || a.Score != 1 {
+		t.Fatalf("got %+v", a)
+	}
+	if _, err := accounts.Update(ctx, "3", func(a *Account) error { return nil }); err != redis.Nil {
+		t.Fatalf("got %v, wanted redis.Nil", err)
+	}
+	errStop := errors.New("stop")
+	if _, err := accounts.Update(ctx, "1", func(a *Account) error { return errStop }); err != errStop {
+		t.Fatalf("got %v", err)
+	}
+	if _, err := accounts.Update(ctx, "1", func(a *Account) error {
+		a.ID = "9"
+		return nil
+	}); err == nil {
+		t.Fatal("expected an error for a changed id")
+	}
+
+	findIDs := func(plan string) []string {
+		found, err := accounts.FindBy(ctx, "plan", plan)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []string
+		for _, a := range found {
+			ids = append(ids, a.ID)
+		}
+		sort.Strings(ids)
+		return ids
+	}
+	if ids := findIDs("free"); len(ids) != 1 || ids[0] != "2" {
+		t.Fatalf("got %q", ids)
+	}
+	if ids := findIDs("pro"); len(ids) != 1 || ids[0] != "1" {
+		t.Fatalf("got %q", ids)
+	}
+
+	// An expired value is removed from its index set.
+	if err := client.Del(ctx, "account:2").Err(); err != nil {
+		t.Fatal(err)
+	}
+	if ids := findIDs("free"); len(ids) != 0 {
+		t.Fatalf("got %q", ids)
+	}
+	if n := client.SCard(ctx, "account:idx:plan:free").Val(); n != 0 {
+		t.Fatalf("got %d stale ids", n)
+	}
+
+	if err := accounts.Delete(ctx, "1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := accounts.Delete(ctx, "1"); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := accounts.Exists(ctx, "1"); ok {
+		t.Fatal("expected the account to be deleted")
+	}
+	if n := client.SCard(ctx, "account:idx:plan:pro").Val(); n != 0 {
+		t.Fatalf("got %d ids after Delete", n)
+	}
+}
+
+func TestRepositoryUpdateRetries(t *testing.T) {
+	ctx := context.Background()
+
+	srv, err := redistest.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	defer client.Close()
+
+	accounts := redis.NewRepository(client, &redis.RepositoryOptions[Account]{
+		ID:     func(a *Account) string { return a.ID },
+		Prefix: "acct:",
+	})
+	if err := accounts.Save(ctx, &Account{ID: "1", Plan: "free"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first attempt races with a concurrent write of the key.
+	var calls int
+	a, err := accounts.Update(ctx, "1", func(a *Account) error {
+		calls++
+		if calls == 1 {
+			if err := client.HSet(ctx, "acct:1", "score", 10).Err(); err != nil {
+				return err
+			}
+		}
+		a.Score++
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 || a.Score != 11 {
+		t.Fatalf("got %d calls, %+v", calls, a)
+	}
+}
+
+// tagList is stored in a hash field as a comma-separated list.
+type tagList []string
+
+func (l tagList) MarshalBinary() ([]byte, error) {
+	return []byte(strings.Join(l, ",")), nil
+}
+
+func (l *tagList) ScanRedis(s string) error {
+	*l = strings.Split(s, ",")
+	return nil
+}
+
+type Article struct {
+	ID   string  `redis:"id"`
+	Tags tagList `redis:"tags"`
+}
+
+func TestRepositoryUpdateInPlace(t *testing.T) {
+	ctx := context.Background()
+
+	srv, err := redistest.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	defer client.Close()
+
+	articles := redis.NewRepository(client, &redis.RepositoryOptions[Article]{
+		ID: func(a *Article) string { return a.ID },
+		Indexes: func(a *Article) map[string]string {
+			return map[string]string{"tag": a.Tags[0]}
+		},
+	})
+	if err := articles.Save(ctx, &Article{ID: "1", Tags: tagList{"go", "redis"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// fn changes the slice shared with the old value.
+	if _, err := articles.Update(ctx, "1", func(a *Article) error {
+		a.Tags[0] = "rust"
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if ids := client.SMembers(ctx, "article:idx:tag:go").Val(); len(ids) != 0 {
+		t.Fatalf("got stale ids %q", ids)
+	}
+	if ids := client.SMembers(ctx, "article:idx:tag:rust").Val(); len(ids) != 1 || ids[0] != "1" {
+		t.Fatalf("got %q", ids)
+	}
+}
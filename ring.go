@@ -100,6 +100,9 @@ type RingOptions struct {
 
 	DisableIndentity bool
 	IdentitySuffix   string
+
+	JSONCodec     JSONCodec
+	JSONValidator *JSONValidator
 }
 
 func (opt *RingOptions) init() {
@@ -168,6 +171,9 @@ func (opt *RingOptions) clientOptions() *Options {
 
 		DisableIndentity: opt.DisableIndentity,
 		IdentitySuffix:   opt.IdentitySuffix,
+
+		JSONCodec:     opt.JSONCodec,
+		JSONValidator: opt.JSONValidator,
 	}
 }
 
@@ -503,6 +509,7 @@ type Ring struct {
 	opt               *RingOptions
 	sharding          *ringSharding
 	cmdsInfoCache     *cmdsInfoCache
+	push              *pushHandlers
 	heartbeatCancelFn context.CancelFunc
 }
 
@@ -514,8 +521,13 @@ func NewRing(opt *RingOptions) *Ring {
 	ring := Ring{
 		opt:               opt,
 		sharding:          newRingSharding(opt),
+		push:              new(pushHandlers),
 		heartbeatCancelFn: hbCancel,
 	}
+	ring.sharding.OnNewNode(ring.push.attach)
+	for _, shard := range ring.sharding.List() {
+		ring.push.attach(shard.Client)
+	}
 
 	ring.cmdsInfoCache = newCmdsInfoCache(ring.cmdsInfo)
 	ring.cmdable = ring.Process
@@ -105,7 +105,7 @@ var _ = Describe("Redis Ring", func() {
 		})
 	})
 
-	It("distributes keys", func() {
+	It("distributes keys", Label("NonRedistest"), func() {
 		setRingKeys()
 
 		// Both shards should have some keys now.
@@ -113,7 +113,7 @@ var _ = Describe("Redis Ring", func() {
 		Expect(ringShard2.Info(ctx, "keyspace").Val()).To(ContainSubstring("keys=44"))
 	})
 
-	It("distributes keys when using EVAL", func() {
+	It("distributes keys when using EVAL", Label("NonRedistest"), func() {
 		script := redis.NewScript(`
 			local r = redis.call('SET', KEYS[1], ARGV[1])
 			return r
@@ -130,7 +130,7 @@ var _ = Describe("Redis Ring", func() {
 		Expect(ringShard2.Info(ctx, "keyspace").Val()).To(ContainSubstring("keys=44"))
 	})
 
-	It("uses single shard when one of the shards is down", func() {
+	It("uses single shard when one of the shards is down", Label("NonRedistest"), func() {
 		// Stop ringShard2.
 		Expect(ringShard2.Close()).NotTo(HaveOccurred())
 
@@ -158,7 +158,7 @@ var _ = Describe("Redis Ring", func() {
 		Expect(ringShard2.Info(ctx, "keyspace").Val()).To(ContainSubstring("keys=44"))
 	})
 
-	It("supports hash tags", func() {
+	It("supports hash tags", Label("NonRedistest"), func() {
 		for i := 0; i < 100; i++ {
 			err := ring.Set(ctx, fmt.Sprintf("key%d{tag}", i), "value", 0).Err()
 			Expect(err).NotTo(HaveOccurred())
@@ -243,7 +243,7 @@ var _ = Describe("Redis Ring", func() {
 			}).NotTo(Panic())
 		})
 
-		It("distributes keys", func() {
+		It("distributes keys", Label("NonRedistest"), func() {
 			pipe := ring.Pipeline()
 			for i := 0; i < 100; i++ {
 				err := pipe.Set(ctx, fmt.Sprintf("key%d", i), "value", 0).Err()
@@ -287,7 +287,7 @@ var _ = Describe("Redis Ring", func() {
 			}
 		})
 
-		It("supports hash tags", func() {
+		It("supports hash tags", Label("NonRedistest"), func() {
 			_, err := ring.Pipelined(ctx, func(pipe redis.Pipeliner) error {
 				for i := 0; i < 100; i++ {
 					pipe.Set(ctx, fmt.Sprintf("key%d{tag}", i), "value", 0).Err()
@@ -302,7 +302,7 @@ var _ = Describe("Redis Ring", func() {
 	})
 
 	Describe("new client callback", func() {
-		It("can be initialized with a new client callback", func() {
+		It("can be initialized with a new client callback", Label("NonRedistest"), func() {
 			opts := redisRingOptions()
 			opts.NewClient = func(opt *redis.Options) *redis.Client {
 				opt.Username = "username1"
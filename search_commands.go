@@ -0,0 +1,1822 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/redis/go-redis/v9/internal/proto"
+)
+
+type SearchCmdable interface {
+	FTAggregate(ctx context.Context, index string, query string) *FTAggregateCmd
+	FTAggregateWithArgs(ctx context.Context, index string, query string, options *FTAggregateOptions) *FTAggregateCmd
+	FTAggregateIterator(ctx context.Context, index string, query string, options *FTAggregateOptions) *AggregateIterator
+	FTAliasAdd(ctx context.Context, index string, alias string) *StatusCmd
+	FTAliasDel(ctx context.Context, alias string) *StatusCmd
+	FTAliasUpdate(ctx context.Context, index string, alias string) *StatusCmd
+	FTAlter(ctx context.Context, index string, skipInitialScan bool, schema ...*FieldSchema) *StatusCmd
+	FTCreate(ctx context.Context, index string, options *FTCreateOptions, schema ...*FieldSchema) *StatusCmd
+	FTCursorDel(ctx context.Context, index string, cursorID int64) *StatusCmd
+	FTCursorRead(ctx context.Context, index string, cursorID int64, count int) *FTAggregateCmd
+	FTDictAdd(ctx context.Context, dict string, terms ...interface{}) *IntCmd
+	FTDictDel(ctx context.Context, dict string, terms ...interface{}) *IntCmd
+	FTDictDump(ctx context.Context, dict string) *StringSliceCmd
+	FTDropIndex(ctx context.Context, index string) *StatusCmd
+	FTDropIndexWithArgs(ctx context.Context, index string, options *FTDropIndexOptions) *StatusCmd
+	FTExplain(ctx context.Context, index string, query string) *StringCmd
+	FTExplainWithArgs(ctx context.Context, index string, query string, options *FTExplainOptions) *StringCmd
+	FTInfo(ctx context.Context, index string) *FTInfoCmd
+	FTList(ctx context.Context) *StringSliceCmd
+	FTProfile(ctx context.Context, index string, query string, options *FTProfileOptions) *FTProfileCmd
+	FTSearch(ctx context.Context, index string, query string) *FTSearchCmd
+	FTSearchWithArgs(ctx context.Context, index string, query string, options *FTSearchOptions) *FTSearchCmd
+	FTSearchKNN(ctx context.Context, index string, query *KNNQuery) *KNNSearchCmd
+	FTSpellCheck(ctx context.Context, index string, query string) *FTSpellCheckCmd
+	FTSpellCheckWithArgs(ctx context.Context, index string, query string, options *FTSpellCheckOptions) *FTSpellCheckCmd
+	FTSugAdd(ctx context.Context, key string, term string, score float64) *IntCmd
+	FTSugAddWithArgs(ctx context.Context, key string, term string, score float64, options *FTSugAddOptions) *IntCmd
+	FTSugDel(ctx context.Context, key string, term string) *IntCmd
+	FTSugGet(ctx context.Context, key string, prefix string) *FTSuggestionCmd
+	FTSugGetWithArgs(ctx context.Context, key string, prefix string, options *FTSugGetOptions) *FTSuggestionCmd
+	FTSugLen(ctx context.Context, key string) *IntCmd
+	FTTagVals(ctx context.Context, index string, field string) *StringSliceCmd
+}
+
+type SearchFieldType int
+
+const (
+	SearchFieldTypeInvalid = SearchFieldType(iota)
+	SearchFieldTypeNumeric
+	SearchFieldTypeTag
+	SearchFieldTypeText
+	SearchFieldTypeGeo
+	SearchFieldTypeGeoShape
+	SearchFieldTypeVector
+)
+
+func (t SearchFieldType) String() string {
+	switch t {
+	case SearchFieldTypeInvalid:
+		return ""
+	case SearchFieldTypeNumeric:
+		return "NUMERIC"
+	case SearchFieldTypeTag:
+		return "TAG"
+	case SearchFieldTypeText:
+		return "TEXT"
+	case SearchFieldTypeGeo:
+		return "GEO"
+	case SearchFieldTypeGeoShape:
+		return "GEOSHAPE"
+	case SearchFieldTypeVector:
+		return "VECTOR"
+	default:
+		return "TEXT"
+	}
+}
+
+// FieldSchema is an attribute of the SCHEMA of FT.CREATE and FT.ALTER.
+type FieldSchema struct {
+	FieldName         string
+	As                string
+	FieldType         SearchFieldType
+	Sortable          bool
+	UNF               bool
+	NoStem            bool
+	NoIndex           bool
+	PhoneticMatcher   string
+	Weight            float64
+	Separator         string
+	CaseSensitive     bool
+	WithSuffixtrie    bool
+	IndexEmpty        bool
+	IndexMissing      bool
+	GeoShapeFieldType string
+	VectorArgs        *FTVectorArgs
+}
+
+type FTCreateOptions struct {
+	OnHash          bool
+	OnJSON          bool
+	Prefix          []interface{}
+	Filter          string
+	DefaultLanguage string
+	LanguageField   string
+	Score           float64
+	ScoreField      string
+	PayloadField    string
+	MaxTextFields   bool
+	Temporary       int
+	NoOffsets       bool
+	NoHL            bool
+	NoFields        bool
+	NoFreqs         bool
+	StopWords       []interface{}
+	SkipInitialScan bool
+}
+
+type FTDropIndexOptions struct {
+	DeleteDocs bool
+}
+
+type FTExplainOptions struct {
+	Dialect int
+}
+
+type FTSearchFilter struct {
+	FieldName string
+	Min       interface{}
+	Max       interface{}
+}
+
+type FTSearchGeoFilter struct {
+	FieldName string
+	Longitude float64
+	Latitude  float64
+	Radius    float64
+	Unit      string
+}
+
+type FTSearchReturn struct {
+	FieldName string
+	As        string
+}
+
+type FTSearchSummarize struct {
+	Fields    []string
+	Frags     int
+	Len       int
+	Separator string
+}
+
+type FTSearchHighlight struct {
+	Fields   []string
+	OpenTag  string
+	CloseTag string
+}
+
+type FTSearchSortBy struct {
+	FieldName string
+	Asc       bool
+	Desc      bool
+}
+
+type FTSearchOptions struct {
+	NoContent       bool
+	Verbatim        bool
+	NoStopWords     bool
+	WithScores      bool
+	WithPayloads    bool
+	WithSortKeys    bool
+	Filters         []FTSearchFilter
+	GeoFilter       []FTSearchGeoFilter
+	InKeys          []interface{}
+	InFields        []interface{}
+	Return          []FTSearchReturn
+	Summarize       *FTSearchSummarize
+	Highlight       *FTSearchHighlight
+	Slop            int
+	Timeout         int
+	InOrder         bool
+	Language        string
+	Expander        string
+	Scorer          string
+	Payload         string
+	SortBy          *FTSearchSortBy
+	SortByWithCount bool
+	LimitOffset     int
+	Limit           int
+	// CountOnly sends LIMIT 0 0, so only the total number of results is returned.
+	CountOnly      bool
+	Params         map[string]interface{}
+	DialectVersion int
+}
+
+type SearchAggregator int
+
+const (
+	SearchInvalid = SearchAggregator(iota)
+	SearchAvg
+	SearchSum
+	SearchMin
+	SearchMax
+	SearchCount
+	SearchCountDistinct
+	SearchCountDistinctish
+	SearchStdDev
+	SearchQuantile
+	SearchToList
+	SearchFirstValue
+	SearchRandomSample
+)
+
+func (a SearchAggregator) String() string {
+	switch a {
+	case SearchInvalid:
+		return ""
+	case SearchAvg:
+		return "AVG"
+	case SearchSum:
+		return "SUM"
+	case SearchMin:
+		return "MIN"
+	case SearchMax:
+		return "MAX"
+	case SearchCount:
+		return "COUNT"
+	case SearchCountDistinct:
+		return "COUNT_DISTINCT"
+	case SearchCountDistinctish:
+		return "COUNT_DISTINCTISH"
+	case SearchStdDev:
+		return "STDDEV"
+	case SearchQuantile:
+		return "QUANTILE"
+	case SearchToList:
+		return "TOLIST"
+	case SearchFirstValue:
+		return "FIRST_VALUE"
+	case SearchRandomSample:
+		return "RANDOM_SAMPLE"
+	default:
+		return ""
+	}
+}
+
+type FTAggregateLoad struct {
+	Field string
+	As    string
+}
+
+type FTAggregateReducer struct {
+	Reducer SearchAggregator
+	Args    []interface{}
+	As      string
+}
+
+type FTAggregateGroupBy struct {
+	Fields []interface{}
+	Reduce []FTAggregateReducer
+}
+
+type FTAggregateSortBy struct {
+	FieldName string
+	Asc       bool
+	Desc      bool
+}
+
+type FTAggregateApply struct {
+	Field string
+	As    string
+}
+
+type FTAggregateWithCursor struct {
+	Count   int
+	MaxIdle int
+}
+
+type FTAggregateOptions struct {
+	Verbatim          bool
+	LoadAll           bool
+	Load              []FTAggregateLoad
+	Timeout           int
+	GroupBy           []FTAggregateGroupBy
+	SortBy            []FTAggregateSortBy
+	SortByMax         int
+	Apply             []FTAggregateApply
+	LimitOffset       int
+	Limit             int
+	Filter            string
+	WithCursor        bool
+	WithCursorOptions *FTAggregateWithCursor
+	Params            map[string]interface{}
+	DialectVersion    int
+}
+
+type FTSpellCheckTerms struct {
+	Inclusion  string // INCLUDE or EXCLUDE
+	Dictionary string
+	Terms      []interface{}
+}
+
+type FTSpellCheckOptions struct {
+	Distance int
+	Terms    *FTSpellCheckTerms
+	Dialect  int
+}
+
+type FTProfileOptions struct {
+	Limited bool
+	// Aggregate profiles FT.AGGREGATE with AggregateOptions instead of
+	// FT.SEARCH with SearchOptions.
+	Aggregate        bool
+	SearchOptions    *FTSearchOptions
+	AggregateOptions *FTAggregateOptions
+}
+
+// FTAggregate - Runs a search query on an index and performs aggregate transformations on the results.
+// For more information - https://redis.io/commands/ft.aggregate/
+func (c cmdable) FTAggregate(ctx context.Context, index string, query string) *FTAggregateCmd {
+	args := []interface{}{"FT.AGGREGATE", index, query}
+	cmd := NewFTAggregateCmd(ctx, args...)
+	_ = c(ctx, cmd)
+	return cmd
+}
+
+// FTAggregateWithArgs - Runs a search query on an index and performs aggregate transformations on the results.
+// This function allows for specifying additional options such as:
+// Verbatim, Load, Timeout, GroupBy, SortBy, Apply, Limit, Filter, WithCursor, Params and DialectVersion.
+// For more information - https://redis.io/commands/ft.aggregate/
+func (c cmdable) FTAggregateWithArgs(ctx context.Context, index string, query string, options *FTAggregateOptions) *FTAggregateCmd {
+	args := []interface{}{"FT.AGGREGATE", index, query}
+	args = appendFTAggregateArgs(args, options)
+	cmd := NewFTAggregateCmd(ctx, args...)
+	_ = c(ctx, cmd)
+	return cmd
+}
+
+// FTAggregateIterator - Runs FT.AGGREGATE with WITHCURSOR and returns an iterator
+// over the rows, which reads the following batches with FT.CURSOR READ.
+// For more information - https://redis.io/docs/interact/search-and-query/search/aggregations/#cursor-api
+func (c cmdable) FTAggregateIterator(ctx context.Context, index string, query string, options *FTAggregateOptions) *AggregateIterator {
+	return newAggregateIterator(ctx, c, index, query, options)
+}
+
+// errAggregateIteratorPipeline is returned by the iterators created by a Pipeliner,
+// which can't read the cursor of FT.AGGREGATE before Exec.
+var errAggregateIteratorPipeline = errors.New("redis: FTAggregateIterator can't be used with a Pipeliner")
+
+// FTAggregateIterator is not supported by pipelines: the iterator returned
+// always fails with an error.
+func (c *Pipeline) FTAggregateIterator(ctx context.Context, index string, query string, options *FTAggregateOptions) *AggregateIterator {
+	cmd := NewFTAggregateCmd(ctx)
+	cmd.SetErr(errAggregateIteratorPipeline)
+	return &AggregateIterator{cmd: cmd}
+}
+
+func newAggregateIterator(ctx context.Context, process cmdable, index string, query string, options *FTAggregateOptions) *AggregateIterator {
+	var opt FTAggregateOptions
+	if options != nil {
+		opt = *options
+	}
+	opt.WithCursor = true
+
+	args := []interface{}{"FT.AGGREGATE", index, query}
+	args = appendFTAggregateArgs(args, &opt)
+	cmd := NewFTAggregateCmd(ctx, args...)
+	_ = process(ctx, cmd)
+
+	it := &AggregateIterator{
+		process: process,
+		index:   index,
+		cmd:     cmd,
+		cursor:  cmd.val.CursorID,
+	}
+	if opt.WithCursorOptions != nil {
+		it.count = opt.WithCursorOptions.Count
+	}
+	return it
+}
+
+func appendFTAggregateArgs(args []interface{}, options *FTAggregateOptions) []interface{} {
+	if options == nil {
+		return args
+	}
+	if options.Verbatim {
+		args = append(args, "VERBATIM")
+	}
+	if options.LoadAll {
+		args = append(args, "LOAD", "*")
+	} else if options.Load != nil {
+		load := make([]interface{}, 0, len(options.Load))
+		for _, l := range options.Load {
+			load = append(load, l.Field)
+			if l.As != "" {
+				load = append(load, "AS", l.As)
+			}
+		}
+		args = append(args, "LOAD", len(load))
+		args = append(args, load...)
+	}
+	if options.Timeout > 0 {
+		args = append(args, "TIMEOUT", options.Timeout)
+	}
+	for _, g := range options.GroupBy {
+		args = append(args, "GROUPBY", len(g.Fields))
+		args = append(args, g.Fields...)
+		for _, r := range g.Reduce {
+			args = append(args, "REDUCE", r.Reducer.String(), len(r.Args))
+			args = append(args, r.Args...)
+			if r.As != "" {
+				args = append(args, "AS", r.As)
+			}
+		}
+	}
+	if options.SortBy != nil {
+		sortBy := make([]interface{}, 0, len(options.SortBy))
+		for _, s := range options.SortBy {
+			sortBy = append(sortBy, s.FieldName)
+			if s.Asc {
+				sortBy = append(sortBy, "ASC")
+			} else if s.Desc {
+				sortBy = append(sortBy, "DESC")
+			}
+		}
+		args = append(args, "SORTBY", len(sortBy))
+		args = append(args, sortBy...)
+		if options.SortByMax > 0 {
+			args = append(args, "MAX", options.SortByMax)
+		}
+	}
+	for _, a := range options.Apply {
+		args = append(args, "APPLY", a.Field, "AS", a.As)
+	}
+	if options.LimitOffset > 0 || options.Limit > 0 {
+		args = append(args, "LIMIT", options.LimitOffset, options.Limit)
+	}
+	if options.Filter != "" {
+		args = append(args, "FILTER", options.Filter)
+	}
+	if options.WithCursor {
+		args = append(args, "WITHCURSOR")
+		if c := options.WithCursorOptions; c != nil {
+			if c.Count > 0 {
+				args = append(args, "COUNT", c.Count)
+			}
+			if c.MaxIdle > 0 {
+				args = append(args, "MAXIDLE", c.MaxIdle)
+			}
+		}
+	}
+	args = appendFTParams(args, options.Params)
+	if options.DialectVersion > 0 {
+		args = append(args, "DIALECT", options.DialectVersion)
+	}
+	return args
+}
+
+func appendFTParams(args []interface{}, params map[string]interface{}) []interface{} {
+	if len(params) == 0 {
+		return args
+	}
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	args = append(args, "PARAMS", len(params)*2)
+	for _, name := range names {
+		args = append(args, name, params[name])
+	}
+	return args
+}
+
+// FTAliasAdd - Adds an alias to an index.
+// For more information - https://redis.io/commands/ft.aliasadd/
+func (c cmdable) FTAliasAdd(ctx context.Context, index string, alias string) *StatusCmd {
+	args := []interface{}{"FT.ALIASADD", alias, index}
+	cmd := NewStatusCmd(ctx, args...)
+	_ = c(ctx, cmd)
+	return cmd
+}
+
+// FTAliasDel - Removes an alias from an index.
+// For more information - https://redis.io/commands/ft.aliasdel/
+func (c cmdable) FTAliasDel(ctx context.Context, alias string) *StatusCmd {
+	cmd := NewStatusCmd(ctx, "FT.ALIASDEL", alias)
+	_ = c(ctx, cmd)
+	return cmd
+}
+
+// FTAliasUpdate - Adds an alias to an index, removing it from the index it was pointing to, if any.
+// For more information - https://redis.io/commands/ft.aliasupdate/
+func (c cmdable) FTAliasUpdate(ctx context.Context, index string, alias string) *StatusCmd {
+	cmd := NewStatusCmd(ctx, "FT.ALIASUPDATE", alias, index)
+	_ = c(ctx, cmd)
+	return cmd
+}
+
+// FTAlter - Adds new attributes to the schema of an index.
+// If skipInitialScan is true, the existing documents are not scanned.
+// For more information - https://redis.io/commands/ft.alter/
+func (c cmdable) FTAlter(ctx context.Context, index string, skipInitialScan bool, schema ...*FieldSchema) *StatusCmd {
+	args := []interface{}{"FT.ALTER", index}
+	if skipInitialScan {
+		args = append(args, "SKIPINITIALSCAN")
+	}
+	args = append(args, "SCHEMA", "ADD")
+	for _, f := range schema {
+		args = appendFieldSchemaArgs(args, f)
+	}
+	cmd := NewStatusCmd(ctx, args...)
+	_ = c(ctx, cmd)
+	return cmd
+}
+
+// FTCreate - Creates an index with the given options and schema.
+// For more information - https://redis.io/commands/ft.create/
+func (c cmdable) FTCreate(ctx context.Context, index string, options *FTCreateOptions, schema ...*FieldSchema) *StatusCmd {
+	args := []interface{}{"FT.CREATE", index}
+	if options != nil {
+		if options.OnHash && !options.OnJSON {
+			args = append(args, "ON", "HASH")
+		}
+		if options.OnJSON && !options.OnHash {
+			args = append(args, "ON", "JSON")
+		}
+		if options.Prefix != nil {
+			args = append(args, "PREFIX", len(options.Prefix))
+			args = append(args, options.Prefix...)
+		}
+		if options.Filter != "" {
+			args = append(args, "FILTER", options.Filter)
+		}
+		if options.DefaultLanguage != "" {
+			args = append(args, "LANGUAGE", options.DefaultLanguage)
+		}
+		if options.LanguageField != "" {
+			args = append(args, "LANGUAGE_FIELD", options.LanguageField)
+		}
+		if options.Score > 0 {
+			args = append(args, "SCORE", options.Score)
+		}
+		if options.ScoreField != "" {
+			args = append(args, "SCORE_FIELD", options.ScoreField)
+		}
+		if options.PayloadField != "" {
+			args = append(args, "PAYLOAD_FIELD", options.PayloadField)
+		}
+		if options.MaxTextFields {
+			args = append(args, "MAXTEXTFIELDS")
+		}
+		if options.Temporary > 0 {
+			args = append(args, "TEMPORARY", options.Temporary)
+		}
+		if options.NoOffsets {
+			args = append(args, "NOOFFSETS")
+		}
+		if options.NoHL {
+			args = append(args, "NOHL")
+		}
+		if options.NoFields {
+			args = append(args, "NOFIELDS")
+		}
+		if options.NoFreqs {
+			args = append(args, "NOFREQS")
+		}
+		if options.StopWords != nil {
+			args = append(args, "STOPWORDS", len(options.StopWords))
+			args = append(args, options.StopWords...)
+		}
+		if options.SkipInitialScan {
+			args = append(args, "SKIPINITIALSCAN")
+		}
+	}
+	args = append(args, "SCHEMA")
+	for _, f := range schema {
+		args = appendFieldSchemaArgs(args, f)
+	}
+	cmd := NewStatusCmd(ctx, args...)
+	_ = c(ctx, cmd)
+	return cmd
+}
+
+func appendFieldSchemaArgs(args []interface{}, f *FieldSchema) []interface{} {
+	args = append(args, f.FieldName)
+	if f.As != "" {
+		args = append(args, "AS", f.As)
+	}
+	args = append(args, f.FieldType.String())
+	args = appendVectorArgs(args, f.VectorArgs)
+	if f.NoStem {
+		args = append(args, "NOSTEM")
+	}
+	if f.Weight > 0 {
+		args = append(args, "WEIGHT", f.Weight)
+	}
+	if f.PhoneticMatcher != "" {
+		args = append(args, "PHONETIC", f.PhoneticMatcher)
+	}
+	if f.Separator != "" {
+		args = append(args, "SEPARATOR", f.Separator)
+	}
+	if f.CaseSensitive {
+		args = append(args, "CASESENSITIVE")
+	}
+	if f.GeoShapeFieldType != "" {
+		args = append(args, f.GeoShapeFieldType)
+	}
+	if f.WithSuffixtrie {
+		args = append(args, "WITHSUFFIXTRIE")
+	}
+	if f.IndexEmpty {
+		args = append(args, "INDEXEMPTY")
+	}
+	if f.IndexMissing {
+		args = append(args, "INDEXMISSING")
+	}
+	if f.Sortable {
+		args = append(args, "SORTABLE")
+		if f.UNF {
+			args = append(args, "UNF")
+		}
+	}
+	if f.NoIndex {
+		args = append(args, "NOINDEX")
+	}
+	return args
+}
+
+// FTCursorDel - Deletes a cursor created by FT.AGGREGATE with WITHCURSOR.
+// For more information - https://redis.io/commands/ft.cursor-del/
+func (c cmdable) FTCursorDel(ctx context.Context, index string, cursorID int64) *StatusCmd {
+	cmd := NewStatusCmd(ctx, "FT.CURSOR", "DEL", index, cursorID)
+	_ = c(ctx, cmd)
+	return cmd
+}
+
+// FTCursorRead - Reads the next batch of results of a cursor created by FT.AGGREGATE with WITHCURSOR.
+// The CursorID of the result is 0 once all the results were read.
+// For more information - https://redis.io/commands/ft.cursor-read/
+func (c cmdable) FTCursorRead(ctx context.Context, index string, cursorID int64, count int) *FTAggregateCmd {
+	args := []interface{}{"FT.CURSOR", "READ", index, cursorID}
+	if count > 0 {
+		args = append(args, "COUNT", count)
+	}
+	cmd := NewFTAggregateCmd(ctx, args...)
+	_ = c(ctx, cmd)
+	return cmd
+}
+
+// FTDictAdd - Adds terms to a dictionary.
+// For more information - https://redis.io/commands/ft.dictadd/
+func (c cmdable) FTDictAdd(ctx context.Context, dict string, terms ...interface{}) *IntCmd {
+	args := []interface{}{"FT.DICTADD", dict}
+	args = append(args, terms...)
+	cmd := NewIntCmd(ctx, args...)
+	_ = c(ctx, cmd)
+	return cmd
+}
+
+// FTDictDel - Deletes terms from a dictionary.
+// For more information - https://redis.io/commands/ft.dictdel/
+func (c cmdable) FTDictDel(ctx context.Context, dict string, terms ...interface{}) *IntCmd {
+	args := []interface{}{"FT.DICTDEL", dict}
+	args = append(args, terms...)
+	cmd := NewIntCmd(ctx, args...)
+	_ = c(ctx, cmd)
+	return cmd
+}
+
+// FTDictDump - Returns all the terms of a dictionary.
+// For more information - https://redis.io/commands/ft.dictdump/
+func (c cmdable) FTDictDump(ctx context.Context, dict string) *StringSliceCmd {
+	cmd := NewStringSliceCmd(ctx, "FT.DICTDUMP", dict)
+	_ = c(ctx, cmd)
+	return cmd
+}
+
+// FTDropIndex - Deletes an index. The indexed documents are kept.
+// For more information - https://redis.io/commands/ft.dropindex/
+func (c cmdable) FTDropIndex(ctx context.Context, index string) *StatusCmd {
+	cmd := NewStatusCmd(ctx, "FT.DROPINDEX", index)
+	_ = c(ctx, cmd)
+	return cmd
+}
+
+// FTDropIndexWithArgs - Deletes an index.
+// This function allows for specifying additional options such as:
+// DeleteDocs, which also deletes the indexed documents.
+// For more information - https://redis.io/commands/ft.dropindex/
+func (c cmdable) FTDropIndexWithArgs(ctx context.Context, index string, options *FTDropIndexOptions) *StatusCmd {
+	args := []interface{}{"FT.DROPINDEX", index}
+	if options != nil {
+		if options.DeleteDocs {
+			args = append(args, "DD")
+		}
+	}
+	cmd := NewStatusCmd(ctx, args...)
+	_ = c(ctx, cmd)
+	return cmd
+}
+
+// FTExplain - Returns the execution plan of a query.
+// For more information - https://redis.io/commands/ft.explain/
+func (c cmdable) FTExplain(ctx context.Context, index string, query string) *StringCmd {
+	cmd := NewStringCmd(ctx, "FT.EXPLAIN", index, query)
+	_ = c(ctx, cmd)
+	return cmd
+}
+
+// FTExplainWithArgs - Returns the execution plan of a query.
+// This function allows for specifying additional options such as:
+// Dialect.
+// For more information - https://redis.io/commands/ft.explain/
+func (c cmdable) FTExplainWithArgs(ctx context.Context, index string, query string, options *FTExplainOptions) *StringCmd {
+	args := []interface{}{"FT.EXPLAIN", index, query}
+	if options != nil {
+		if options.Dialect > 0 {
+			args = append(args, "DIALECT", options.Dialect)
+		}
+	}
+	cmd := NewStringCmd(ctx, args...)
+	_ = c(ctx, cmd)
+	return cmd
+}
+
+// FTInfo - Returns information and statistics about an index.
+// For more information - https://redis.io/commands/ft.info/
+func (c cmdable) FTInfo(ctx context.Context, index string) *FTInfoCmd {
+	cmd := NewFTInfoCmd(ctx, "FT.INFO", index)
+	_ = c(ctx, cmd)
+	return cmd
+}
+
+// FTList - Returns the names of all the indexes (FT._LIST).
+// For more information - https://redis.io/commands/ft._list/
+func (c cmdable) FTList(ctx context.Context) *StringSliceCmd {
+	cmd := NewStringSliceCmd(ctx, "FT._LIST")
+	_ = c(ctx, cmd)
+	return cmd
+}
+
+// FTProfile - Runs FT.SEARCH or FT.AGGREGATE and reports how long each step took.
+// The options choose the profiled command and its arguments.
+// For more information - https://redis.io/commands/ft.profile/
+func (c cmdable) FTProfile(ctx context.Context, index string, query string, options *FTProfileOptions) *FTProfileCmd {
+	if options == nil {
+		options = &FTProfileOptions{}
+	}
+	args := []interface{}{"FT.PROFILE", index}
+	if options.Aggregate {
+		args = append(args, "AGGREGATE")
+	} else {
+		args = append(args, "SEARCH")
+	}
+	if options.Limited {
+		args = append(args, "LIMITED")
+	}
+	args = append(args, "QUERY", query)
+	if options.Aggregate {
+		args = appendFTAggregateArgs(args, options.AggregateOptions)
+	} else {
+		args = appendFTSearchArgs(args, options.SearchOptions)
+	}
+	cmd := NewFTProfileCmd(ctx, options, args...)
+	_ = c(ctx, cmd)
+	return cmd
+}
+
+// FTSearch - Searches an index with a textual query.
+// For more information - https://redis.io/commands/ft.search/
+func (c cmdable) FTSearch(ctx context.Context, index string, query string) *FTSearchCmd {
+	args := []interface{}{"FT.SEARCH", index, query}
+	cmd := NewFTSearchCmd(ctx, nil, args...)
+	_ = c(ctx, cmd)
+	return cmd
+}
+
+// FTSearchWithArgs - Searches an index with a textual query.
+// This function allows for specifying additional options such as:
+// NoContent, WithScores, Filters, Return, SortBy, Limit, Params and DialectVersion.
+// For more information - https://redis.io/commands/ft.search/
+func (c cmdable) FTSearchWithArgs(ctx context.Context, index string, query string, options *FTSearchOptions) *FTSearchCmd {
+	args := []interface{}{"FT.SEARCH", index, query}
+	args = appendFTSearchArgs(args, options)
+	cmd := NewFTSearchCmd(ctx, options, args...)
+	_ = c(ctx, cmd)
+	return cmd
+}
+
+func appendFTSearchArgs(args []interface{}, options *FTSearchOptions) []interface{} {
+	if options == nil {
+		return args
+	}
+	if options.NoContent {
+		args = append(args, "NOCONTENT")
+	}
+	if options.Verbatim {
+		args = append(args, "VERBATIM")
+	}
+	if options.NoStopWords {
+		args = append(args, "NOSTOPWORDS")
+	}
+	if options.WithScores {
+		args = append(args, "WITHSCORES")
+	}
+	if options.WithPayloads {
+		args = append(args, "WITHPAYLOADS")
+	}
+	if options.WithSortKeys {
+		args = append(args, "WITHSORTKEYS")
+	}
+	for _, f := range options.Filters {
+		args = append(args, "FILTER", f.FieldName, f.Min, f.Max)
+	}
+	for _, g := range options.GeoFilter {
+		args = append(args, "GEOFILTER", g.FieldName, g.Longitude, g.Latitude, g.Radius, g.Unit)
+	}
+	if options.InKeys != nil {
+		args = append(args, "INKEYS", len(options.InKeys))
+		args = append(args, options.InKeys...)
+	}
+	if options.InFields != nil {
+		args = append(args, "INFIELDS", len(options.InFields))
+		args = append(args, options.InFields...)
+	}
+	if options.Return != nil {
+		ret := make([]interface{}, 0, len(options.Return))
+		for _, r := range options.Return {
+			ret = append(ret, r.FieldName)
+			if r.As != "" {
+				ret = append(ret, "AS", r.As)
+			}
+		}
+		args = append(args, "RETURN", len(ret))
+		args = append(args, ret...)
+	}
+	if s := options.Summarize; s != nil {
+		args = append(args, "SUMMARIZE")
+		if s.Fields != nil {
+			args = append(args, "FIELDS", len(s.Fields))
+			for _, f := range s.Fields {
+				args = append(args, f)
+			}
+		}
+		if s.Frags > 0 {
+			args = append(args, "FRAGS", s.Frags)
+		}
+		if s.Len > 0 {
+			args = append(args, "LEN", s.Len)
+		}
+		if s.Separator != "" {
+			args = append(args, "SEPARATOR", s.Separator)
+		}
+	}
+	if h := options.Highlight; h != nil {
+		args = append(args, "HIGHLIGHT")
+		if h.Fields != nil {
+			args = append(args, "FIELDS", len(h.Fields))
+			for _, f := range h.Fields {
+				args = append(args, f)
+			}
+		}
+		if h.OpenTag != "" || h.CloseTag != "" {
+			args = append(args, "TAGS", h.OpenTag, h.CloseTag)
+		}
+	}
+	if options.Slop > 0 {
+		args = append(args, "SLOP", options.Slop)
+	}
+	if options.Timeout > 0 {
+		args = append(args, "TIMEOUT", options.Timeout)
+	}
+	if options.InOrder {
+		args = append(args, "INORDER")
+	}
+	if options.Language != "" {
+		args = append(args, "LANGUAGE", options.Language)
+	}
+	if options.Expander != "" {
+		args = append(args, "EXPANDER", options.Expander)
+	}
+	if options.Scorer != "" {
+		args = append(args, "SCORER", options.Scorer)
+	}
+	if options.Payload != "" {
+		args = append(args, "PAYLOAD", options.Payload)
+	}
+	if s := options.SortBy; s != nil {
+		args = append(args, "SORTBY", s.FieldName)
+		if s.Asc {
+			args = append(args, "ASC")
+		} else if s.Desc {
+			args = append(args, "DESC")
+		}
+		if options.SortByWithCount {
+			args = append(args, "WITHCOUNT")
+		}
+	}
+	if options.CountOnly {
+		args = append(args, "LIMIT", 0, 0)
+	} else if options.LimitOffset > 0 || options.Limit > 0 {
+		args = append(args, "LIMIT", options.LimitOffset, options.Limit)
+	}
+	args = appendFTParams(args, options.Params)
+	if options.DialectVersion > 0 {
+		args = append(args, "DIALECT", options.DialectVersion)
+	}
+	return args
+}
+
+// FTSpellCheck - Suggests spelling corrections for the terms of a query.
+// For more information - https://redis.io/commands/ft.spellcheck/
+func (c cmdable) FTSpellCheck(ctx context.Context, index string, query string) *FTSpellCheckCmd {
+	cmd := NewFTSpellCheckCmd(ctx, "FT.SPELLCHECK", index, query)
+	_ = c(ctx, cmd)
+	return cmd
+}
+
+// FTSpellCheckWithArgs - Suggests spelling corrections for the terms of a query.
+// This function allows for specifying additional options such as:
+// Distance, Terms and Dialect.
+// For more information - https://redis.io/commands/ft.spellcheck/
+func (c cmdable) FTSpellCheckWithArgs(ctx context.Context, index string, query string, options *FTSpellCheckOptions) *FTSpellCheckCmd {
+	args := []interface{}{"FT.SPELLCHECK", index, query}
+	if options != nil {
+		if options.Distance > 0 {
+			args = append(args, "DISTANCE", options.Distance)
+		}
+		if t := options.Terms; t != nil {
+			args = append(args, "TERMS", t.Inclusion, t.Dictionary)
+			args = append(args, t.Terms...)
+		}
+		if options.Dialect > 0 {
+			args = append(args, "DIALECT", options.Dialect)
+		}
+	}
+	cmd := NewFTSpellCheckCmd(ctx, args...)
+	_ = c(ctx, cmd)
+	return cmd
+}
+
+// FTTagVals - Returns the distinct values of a TAG field.
+// For more information - https://redis.io/commands/ft.tagvals/
+func (c cmdable) FTTagVals(ctx context.Context, index string, field string) *StringSliceCmd {
+	cmd := NewStringSliceCmd(ctx, "FT.TAGVALS", index, field)
+	_ = c(ctx, cmd)
+	return cmd
+}
+
+//------------------------------------------------------------------------------
+
+// Document is a document returned by FT.SEARCH. Score, Payload and SortKey
+// are only set when requested with WithScores, WithPayloads and WithSortKeys.
+type Document struct {
+	ID      string
+	Score   *float64
+	Payload *string
+	SortKey *string
+	Fields  map[string]string
+}
+
+type FTSearchResult struct {
+	Total int64
+	Docs  []Document
+}
+
+type FTSearchCmd struct {
+	baseCmd
+	val     FTSearchResult
+	options *FTSearchOptions
+}
+
+func NewFTSearchCmd(ctx context.Context, options *FTSearchOptions, args ...interface{}) *FTSearchCmd {
+	return &FTSearchCmd{
+		baseCmd: baseCmd{
+			ctx:  ctx,
+			args: args,
+		},
+		options: options,
+	}
+}
+
+func (cmd *FTSearchCmd) SetVal(val FTSearchResult) {
+	cmd.val = val
+}
+
+func (cmd *FTSearchCmd) Val() FTSearchResult {
+	return cmd.val
+}
+
+func (cmd *FTSearchCmd) Result() (FTSearchResult, error) {
+	return cmd.val, cmd.err
+}
+
+func (cmd *FTSearchCmd) String() string {
+	return cmdString(cmd, cmd.val)
+}
+
+func (cmd *FTSearchCmd) readReply(rd *proto.Reader) error {
+	reply, err := rd.ReadReply()
+	if err != nil {
+		return err
+	}
+	cmd.val, err = parseFTSearch(reply, cmd.options)
+	return err
+}
+
+// parseFTSearch decodes the reply of FT.SEARCH: a map in RESP3, or an
+// array with the total followed by the documents in RESP2.
+func parseFTSearch(reply interface{}, options *FTSearchOptions) (FTSearchResult, error) {
+	var res FTSearchResult
+	switch reply := reply.(type) {
+	case map[interface{}]interface{}:
+		err := forEachPair(reply, func(k, v interface{}) error {
+			key, err := replyString(k)
+			if err != nil {
+				return err
+			}
+			switch key {
+			case "total_results":
+				res.Total, err = toInt64(v)
+				return err
+			case "results":
+				docs, ok := v.([]interface{})
+				if !ok {
+					return fmt.Errorf("redis: unexpected type=%T for FT.SEARCH results", v)
+				}
+				res.Docs = make([]Document, 0, len(docs))
+				for _, d := range docs {
+					doc, err := parseFTSearchDoc(d)
+					if err != nil {
+						return err
+					}
+					res.Docs = append(res.Docs, doc)
+				}
+			}
+			return nil
+		})
+		return res, err
+	case []interface{}:
+		return parseFTSearchArray(reply, options)
+	case error:
+		return res, reply
+	default:
+		return res, fmt.Errorf("redis: unexpected type=%T for FT.SEARCH", reply)
+	}
+}
+
+func parseFTSearchArray(reply []interface{}, options *FTSearchOptions) (FTSearchResult, error) {
+	var res FTSearchResult
+	if len(reply) == 0 {
+		return res, fmt.Errorf("redis: got an empty FT.SEARCH reply")
+	}
+
+	var err error
+	if res.Total, err = toInt64(reply[0]); err != nil {
+		return res, err
+	}
+
+	if options == nil {
+		options = &FTSearchOptions{}
+	}
+	step := 1
+	for _, with := range []bool{options.WithScores, options.WithPayloads, options.WithSortKeys, !options.NoContent} {
+		if with {
+			step++
+		}
+	}
+	if (len(reply)-1)%step != 0 {
+		return res, fmt.Errorf("redis: got %d elements in the FT.SEARCH reply, wanted 1 + a multiple of %d", len(reply), step)
+	}
+
+	res.Docs = make([]Document, 0, (len(reply)-1)/step)
+	for i := 1; i < len(reply); i += step {
+		var doc Document
+		j := i
+		if doc.ID, err = replyString(reply[j]); err != nil {
+			return res, err
+		}
+		j++
+		if options.WithScores {
+			score, err := parseFTScore(reply[j])
+			if err != nil {
+				return res, err
+			}
+			doc.Score = &score
+			j++
+		}
+		if options.WithPayloads {
+			if doc.Payload, err = replyStringPtr(reply[j]); err != nil {
+				return res, err
+			}
+			j++
+		}
+		if options.WithSortKeys {
+			if doc.SortKey, err = replyStringPtr(reply[j]); err != nil {
+				return res, err
+			}
+			j++
+		}
+		if !options.NoContent {
+			if doc.Fields, err = parseFTFields(reply[j]); err != nil {
+				return res, err
+			}
+		}
+		res.Docs = append(res.Docs, doc)
+	}
+	return res, nil
+}
+
+func parseFTSearchDoc(reply interface{}) (Document, error) {
+	var doc Document
+	if err, ok := reply.(error); ok {
+		return doc, err
+	}
+	err := forEachPair(reply, func(k, v interface{}) error {
+		key, err := replyString(k)
+		if err != nil {
+			return err
+		}
+		switch key {
+		case "id":
+			doc.ID, err = replyString(v)
+		case "score":
+			var score float64
+			score, err = parseFTScore(v)
+			doc.Score = &score
+		case "payload":
+			doc.Payload, err = replyStringPtr(v)
+		case "sortkey":
+			doc.SortKey, err = replyStringPtr(v)
+		case "extra_attributes":
+			doc.Fields, err = parseFTFields(v)
+		}
+		return err
+	})
+	return doc, err
+}
+
+// parseFTScore decodes a score, which is a [score, explanation] pair with EXPLAINSCORE.
+func parseFTScore(reply interface{}) (float64, error) {
+	if vals, ok := reply.([]interface{}); ok && len(vals) > 0 {
+		reply = vals[0]
+	}
+	return DecodeReply[float64](reply)
+}
+
+func parseFTFields(reply interface{}) (map[string]string, error) {
+	fields := make(map[string]string)
+	err := forEachPair(reply, func(k, v interface{}) error {
+		if v == nil {
+			return nil
+		}
+		key, err := replyString(k)
+		if err != nil {
+			return err
+		}
+		if fields[key], err = replyString(v); err != nil {
+			return err
+		}
+		return nil
+	})
+	return fields, err
+}
+
+func replyStringPtr(reply interface{}) (*string, error) {
+	if reply == nil {
+		return nil, nil
+	}
+	s, err := replyString(reply)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+//------------------------------------------------------------------------------
+
+type AggregateRow struct {
+	Fields map[string]interface{}
+}
+
+type FTAggregateResult struct {
+	Total int64
+	Rows  []AggregateRow
+	// CursorID is the cursor to read the next rows from with WITHCURSOR,
+	// or 0 when there are no more rows.
+	CursorID int64
+}
+
+type FTAggregateCmd struct {
+	baseCmd
+	val FTAggregateResult
+}
+
+func NewFTAggregateCmd(ctx context.Context, args ...interface{}) *FTAggregateCmd {
+	return &FTAggregateCmd{
+		baseCmd: baseCmd{
+			ctx:  ctx,
+			args: args,
+		},
+	}
+}
+
+func (cmd *FTAggregateCmd) SetVal(val FTAggregateResult) {
+	cmd.val = val
+}
+
+func (cmd *FTAggregateCmd) Val() FTAggregateResult {
+	return cmd.val
+}
+
+func (cmd *FTAggregateCmd) Result() (FTAggregateResult, error) {
+	return cmd.val, cmd.err
+}
+
+func (cmd *FTAggregateCmd) String() string {
+	return cmdString(cmd, cmd.val)
+}
+
+func (cmd *FTAggregateCmd) readReply(rd *proto.Reader) error {
+	reply, err := rd.ReadReply()
+	if err != nil {
+		return err
+	}
+
+	// With WITHCURSOR and FT.CURSOR READ, the reply is [results, cursor].
+	if vals, ok := reply.([]interface{}); ok && len(vals) == 2 {
+		switch vals[0].(type) {
+		case []interface{}, map[interface{}]interface{}:
+			if cmd.val, err = parseFTAggregate(vals[0]); err != nil {
+				return err
+			}
+			cmd.val.CursorID, err = toInt64(vals[1])
+			return err
+		}
+	}
+
+	cmd.val, err = parseFTAggregate(reply)
+	return err
+}
+
+// parseFTAggregate decodes the reply of FT.AGGREGATE: a map in RESP3, or an
+// array with the total followed by the rows in RESP2.
+func parseFTAggregate(reply interface{}) (FTAggregateResult, error) {
+	var res FTAggregateResult
+	switch reply := reply.(type) {
+	case map[interface{}]interface{}:
+		err := forEachPair(reply, func(k, v interface{}) error {
+			key, err := replyString(k)
+			if err != nil {
+				return err
+			}
+			switch key {
+			case "total_results":
+				res.Total, err = toInt64(v)
+				return err
+			case "results":
+				rows, ok := v.([]interface{})
+				if !ok {
+					return fmt.Errorf("redis: unexpected type=%T for FT.AGGREGATE results", v)
+				}
+				res.Rows = make([]AggregateRow, 0, len(rows))
+				for _, r := range rows {
+					var row AggregateRow
+					err := forEachPair(r, func(k, v interface{}) error {
+						if key, _ := k.(string); key != "extra_attributes" {
+							return nil
+						}
+						var err error
+						row.Fields, err = parseFTRow(v)
+						return err
+					})
+					if err != nil {
+						return err
+					}
+					res.Rows = append(res.Rows, row)
+				}
+			}
+			return nil
+		})
+		return res, err
+	case []interface{}:
+		if len(reply) == 0 {
+			return res, fmt.Errorf("redis: got an empty FT.AGGREGATE reply")
+		}
+		var err error
+		if res.Total, err = toInt64(reply[0]); err != nil {
+			return res, err
+		}
+		res.Rows = make([]AggregateRow, 0, len(reply)-1)
+		for _, r := range reply[1:] {
+			fields, err := parseFTRow(r)
+			if err != nil {
+				return res, err
+			}
+			res.Rows = append(res.Rows, AggregateRow{Fields: fields})
+		}
+		return res, nil
+	case error:
+		return res, reply
+	default:
+		return res, fmt.Errorf("redis: unexpected type=%T for FT.AGGREGATE", reply)
+	}
+}
+
+func parseFTRow(reply interface{}) (map[string]interface{}, error) {
+	if err, ok := reply.(error); ok {
+		return nil, err
+	}
+	fields := make(map[string]interface{})
+	err := forEachPair(reply, func(k, v interface{}) error {
+		key, err := replyString(k)
+		if err != nil {
+			return err
+		}
+		fields[key] = v
+		return nil
+	})
+	return fields, err
+}
+
+//------------------------------------------------------------------------------
+
+type IndexDefinition struct {
+	KeyType         string   `redis:"key_type"`
+	Prefixes        []string `redis:"prefixes"`
+	Filter          string   `redis:"filter"`
+	DefaultLanguage string   `redis:"default_language"`
+	LanguageField   string   `redis:"language_field"`
+	DefaultScore    float64  `redis:"default_score"`
+	ScoreField      string   `redis:"score_field"`
+	PayloadField    string   `redis:"payload_field"`
+}
+
+// FTAttribute is an attribute of the schema returned by FT.INFO.
+type FTAttribute struct {
+	Identifier      string
+	Attribute       string
+	Type            string
+	Weight          float64
+	Separator       string
+	PhoneticMatcher string
+	Algorithm       string
+	DataType        string
+	Dim             int64
+	DistanceMetric  string
+	Sortable        bool
+	UNF             bool
+	NoStem          bool
+	NoIndex         bool
+	CaseSensitive   bool
+	WithSuffixtrie  bool
+	IndexEmpty      bool
+	IndexMissing    bool
+}
+
+type FTInfoResult struct {
+	IndexName                string                 `redis:"index_name"`
+	IndexOptions             []string               `redis:"index_options"`
+	IndexDefinition          IndexDefinition        `redis:"index_definition"`
+	Attributes               []FTAttribute          `redis:"-"`
+	NumDocs                  int64                  `redis:"num_docs"`
+	MaxDocID                 int64                  `redis:"max_doc_id"`
+	NumTerms                 int64                  `redis:"num_terms"`
+	NumRecords               int64                  `redis:"num_records"`
+	InvertedSzMB             float64                `redis:"inverted_sz_mb"`
+	VectorIndexSzMB          float64                `redis:"vector_index_sz_mb"`
+	TotalInvertedIndexBlocks int64                  `redis:"total_inverted_index_blocks"`
+	OffsetVectorsSzMB        float64                `redis:"offset_vectors_sz_mb"`
+	DocTableSizeMB           float64                `redis:"doc_table_size_mb"`
+	SortableValuesSizeMB     float64                `redis:"sortable_values_size_mb"`
+	KeyTableSizeMB           float64                `redis:"key_table_size_mb"`
+	RecordsPerDocAvg         float64                `redis:"records_per_doc_avg"`
+	BytesPerRecordAvg        float64                `redis:"bytes_per_record_avg"`
+	OffsetsPerTermAvg        float64                `redis:"offsets_per_term_avg"`
+	OffsetBitsPerRecordAvg   float64                `redis:"offset_bits_per_record_avg"`
+	HashIndexingFailures     int64                  `redis:"hash_indexing_failures"`
+	TotalIndexingTime        float64                `redis:"total_indexing_time"`
+	Indexing                 bool                   `redis:"indexing"`
+	PercentIndexed           float64                `redis:"percent_indexed"`
+	NumberOfUses             int64                  `redis:"number_of_uses"`
+	Cleaning                 bool                   `redis:"cleaning"`
+	GCStats                  map[string]interface{} `redis:"gc_stats"`
+	CursorStats              map[string]interface{} `redis:"cursor_stats"`
+	DialectStats             map[string]int64       `redis:"dialect_stats"`
+}
+
+type FTInfoCmd struct {
+	baseCmd
+	val FTInfoResult
+}
+
+func NewFTInfoCmd(ctx context.Context, args ...interface{}) *FTInfoCmd {
+	return &FTInfoCmd{
+		baseCmd: baseCmd{
+			ctx:  ctx,
+			args: args,
+		},
+	}
+}
+
+func (cmd *FTInfoCmd) SetVal(val FTInfoResult) {
+	cmd.val = val
+}
+
+func (cmd *FTInfoCmd) Val() FTInfoResult {
+	return cmd.val
+}
+
+func (cmd *FTInfoCmd) Result() (FTInfoResult, error) {
+	return cmd.val, cmd.err
+}
+
+func (cmd *FTInfoCmd) String() string {
+	return cmdString(cmd, cmd.val)
+}
+
+func (cmd *FTInfoCmd) readReply(rd *proto.Reader) error {
+	reply, err := rd.ReadReply()
+	if err != nil {
+		return err
+	}
+	if cmd.val, err = DecodeReply[FTInfoResult](reply); err != nil {
+		return err
+	}
+	return forEachPair(reply, func(k, v interface{}) error {
+		if key, _ := k.(string); key != "attributes" {
+			return nil
+		}
+		attrs, ok := v.([]interface{})
+		if !ok {
+			return fmt.Errorf("redis: unexpected type=%T for FT.INFO attributes", v)
+		}
+		cmd.val.Attributes = make([]FTAttribute, 0, len(attrs))
+		for _, a := range attrs {
+			attr, err := parseFTAttribute(a)
+			if err != nil {
+				return err
+			}
+			cmd.val.Attributes = append(cmd.val.Attributes, attr)
+		}
+		return nil
+	})
+}
+
+// parseFTAttribute decodes an attribute of FT.INFO. In RESP2 it is an array
+// mixing field-value pairs with flags, in RESP3 a map with a flags array.
+func parseFTAttribute(reply interface{}) (FTAttribute, error) {
+	var attr FTAttribute
+	switch reply := reply.(type) {
+	case map[interface{}]interface{}:
+		err := forEachPair(reply, func(k, v interface{}) error {
+			key, err := replyString(k)
+			if err != nil {
+				return err
+			}
+			if key != "flags" {
+				return attr.set(key, v)
+			}
+			flags, ok := v.([]interface{})
+			if !ok {
+				return fmt.Errorf("redis: unexpected type=%T for FT.INFO attribute flags", v)
+			}
+			for _, f := range flags {
+				flag, err := replyString(f)
+				if err != nil {
+					return err
+				}
+				attr.setFlag(flag)
+			}
+			return nil
+		})
+		return attr, err
+	case []interface{}:
+		for i := 0; i < len(reply); i++ {
+			key, err := replyString(reply[i])
+			if err != nil {
+				return attr, err
+			}
+			if attr.setFlag(key) {
+				continue
+			}
+			if i+1 == len(reply) {
+				return attr, fmt.Errorf("redis: FT.INFO attribute %q has no value", key)
+			}
+			i++
+			if err := attr.set(key, reply[i]); err != nil {
+				return attr, err
+			}
+		}
+		return attr, nil
+	case error:
+		return attr, reply
+	default:
+		return attr, fmt.Errorf("redis: unexpected type=%T for FT.INFO attribute", reply)
+	}
+}
+
+func (a *FTAttribute) set(key string, val interface{}) (err error) {
+	switch strings.ToLower(key) {
+	case "identifier":
+		a.Identifier, err = replyString(val)
+	case "attribute":
+		a.Attribute, err = replyString(val)
+	case "type":
+		a.Type, err = replyString(val)
+	case "weight":
+		a.Weight, err = DecodeReply[float64](val)
+	case "separator":
+		a.Separator, err = replyString(val)
+	case "phonetic":
+		a.PhoneticMatcher, err = replyString(val)
+	case "algorithm":
+		a.Algorithm, err = replyString(val)
+	case "data_type":
+		a.DataType, err = replyString(val)
+	case "dim":
+		a.Dim, err = DecodeReply[int64](val)
+	case "distance_metric":
+		a.DistanceMetric, err = replyString(val)
+	}
+	return err
+}
+
+func (a *FTAttribute) setFlag(flag string) bool {
+	switch flag {
+	case "SORTABLE":
+		a.Sortable = true
+	case "UNF":
+		a.UNF = true
+	case "NOSTEM":
+		a.NoStem = true
+	case "NOINDEX":
+		a.NoIndex = true
+	case "CASESENSITIVE":
+		a.CaseSensitive = true
+	case "WITHSUFFIXTRIE":
+		a.WithSuffixtrie = true
+	case "INDEXEMPTY":
+		a.IndexEmpty = true
+	case "INDEXMISSING":
+		a.IndexMissing = true
+	default:
+		return false
+	}
+	return true
+}
+
+//------------------------------------------------------------------------------
+
+type SpellCheckSuggestion struct {
+	Score      float64
+	Suggestion string
+}
+
+// SpellCheckResult holds the suggestions for a misspelled term of the query.
+type SpellCheckResult struct {
+	Term        string
+	Suggestions []SpellCheckSuggestion
+}
+
+type FTSpellCheckCmd struct {
+	baseCmd
+	val []SpellCheckResult
+}
+
+func NewFTSpellCheckCmd(ctx context.Context, args ...interface{}) *FTSpellCheckCmd {
+	return &FTSpellCheckCmd{
+		baseCmd: baseCmd{
+			ctx:  ctx,
+			args: args,
+		},
+	}
+}
+
+func (cmd *FTSpellCheckCmd) SetVal(val []SpellCheckResult) {
+	cmd.val = val
+}
+
+func (cmd *FTSpellCheckCmd) Val() []SpellCheckResult {
+	return cmd.val
+}
+
+func (cmd *FTSpellCheckCmd) Result() ([]SpellCheckResult, error) {
+	return cmd.val, cmd.err
+}
+
+func (cmd *FTSpellCheckCmd) String() string {
+	return cmdString(cmd, cmd.val)
+}
+
+// readReply decodes an array of ["TERM", term, [[score, suggestion], ...]]
+// in RESP2, and a map of results from term to [{suggestion: score}, ...]
+// in RESP3. The RESP3 terms are sorted, since the map loses their order.
+func (cmd *FTSpellCheckCmd) readReply(rd *proto.Reader) error {
+	reply, err := rd.ReadReply()
+	if err != nil {
+		return err
+	}
+
+	switch reply := reply.(type) {
+	case map[interface{}]interface{}:
+		results, ok := reply["results"]
+		if !ok {
+			cmd.val = []SpellCheckResult{}
+			return nil
+		}
+		cmd.val = make([]SpellCheckResult, 0)
+		err := forEachPair(results, func(k, v interface{}) error {
+			term, err := replyString(k)
+			if err != nil {
+				return err
+			}
+			res := SpellCheckResult{Term: term}
+			sugs, ok := v.([]interface{})
+			if !ok {
+				return fmt.Errorf("redis: unexpected type=%T for FT.SPELLCHECK suggestions", v)
+			}
+			for _, s := range sugs {
+				err := forEachPair(s, func(k, v interface{}) error {
+					sug, err := replyString(k)
+					if err != nil {
+						return err
+					}
+					score, err := DecodeReply[float64](v)
+					if err != nil {
+						return err
+					}
+					res.Suggestions = append(res.Suggestions, SpellCheckSuggestion{Score: score, Suggestion: sug})
+					return nil
+				})
+				if err != nil {
+					return err
+				}
+			}
+			cmd.val = append(cmd.val, res)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		sort.Slice(cmd.val, func(i, j int) bool {
+			return cmd.val[i].Term < cmd.val[j].Term
+		})
+		return nil
+	case []interface{}:
+		cmd.val = make([]SpellCheckResult, 0, len(reply))
+		for _, r := range reply {
+			vals, ok := r.([]interface{})
+			if !ok || len(vals) != 3 {
+				return fmt.Errorf("redis: unexpected FT.SPELLCHECK result %v", r)
+			}
+			term, err := replyString(vals[1])
+			if err != nil {
+				return err
+			}
+			res := SpellCheckResult{Term: term}
+			sugs, ok := vals[2].([]interface{})
+			if !ok {
+				return fmt.Errorf("redis: unexpected type=%T for FT.SPELLCHECK suggestions", vals[2])
+			}
+			for _, s := range sugs {
+				pair, ok := s.([]interface{})
+				if !ok || len(pair) != 2 {
+					return fmt.Errorf("redis: unexpected FT.SPELLCHECK suggestion %v", s)
+				}
+				score, err := DecodeReply[float64](pair[0])
+				if err != nil {
+					return err
+				}
+				sug, err := replyString(pair[1])
+				if err != nil {
+					return err
+				}
+				res.Suggestions = append(res.Suggestions, SpellCheckSuggestion{Score: score, Suggestion: sug})
+			}
+			cmd.val = append(cmd.val, res)
+		}
+		return nil
+	default:
+		return fmt.Errorf("redis: unexpected type=%T for FT.SPELLCHECK", reply)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// FTProfileResult holds the results of the profiled query, in Search or in
+// Aggregate depending on FTProfileOptions.Aggregate, and the profile.
+type FTProfileResult struct {
+	Search    *FTSearchResult
+	Aggregate *FTAggregateResult
+	Profile   map[string]interface{}
+}
+
+type FTProfileCmd struct {
+	baseCmd
+	val     FTProfileResult
+	options *FTProfileOptions
+}
+
+func NewFTProfileCmd(ctx context.Context, options *FTProfileOptions, args ...interface{}) *FTProfileCmd {
+	return &FTProfileCmd{
+		baseCmd: baseCmd{
+			ctx:  ctx,
+			args: args,
+		},
+		options: options,
+	}
+}
+
+func (cmd *FTProfileCmd) SetVal(val FTProfileResult) {
+	cmd.val = val
+}
+
+func (cmd *FTProfileCmd) Val() FTProfileResult {
+	return cmd.val
+}
+
+func (cmd *FTProfileCmd) Result() (FTProfileResult, error) {
+	return cmd.val, cmd.err
+}
+
+func (cmd *FTProfileCmd) String() string {
+	return cmdString(cmd, cmd.val)
+}
+
+// readReply decodes a [results, profile] array in RESP2, and a map with
+// the results and a profile entry in RESP3.
+func (cmd *FTProfileCmd) readReply(rd *proto.Reader) error {
+	reply, err := rd.ReadReply()
+	if err != nil {
+		return err
+	}
+
+	var results, profile interface{}
+	switch reply := reply.(type) {
+	case []interface{}:
+		if len(reply) != 2 {
+			return fmt.Errorf("redis: got %d elements in the FT.PROFILE reply, wanted 2", len(reply))
+		}
+		results, profile = reply[0], reply[1]
+	case map[interface{}]interface{}:
+		results = reply
+		for k, v := range reply {
+			switch k {
+			case "results", "Results":
+				if _, ok := reply["total_results"]; !ok {
+					results = v
+				}
+			case "profile", "Profile":
+				profile = v
+			}
+		}
+	default:
+		return fmt.Errorf("redis: unexpected type=%T for FT.PROFILE", reply)
+	}
+
+	cmd.val = FTProfileResult{}
+	if cmd.options != nil && cmd.options.Aggregate {
+		res, err := parseFTAggregate(results)
+		if err != nil {
+			return err
+		}
+		cmd.val.Aggregate = &res
+	} else {
+		var options *FTSearchOptions
+		if cmd.options != nil {
+			options = cmd.options.SearchOptions
+		}
+		res, err := parseFTSearch(results, options)
+		if err != nil {
+			return err
+		}
+		cmd.val.Search = &res
+	}
+	cmd.val.Profile, err = parseFTProfile(profile)
+	return err
+}
+
+// parseFTProfile decodes a profile given as a map, as field-value pairs, or
+// as an array of [field, value...] arrays.
+func parseFTProfile(reply interface{}) (map[string]interface{}, error) {
+	profile := make(map[string]interface{})
+	if entries, ok := reply.([]interface{}); ok && len(entries) > 0 {
+		if _, ok := entries[0].([]interface{}); ok {
+			for _, e := range entries {
+				vals, ok := e.([]interface{})
+				if !ok || len(vals) == 0 {
+					return nil, fmt.Errorf("redis: unexpected FT.PROFILE entry %v", e)
+				}
+				key, err := replyString(vals[0])
+				if err != nil {
+					return nil, err
+				}
+				if len(vals) == 2 {
+					profile[key] = vals[1]
+				} else {
+					profile[key] = vals[1:]
+				}
+			}
+			return profile, nil
+		}
+	}
+	if reply == nil {
+		return profile, nil
+	}
+	err := forEachPair(reply, func(k, v interface{}) error {
+		key, err := replyString(k)
+		if err != nil {
+			return err
+		}
+		profile[key] = v
+		return nil
+	})
+	return profile, err
+}
@@ -0,0 +1,442 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/redis/go-redis/v9/internal/proto"
+)
+
+func readFTReply(t *testing.T, cmd Cmder, reply string) {
+	t.Helper()
+	if err := cmd.readReply(proto.NewReader(strings.NewReader(reply))); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFTSearchCmdReadReply(t *testing.T) {
+	score := 1.5
+	payload := "pl"
+	want := FTSearchResult{
+		Total: 2,
+		Docs: []Document{
+			{ID: "doc:1", Score: &score, Payload: &payload, Fields: map[string]string{"title": "hello"}},
+			{ID: "doc:2", Score: &score, Fields: map[string]string{}},
+		},
+	}
+	options := &FTSearchOptions{WithScores: true, WithPayloads: true}
+
+	for _, tt := range []struct {
+		name  string
+		reply string
+	}{
+		{"RESP2", "*9\r\n:2\r\n" +
+			"$5\r\ndoc:1\r\n$3\r\n1.5\r\n$2\r\npl\r\n*2\r\n$5\r\ntitle\r\n$5\r\nhello\r\n" +
+			"$5\r\ndoc:2\r\n$3\r\n1.5\r\n$-1\r\n*0\r\n"},
+		{"RESP3", "%3\r\n+total_results\r\n:2\r\n+format\r\n+STRING\r\n+results\r\n*2\r\n" +
+			"%4\r\n+id\r\n$5\r\ndoc:1\r\n+score\r\n,1.5\r\n+payload\r\n$2\r\npl\r\n" +
+			"+extra_attributes\r\n%1\r\n$5\r\ntitle\r\n$5\r\nhello\r\n" +
+			"%3\r\n+id\r\n$5\r\ndoc:2\r\n+score\r\n,1.5\r\n+extra_attributes\r\n%0\r\n"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := NewFTSearchCmd(ctx, options)
+			readFTReply(t, cmd, tt.reply)
+			if !reflect.DeepEqual(cmd.Val(), want) {
+				t.Fatalf("got %+v, wanted %+v", cmd.Val(), want)
+			}
+		})
+	}
+
+	cmd := NewFTSearchCmd(ctx, &FTSearchOptions{NoContent: true})
+	readFTReply(t, cmd, "*3\r\n:2\r\n$1\r\na\r\n$1\r\nb\r\n")
+	if got := cmd.Val(); got.Total != 2 || len(got.Docs) != 2 || got.Docs[1].ID != "b" {
+		t.Fatalf("got %+v", got)
+	}
+
+	cmd = NewFTSearchCmd(ctx, nil)
+	if err := cmd.readReply(proto.NewReader(strings.NewReader("*2\r\n:1\r\n$1\r\na\r\n"))); err == nil {
+		t.Fatal("expected an error for a document without fields")
+	}
+}
+
+func TestFTAggregateCmdReadReply(t *testing.T) {
+	want := FTAggregateResult{
+		Total: 1,
+		Rows:  []AggregateRow{{Fields: map[string]interface{}{"brand": "acme", "count": "3"}}},
+	}
+
+	for _, tt := range []struct {
+		name  string
+		reply string
+	}{
+		{"RESP2", "*2\r\n:1\r\n*4\r\n$5\r\nbrand\r\n$4\r\nacme\r\n$5\r\ncount\r\n$1\r\n3\r\n"},
+		{"RESP3", "%2\r\n+total_results\r\n:1\r\n+results\r\n*1\r\n" +
+			"%2\r\n+extra_attributes\r\n%2\r\n$5\r\nbrand\r\n$4\r\nacme\r\n$5\r\ncount\r\n$1\r\n3\r\n+values\r\n*0\r\n"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := NewFTAggregateCmd(ctx)
+			readFTReply(t, cmd, tt.reply)
+			if !reflect.DeepEqual(cmd.Val(), want) {
+				t.Fatalf("got %+v, wanted %+v", cmd.Val(), want)
+			}
+		})
+	}
+}
+
+func TestFTInfoCmdReadReply(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		reply string
+	}{
+		{"RESP2", "*12\r\n" +
+			"$10\r\nindex_name\r\n$3\r\nidx\r\n" +
+			"$16\r\nindex_definition\r\n*4\r\n$8\r\nkey_type\r\n$4\r\nHASH\r\n$8\r\nprefixes\r\n*1\r\n$4\r\ndoc:\r\n" +
+			"$10\r\nattributes\r\n*1\r\n*9\r\n$10\r\nidentifier\r\n$5\r\ntitle\r\n$9\r\nattribute\r\n$5\r\ntitle\r\n" +
+			"$4\r\ntype\r\n$4\r\nTEXT\r\n$6\r\nWEIGHT\r\n$1\r\n2\r\n$8\r\nSORTABLE\r\n" +
+			"$8\r\nnum_docs\r\n$1\r\n3\r\n" +
+			"$14\r\ninverted_sz_mb\r\n$4\r\n0.25\r\n" +
+			"$8\r\nindexing\r\n:0\r\n"},
+		{"RESP3", "%6\r\n" +
+			"+index_name\r\n$3\r\nidx\r\n" +
+			"+index_definition\r\n%2\r\n+key_type\r\n+HASH\r\n+prefixes\r\n*1\r\n$4\r\ndoc:\r\n" +
+			"+attributes\r\n*1\r\n%5\r\n+identifier\r\n$5\r\ntitle\r\n+attribute\r\n$5\r\ntitle\r\n" +
+			"+type\r\n+TEXT\r\n+WEIGHT\r\n,2\r\n+flags\r\n*1\r\n+SORTABLE\r\n" +
+			"+num_docs\r\n:3\r\n" +
+			"+inverted_sz_mb\r\n,0.25\r\n" +
+			"+indexing\r\n:0\r\n"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := NewFTInfoCmd(ctx)
+			readFTReply(t, cmd, tt.reply)
+
+			info := cmd.Val()
+			if info.IndexName != "idx" || info.NumDocs != 3 || info.InvertedSzMB != 0.25 || info.Indexing {
+				t.Fatalf("got %+v", info)
+			}
+			def := IndexDefinition{KeyType: "HASH", Prefixes: []string{"doc:"}}
+			if !reflect.DeepEqual(info.IndexDefinition, def) {
+				t.Fatalf("got %+v, wanted %+v", info.IndexDefinition, def)
+			}
+			attrs := []FTAttribute{{Identifier: "title", Attribute: "title", Type: "TEXT", Weight: 2, Sortable: true}}
+			if !reflect.DeepEqual(info.Attributes, attrs) {
+				t.Fatalf("got %+v, wanted %+v", info.Attributes, attrs)
+			}
+		})
+	}
+}
+
+func TestFTInfoCmdIndexingProgress(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		reply string
+	}{
+		{"RESP2", "*8\r\n" +
+			"$10\r\nindex_name\r\n$11\r\nproducts_v2\r\n" +
+			"$22\r\nhash_indexing_failures\r\n:2\r\n" +
+			"$8\r\nindexing\r\n:1\r\n" +
+			"$15\r\npercent_indexed\r\n$19\r\n0.42857142857142855\r\n"},
+		{"RESP3", "%4\r\n" +
+			"+index_name\r\n$11\r\nproducts_v2\r\n" +
+			"+hash_indexing_failures\r\n:2\r\n" +
+			"+indexing\r\n:1\r\n" +
+			"+percent_indexed\r\n,0.42857142857142855\r\n"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := NewFTInfoCmd(ctx)
+			readFTReply(t, cmd, tt.reply)
+
+			info := cmd.Val()
+			if info.IndexName != "products_v2" || info.HashIndexingFailures != 2 ||
+				!info.Indexing || info.PercentIndexed != 3.0/7 {
+				t.Fatalf("got %+v", info)
+			}
+		})
+	}
+}
+
+func TestFTSpellCheckCmdReadReply(t *testing.T) {
+	want := []SpellCheckResult{{
+		Term: "hockye",
+		Suggestions: []SpellCheckSuggestion{
+			{Score: 0.5, Suggestion: "hockey"},
+			{Score: 0.25, Suggestion: "hocky"},
+		},
+	}}
+
+	for _, tt := range []struct {
+		name  string
+		reply string
+	}{
+		{"RESP2", "*1\r\n*3\r\n$4\r\nTERM\r\n$6\r\nhockye\r\n*2\r\n" +
+			"*2\r\n$3\r\n0.5\r\n$6\r\nhockey\r\n*2\r\n$4\r\n0.25\r\n$5\r\nhocky\r\n"},
+		{"RESP3", "%1\r\n+results\r\n%1\r\n$6\r\nhockye\r\n*2\r\n" +
+			"%1\r\n$6\r\nhockey\r\n,0.5\r\n%1\r\n$5\r\nhocky\r\n,0.25\r\n"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := NewFTSpellCheckCmd(ctx)
+			readFTReply(t, cmd, tt.reply)
+			if !reflect.DeepEqual(cmd.Val(), want) {
+				t.Fatalf("got %+v, wanted %+v", cmd.Val(), want)
+			}
+		})
+	}
+}
+
+func TestFTProfileCmdReadReply(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		reply string
+	}{
+		{"RESP2", "*2\r\n*3\r\n:1\r\n$5\r\ndoc:1\r\n*0\r\n" +
+			"*2\r\n*2\r\n$18\r\nTotal profile time\r\n$3\r\n0.5\r\n*3\r\n$17\r\nIterators profile\r\n$1\r\na\r\n$1\r\nb\r\n"},
+		{"RESP3", "%3\r\n+total_results\r\n:1\r\n+results\r\n*1\r\n%2\r\n+id\r\n$5\r\ndoc:1\r\n+extra_attributes\r\n%0\r\n" +
+			"+profile\r\n%2\r\n+Total profile time\r\n$3\r\n0.5\r\n+Iterators profile\r\n*2\r\n$1\r\na\r\n$1\r\nb\r\n"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := NewFTProfileCmd(ctx, nil)
+			readFTReply(t, cmd, tt.reply)
+
+			res := cmd.Val()
+			if res.Search == nil || res.Search.Total != 1 || res.Search.Docs[0].ID != "doc:1" {
+				t.Fatalf("got %+v", res.Search)
+			}
+			if res.Profile["Total profile time"] != "0.5" {
+				t.Fatalf("got %+v", res.Profile)
+			}
+			iterators := []interface{}{"a", "b"}
+			if !reflect.DeepEqual(res.Profile["Iterators profile"], iterators) {
+				t.Fatalf("got %+v", res.Profile)
+			}
+		})
+	}
+}
+
+func TestFTCommandArgs(t *testing.T) {
+	c := cmdable(func(ctx context.Context, cmd Cmder) error { return nil })
+
+	for _, tt := range []struct {
+		name string
+		cmd  Cmder
+		want []interface{}
+	}{
+		{
+			"FT.CREATE",
+			c.FTCreate(ctx, "idx", &FTCreateOptions{OnHash: true, Prefix: []interface{}{"doc:"}},
+				&FieldSchema{FieldName: "title", FieldType: SearchFieldTypeText, Weight: 2, Sortable: true},
+				&FieldSchema{FieldName: "tags", As: "t", FieldType: SearchFieldTypeTag, Separator: ";"},
+			),
+			[]interface{}{
+				"FT.CREATE", "idx", "ON", "HASH", "PREFIX", 1, "doc:", "SCHEMA",
+				"title", "TEXT", "WEIGHT", 2.0, "SORTABLE",
+				"tags", "AS", "t", "TAG", "SEPARATOR", ";",
+			},
+		},
+		{
+			"FT.SEARCH",
+			c.FTSearchWithArgs(ctx, "idx", "@title:$q", &FTSearchOptions{
+				WithScores:     true,
+				Return:         []FTSearchReturn{{FieldName: "title"}, {FieldName: "$.price", As: "price"}},
+				SortBy:         &FTSearchSortBy{FieldName: "price", Desc: true},
+				Limit:          10,
+				Params:         map[string]interface{}{"q": "hello", "a": 1},
+				DialectVersion: 2,
+			}),
+			[]interface{}{
+				"FT.SEARCH", "idx", "@title:$q", "WITHSCORES",
+				"RETURN", 4, "title", "$.price", "AS", "price",
+				"SORTBY", "price", "DESC", "LIMIT", 0, 10,
+				"PARAMS", 4, "a", 1, "q", "hello", "DIALECT", 2,
+			},
+		},
+		{
+			"FT.AGGREGATE",
+			c.FTAggregateWithArgs(ctx, "idx", "*", &FTAggregateOptions{
+				GroupBy: []FTAggregateGroupBy{{
+					Fields: []interface{}{"@brand"},
+					Reduce: []FTAggregateReducer{{Reducer: SearchCount, As: "n"}},
+				}},
+				SortBy: []FTAggregateSortBy{{FieldName: "@n", Desc: true}},
+			}),
+			[]interface{}{
+				"FT.AGGREGATE", "idx", "*",
+				"GROUPBY", 1, "@brand", "REDUCE", "COUNT", 0, "AS", "n",
+				"SORTBY", 2, "@n", "DESC",
+			},
+		},
+		{
+			"FT.PROFILE",
+			c.FTProfile(ctx, "idx", "*", &FTProfileOptions{Limited: true, SearchOptions: &FTSearchOptions{NoContent: true}}),
+			[]interface{}{"FT.PROFILE", "idx", "SEARCH", "LIMITED", "QUERY", "*", "NOCONTENT"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cmd.Args(); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %v, wanted %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFTAggregateCmdReadCursorReply(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		reply string
+	}{
+		{"RESP2", "*2\r\n*2\r\n:1\r\n*2\r\n$1\r\na\r\n$1\r\n1\r\n:42\r\n"},
+		{"RESP3", "*2\r\n%2\r\n+total_results\r\n:1\r\n+results\r\n*1\r\n" +
+			"%1\r\n+extra_attributes\r\n%1\r\n$1\r\na\r\n$1\r\n1\r\n:42\r\n"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := NewFTAggregateCmd(ctx)
+			readFTReply(t, cmd, tt.reply)
+			want := FTAggregateResult{
+				Total:    1,
+				Rows:     []AggregateRow{{Fields: map[string]interface{}{"a": "1"}}},
+				CursorID: 42,
+			}
+			if !reflect.DeepEqual(cmd.Val(), want) {
+				t.Fatalf("got %+v, wanted %+v", cmd.Val(), want)
+			}
+		})
+	}
+}
+
+func TestAggregateIterator(t *testing.T) {
+	batches := [][]string{{"1", "2"}, {}, {"3"}}
+	var sent [][]interface{}
+	var readErr error
+	process := func(ctx context.Context, cmd Cmder) error {
+		sent = append(sent, cmd.Args())
+		if readErr != nil && cmd.Args()[1] == "READ" {
+			cmd.SetErr(readErr)
+			return readErr
+		}
+		switch cmd := cmd.(type) {
+		case *FTAggregateCmd:
+			batch := batches[0]
+			batches = batches[1:]
+			res := FTAggregateResult{Rows: []AggregateRow{}}
+			for _, v := range batch {
+				res.Rows = append(res.Rows, AggregateRow{Fields: map[string]interface{}{"n": v}})
+			}
+			if len(batches) > 0 {
+				res.CursorID = 7
+			}
+			cmd.SetVal(res)
+		case *StatusCmd:
+			cmd.SetVal("OK")
+		}
+		return nil
+	}
+
+	it := cmdable(process).FTAggregateIterator(ctx, "idx", "*", &FTAggregateOptions{
+		WithCursorOptions: &FTAggregateWithCursor{Count: 2},
+	})
+	var got []interface{}
+	for it.Next(ctx) {
+		got = append(got, it.Val().Fields["n"])
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, []interface{}{"1", "2", "3"}) {
+		t.Fatalf("got %v", got)
+	}
+	want := [][]interface{}{
+		{"FT.AGGREGATE", "idx", "*", "WITHCURSOR", "COUNT", 2},
+		{"FT.CURSOR", "READ", "idx", int64(7), "COUNT", 2},
+		{"FT.CURSOR", "READ", "idx", int64(7), "COUNT", 2},
+	}
+	if !reflect.DeepEqual(sent, want) {
+		t.Fatalf("got %v, wanted %v", sent, want)
+	}
+
+	// Canceling the context deletes the cursor.
+	batches = [][]string{{"1"}, {"2"}}
+	sent = nil
+	it = cmdable(process).FTAggregateIterator(ctx, "idx", "*", nil)
+	cctx, cancel := context.WithCancel(ctx)
+	if !it.Next(cctx) {
+		t.Fatal(it.Err())
+	}
+	cancel()
+	if it.Next(cctx) || it.Err() != context.Canceled {
+		t.Fatalf("got %v, wanted context.Canceled", it.Err())
+	}
+	if last := sent[len(sent)-1]; !reflect.DeepEqual(last, []interface{}{"FT.CURSOR", "DEL", "idx", int64(7)}) {
+		t.Fatalf("got %v", last)
+	}
+
+	// A failed read deletes the cursor.
+	batches = [][]string{{"1"}, {"2"}}
+	sent = nil
+	readErr = errors.New("read failed")
+	it = cmdable(process).FTAggregateIterator(ctx, "idx", "*", nil)
+	if !it.Next(ctx) {
+		t.Fatal(it.Err())
+	}
+	if it.Next(ctx) {
+		t.Fatal("got a row from a failed read")
+	}
+	if it.Err() != readErr {
+		t.Fatalf("got %v, wanted %v", it.Err(), readErr)
+	}
+	if last := sent[len(sent)-1]; !reflect.DeepEqual(last, []interface{}{"FT.CURSOR", "DEL", "idx", int64(7)}) {
+		t.Fatalf("got %v", last)
+	}
+	readErr = nil
+
+	// Pipelines can't iterate.
+	it = (&Pipeline{}).FTAggregateIterator(ctx, "idx", "*", nil)
+	if it.Next(ctx) || it.Err() == nil {
+		t.Fatalf("got %v, wanted an error", it.Err())
+	}
+
+	// Close deletes the cursor once.
+	batches = [][]string{{"1"}, {"2"}}
+	sent = nil
+	it = cmdable(process).FTAggregateIterator(ctx, "idx", "*", nil)
+	if err := it.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := it.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if it.Next(ctx) || len(sent) != 2 {
+		t.Fatalf("got %v", sent)
+	}
+}
+
+func TestFTSuggestionCmdReadReply(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		options *FTSugGetOptions
+		reply   string
+		want    []Suggestion
+	}{
+		{"terms", nil, "*2\r\n$5\r\nhello\r\n$4\r\nhelp\r\n", []Suggestion{{Term: "hello"}, {Term: "help"}}},
+		{"nil", nil, "*-1\r\n", []Suggestion{}},
+		{"RESP3 null", nil, "_\r\n", []Suggestion{}},
+		{
+			"RESP2 scores and payloads", &FTSugGetOptions{WithScores: true, WithPayloads: true},
+			"*6\r\n$5\r\nhello\r\n$3\r\n0.5\r\n$1\r\np\r\n$4\r\nhelp\r\n$4\r\n0.25\r\n$-1\r\n",
+			[]Suggestion{{Term: "hello", Score: 0.5, Payload: "p"}, {Term: "help", Score: 0.25}},
+		},
+		{
+			"RESP3 scores", &FTSugGetOptions{WithScores: true},
+			"*2\r\n$5\r\nhello\r\n,0.5\r\n",
+			[]Suggestion{{Term: "hello", Score: 0.5}},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := NewFTSuggestionCmd(ctx, tt.options)
+			readFTReply(t, cmd, tt.reply)
+			if !reflect.DeepEqual(cmd.Val(), tt.want) {
+				t.Fatalf("got %+v, wanted %+v", cmd.Val(), tt.want)
+			}
+		})
+	}
+}
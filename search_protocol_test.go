@@ -0,0 +1,286 @@
+package redis_test
+
+import (
+	"context"
+
+	. "github.com/bsm/ginkgo/v2"
+	. "github.com/bsm/gomega"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var _ = Describe("RediSearch commands", Label("search"), func() {
+	ctx := context.TODO()
+
+	var client2, client3 *redis.Client
+
+	// both runs fn with RESP2 and with RESP3, and checks that the typed
+	// results match before returning the RESP3 one.
+	both := func(fn func(client *redis.Client) interface{}) interface{} {
+		res2 := fn(client2)
+		res3 := fn(client3)
+		Expect(res3).To(Equal(res2))
+		return res3
+	}
+
+	title := &redis.FieldSchema{FieldName: "title", FieldType: redis.SearchFieldTypeText, Sortable: true}
+	price := &redis.FieldSchema{FieldName: "price", FieldType: redis.SearchFieldTypeNumeric, Sortable: true}
+	color := &redis.FieldSchema{FieldName: "color", FieldType: redis.SearchFieldTypeTag}
+
+	waitForIndexing := func(index string) {
+		Eventually(func() bool {
+			info, err := client2.FTInfo(ctx, index).Result()
+			Expect(err).NotTo(HaveOccurred())
+			return info.Indexing
+		}, "5s", "10ms").Should(BeFalse())
+	}
+
+	BeforeEach(func() {
+		client2 = redis.NewClient(&redis.Options{Addr: rediStackAddr, Protocol: 2})
+		client3 = redis.NewClient(&redis.Options{Addr: rediStackAddr, Protocol: 3})
+		Expect(client2.FlushDB(ctx).Err()).NotTo(HaveOccurred())
+		for _, index := range client2.FTList(ctx).Val() {
+			Expect(client2.FTDropIndex(ctx, index).Err()).NotTo(HaveOccurred())
+		}
+
+		err := client2.FTCreate(ctx, "idx",
+			&redis.FTCreateOptions{OnHash: true, Prefix: []interface{}{"doc:"}},
+			title, price, color).Err()
+		Expect(err).NotTo(HaveOccurred())
+		docs := [][]interface{}{
+			{"doc:1", "title", "hello world", "price", 10, "color", "red"},
+			{"doc:2", "title", "hello redis", "price", 20, "color", "blue"},
+			{"doc:3", "title", "goodbye world", "price", 30, "color", "red"},
+		}
+		for _, doc := range docs {
+			Expect(client2.HSet(ctx, doc[0].(string), doc[1:]...).Err()).NotTo(HaveOccurred())
+		}
+		waitForIndexing("idx")
+	})
+
+	AfterEach(func() {
+		Expect(client2.Close()).NotTo(HaveOccurred())
+		Expect(client3.Close()).NotTo(HaveOccurred())
+	})
+
+	It("FT.SEARCH", Label("ftsearch"), func() {
+		res := both(func(client *redis.Client) interface{} {
+			res, err := client.FTSearchWithArgs(ctx, "idx", "hello", &redis.FTSearchOptions{
+				Return: []redis.FTSearchReturn{{FieldName: "title"}, {FieldName: "price"}},
+				SortBy: &redis.FTSearchSortBy{FieldName: "price", Desc: true},
+			}).Result()
+			Expect(err).NotTo(HaveOccurred())
+			return res
+		}).(redis.FTSearchResult)
+		Expect(res).To(Equal(redis.FTSearchResult{
+			Total: 2,
+			Docs: []redis.Document{
+				{ID: "doc:2", Fields: map[string]string{"title": "hello redis", "price": "20"}},
+				{ID: "doc:1", Fields: map[string]string{"title": "hello world", "price": "10"}},
+			},
+		}))
+
+		res = both(func(client *redis.Client) interface{} {
+			res, err := client.FTSearchWithArgs(ctx, "idx", "@color:{red}", &redis.FTSearchOptions{
+				NoContent:  true,
+				WithScores: true,
+				SortBy:     &redis.FTSearchSortBy{FieldName: "price", Asc: true},
+			}).Result()
+			Expect(err).NotTo(HaveOccurred())
+			return res
+		}).(redis.FTSearchResult)
+		Expect(res.Total).To(BeEquivalentTo(2))
+		Expect(res.Docs).To(HaveLen(2))
+		Expect(res.Docs[0].ID).To(Equal("doc:1"))
+		Expect(res.Docs[0].Score).NotTo(BeNil())
+		Expect(res.Docs[0].Fields).To(BeEmpty())
+		Expect(res.Docs[1].ID).To(Equal("doc:3"))
+	})
+
+	It("FT.AGGREGATE", Label("ftaggregate"), func() {
+		options := &redis.FTAggregateOptions{
+			GroupBy: []redis.FTAggregateGroupBy{{
+				Fields: []interface{}{"@color"},
+				Reduce: []redis.FTAggregateReducer{{Reducer: redis.SearchSum, Args: []interface{}{"@price"}, As: "total"}},
+			}},
+			SortBy: []redis.FTAggregateSortBy{{FieldName: "@color", Asc: true}},
+		}
+		res := both(func(client *redis.Client) interface{} {
+			res, err := client.FTAggregateWithArgs(ctx, "idx", "*", options).Result()
+			Expect(err).NotTo(HaveOccurred())
+			return res
+		}).(redis.FTAggregateResult)
+		Expect(res.Rows).To(Equal([]redis.AggregateRow{
+			{Fields: map[string]interface{}{"color": "blue", "total": "20"}},
+			{Fields: map[string]interface{}{"color": "red", "total": "40"}},
+		}))
+
+		rows := both(func(client *redis.Client) interface{} {
+			res, err := client.FTAggregateWithArgs(ctx, "idx", "*", &redis.FTAggregateOptions{
+				Load:              []redis.FTAggregateLoad{{Field: "title"}},
+				SortBy:            []redis.FTAggregateSortBy{{FieldName: "@title", Asc: true}},
+				WithCursor:        true,
+				WithCursorOptions: &redis.FTAggregateWithCursor{Count: 2},
+			}).Result()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.CursorID).NotTo(BeZero())
+			rows := res.Rows
+
+			next, err := client.FTCursorRead(ctx, "idx", res.CursorID, 2).Result()
+			Expect(err).NotTo(HaveOccurred())
+			rows = append(rows, next.Rows...)
+			if next.CursorID != 0 {
+				Expect(client.FTCursorDel(ctx, "idx", next.CursorID).Err()).NotTo(HaveOccurred())
+			}
+			return rows
+		}).([]redis.AggregateRow)
+		Expect(rows).To(Equal([]redis.AggregateRow{
+			{Fields: map[string]interface{}{"title": "goodbye world"}},
+			{Fields: map[string]interface{}{"title": "hello redis"}},
+			{Fields: map[string]interface{}{"title": "hello world"}},
+		}))
+	})
+
+	It("FT.INFO and FT.ALTER", Label("ftinfo", "ftalter"), func() {
+		info := func(client *redis.Client) interface{} {
+			res, err := client.FTInfo(ctx, "idx").Result()
+			Expect(err).NotTo(HaveOccurred())
+			// The statistics and the timings change between calls.
+			return []interface{}{res.IndexName, res.IndexDefinition, res.Attributes, res.NumDocs}
+		}
+		res := both(info).([]interface{})
+		Expect(res[0]).To(Equal("idx"))
+		Expect(res[1].(redis.IndexDefinition).KeyType).To(Equal("HASH"))
+		Expect(res[1].(redis.IndexDefinition).Prefixes).To(Equal([]string{"doc:"}))
+		attrs := res[2].([]redis.FTAttribute)
+		Expect(attrs).To(HaveLen(3))
+		Expect(attrs[0].Attribute).To(Equal("title"))
+		Expect(attrs[0].Type).To(Equal("TEXT"))
+		Expect(attrs[0].Sortable).To(BeTrue())
+		Expect(attrs[1].Type).To(Equal("NUMERIC"))
+		Expect(attrs[2].Type).To(Equal("TAG"))
+		Expect(attrs[2].Separator).To(Equal(","))
+		Expect(res[3]).To(BeEquivalentTo(3))
+
+		body := &redis.FieldSchema{FieldName: "body", FieldType: redis.SearchFieldTypeText}
+		Expect(client2.FTAlter(ctx, "idx", false, body).Err()).NotTo(HaveOccurred())
+		attrs = both(info).([]interface{})[2].([]redis.FTAttribute)
+		Expect(attrs).To(HaveLen(4))
+		Expect(attrs[3].Attribute).To(Equal("body"))
+	})
+
+	It("FT.ALIASADD, FT.ALIASUPDATE and FT.ALIASDEL", Label("ftalias"), func() {
+		err := client2.FTCreate(ctx, "idx2",
+			&redis.FTCreateOptions{OnHash: true, Prefix: []interface{}{"doc:3"}}, title).Err()
+		Expect(err).NotTo(HaveOccurred())
+		waitForIndexing("idx2")
+
+		total := func(client *redis.Client) interface{} {
+			res, err := client.FTSearch(ctx, "alias", "*").Result()
+			Expect(err).NotTo(HaveOccurred())
+			return res.Total
+		}
+
+		Expect(client2.FTAliasAdd(ctx, "idx", "alias").Val()).To(Equal("OK"))
+		Expect(both(total)).To(BeEquivalentTo(3))
+		Expect(client3.FTAliasUpdate(ctx, "idx2", "alias").Val()).To(Equal("OK"))
+		Expect(both(total)).To(BeEquivalentTo(1))
+		Expect(client2.FTAliasDel(ctx, "alias").Val()).To(Equal("OK"))
+		Expect(client3.FTSearch(ctx, "alias", "*").Err()).To(HaveOccurred())
+	})
+
+	It("FT.EXPLAIN", Label("ftexplain"), func() {
+		res := both(func(client *redis.Client) interface{} {
+			res, err := client.FTExplain(ctx, "idx", "@title:hello @price:[0 15]").Result()
+			Expect(err).NotTo(HaveOccurred())
+			return res
+		}).(string)
+		Expect(res).To(ContainSubstring("INTERSECT"))
+		Expect(res).To(ContainSubstring("NUMERIC"))
+	})
+
+	It("FT.PROFILE", Label("ftprofile"), func() {
+		res := both(func(client *redis.Client) interface{} {
+			res, err := client.FTProfile(ctx, "idx", "hello", &redis.FTProfileOptions{
+				SearchOptions: &redis.FTSearchOptions{NoContent: true, SortBy: &redis.FTSearchSortBy{FieldName: "price", Asc: true}},
+			}).Result()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.Profile).NotTo(BeEmpty())
+			// The profile has the timings, and a different layout in RESP3.
+			return res.Search
+		}).(*redis.FTSearchResult)
+		Expect(res).To(Equal(&redis.FTSearchResult{
+			Total: 2,
+			Docs:  []redis.Document{{ID: "doc:1"}, {ID: "doc:2"}},
+		}))
+
+		agg := both(func(client *redis.Client) interface{} {
+			res, err := client.FTProfile(ctx, "idx", "*", &redis.FTProfileOptions{
+				Aggregate: true,
+				AggregateOptions: &redis.FTAggregateOptions{
+					GroupBy: []redis.FTAggregateGroupBy{{
+						Fields: []interface{}{"@color"},
+						Reduce: []redis.FTAggregateReducer{{Reducer: redis.SearchCount, As: "count"}},
+					}},
+					SortBy: []redis.FTAggregateSortBy{{FieldName: "@color", Asc: true}},
+				},
+			}).Result()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.Profile).NotTo(BeEmpty())
+			return res.Aggregate.Rows
+		}).([]redis.AggregateRow)
+		Expect(agg).To(Equal([]redis.AggregateRow{
+			{Fields: map[string]interface{}{"color": "blue", "count": "1"}},
+			{Fields: map[string]interface{}{"color": "red", "count": "2"}},
+		}))
+	})
+
+	It("FT.TAGVALS and FT._LIST", Label("fttagvals", "ftlist"), func() {
+		res := both(func(client *redis.Client) interface{} {
+			res, err := client.FTTagVals(ctx, "idx", "color").Result()
+			Expect(err).NotTo(HaveOccurred())
+			return res
+		})
+		Expect(res).To(ConsistOf("red", "blue"))
+
+		res = both(func(client *redis.Client) interface{} {
+			res, err := client.FTList(ctx).Result()
+			Expect(err).NotTo(HaveOccurred())
+			return res
+		})
+		Expect(res).To(Equal([]string{"idx"}))
+	})
+
+	It("FT.SPELLCHECK and FT.DICT*", Label("ftspellcheck", "ftdict"), func() {
+		res := both(func(client *redis.Client) interface{} {
+			res, err := client.FTSpellCheck(ctx, "idx", "wrld").Result()
+			Expect(err).NotTo(HaveOccurred())
+			return res
+		}).([]redis.SpellCheckResult)
+		Expect(res).To(HaveLen(1))
+		Expect(res[0].Term).To(Equal("wrld"))
+		Expect(res[0].Suggestions).To(HaveLen(1))
+		Expect(res[0].Suggestions[0].Suggestion).To(Equal("world"))
+
+		Expect(client2.FTDictAdd(ctx, "dict", "wrld", "hllo").Val()).To(BeEquivalentTo(2))
+		dump := both(func(client *redis.Client) interface{} {
+			res, err := client.FTDictDump(ctx, "dict").Result()
+			Expect(err).NotTo(HaveOccurred())
+			return res
+		})
+		Expect(dump).To(ConsistOf("wrld", "hllo"))
+
+		// The terms of the dictionary aren't misspelled anymore.
+		res = both(func(client *redis.Client) interface{} {
+			res, err := client.FTSpellCheckWithArgs(ctx, "idx", "wrld", &redis.FTSpellCheckOptions{
+				Terms: &redis.FTSpellCheckTerms{Inclusion: "EXCLUDE", Dictionary: "dict"},
+			}).Result()
+			Expect(err).NotTo(HaveOccurred())
+			return res
+		}).([]redis.SpellCheckResult)
+		Expect(res).To(BeEmpty())
+
+		Expect(client3.FTDictDel(ctx, "dict", "wrld", "hllo").Val()).To(BeEquivalentTo(2))
+		Expect(client2.FTDictDump(ctx, "dict").Val()).To(BeEmpty())
+	})
+})
@@ -0,0 +1,300 @@
+package redis
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// QueryNode is a clause of a search query built by QueryText, QueryTag,
+// QueryNumericRange and the other Query functions. Values are escaped,
+// so they can be taken from user input.
+type QueryNode struct {
+	expr string
+	err  error
+}
+
+// String returns the clause in the query syntax.
+func (n QueryNode) String() string {
+	return n.expr
+}
+
+// Err returns the error of the invalid arguments of the clause or of one
+// of its clauses, if any.
+func (n QueryNode) Err() error {
+	return n.err
+}
+
+// QueryRaw returns a clause with expr as is, without escaping.
+func QueryRaw(expr string) QueryNode {
+	return QueryNode{expr: expr}
+}
+
+// QueryText matches documents containing all the terms, in field or, if
+// field is empty, in any TEXT field.
+func QueryText(field string, terms ...string) QueryNode {
+	escaped := make([]string, 0, len(terms))
+	for _, t := range terms {
+		escaped = append(escaped, EscapeQuery(t))
+	}
+	return fieldNode(field, joinQuery(escaped, " "))
+}
+
+// QueryPhrase matches documents containing the exact phrase.
+func QueryPhrase(field string, phrase string) QueryNode {
+	phrase = strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(phrase)
+	return fieldNode(field, `"`+phrase+`"`)
+}
+
+// QueryPrefix matches documents containing a term starting with prefix.
+func QueryPrefix(field string, prefix string) QueryNode {
+	return fieldNode(field, EscapeQuery(prefix)+"*")
+}
+
+// QueryNumericRange matches documents whose NUMERIC field is between min
+// and max, both inclusive. Use math.Inf for an open range.
+func QueryNumericRange(field string, min, max float64) QueryNode {
+	return numericRange(field, formatBound(min), formatBound(max))
+}
+
+// QueryNumericRangeExclusive is like QueryNumericRange with both bounds excluded.
+func QueryNumericRangeExclusive(field string, min, max float64) QueryNode {
+	return numericRange(field, exclusiveBound(min), exclusiveBound(max))
+}
+
+func exclusiveBound(f float64) string {
+	if math.IsInf(f, 0) {
+		return formatBound(f)
+	}
+	return "(" + formatBound(f)
+}
+
+// QueryNumericEqual matches documents whose NUMERIC field is equal to value.
+func QueryNumericEqual(field string, value float64) QueryNode {
+	return QueryNumericRange(field, value, value)
+}
+
+func numericRange(field, min, max string) QueryNode {
+	return QueryNode{expr: "@" + field + ":[" + min + " " + max + "]"}
+}
+
+func formatBound(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+inf"
+	case math.IsInf(f, -1):
+		return "-inf"
+	default:
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+}
+
+// QueryTag matches documents whose TAG field has any of the values. Without
+// values, the clause is empty and left out of the query.
+func QueryTag(field string, values ...string) QueryNode {
+	if len(values) == 0 {
+		return QueryNode{}
+	}
+	escaped := make([]string, 0, len(values))
+	for _, v := range values {
+		escaped = append(escaped, EscapeQuery(v))
+	}
+	return QueryNode{expr: "@" + field + ":{" + strings.Join(escaped, " | ") + "}"}
+}
+
+// QueryGeo matches documents whose GEO field is within radius of the point.
+// The unit is m, km, mi or ft, other units are reported by Err.
+func QueryGeo(field string, longitude, latitude, radius float64, unit string) QueryNode {
+	unit = strings.ToLower(unit)
+	node := QueryNode{expr: "@" + field + ":[" +
+		formatBound(longitude) + " " +
+		formatBound(latitude) + " " +
+		formatBound(radius) + " " +
+		unit + "]"}
+	switch unit {
+	case "m", "km", "mi", "ft":
+	default:
+		node.err = fmt.Errorf("redis: QueryGeo unit must be m, km, mi or ft, got %q", unit)
+	}
+	return node
+}
+
+// QueryVectorRange matches documents whose VECTOR field is within radius
+// of the vector bound to the param, see SearchQuery.Param.
+func QueryVectorRange(field string, radius float64, param string) QueryNode {
+	return QueryNode{expr: "@" + field + ":[VECTOR_RANGE " + formatBound(radius) + " $" + param + "]"}
+}
+
+// QueryIntersect matches documents matching all the clauses.
+func QueryIntersect(nodes ...QueryNode) QueryNode {
+	return QueryNode{expr: joinQuery(nodeExprs(nodes), " "), err: nodesErr(nodes)}
+}
+
+// QueryUnion matches documents matching any of the clauses.
+func QueryUnion(nodes ...QueryNode) QueryNode {
+	return QueryNode{expr: joinQuery(nodeExprs(nodes), " | "), err: nodesErr(nodes)}
+}
+
+// QueryNot matches documents not matching the clause.
+func QueryNot(node QueryNode) QueryNode {
+	if node.expr == "" {
+		return node
+	}
+	return QueryNode{expr: "-" + node.expr, err: node.err}
+}
+
+func fieldNode(field, expr string) QueryNode {
+	if field == "" || expr == "" {
+		return QueryNode{expr: expr}
+	}
+	return QueryNode{expr: "@" + field + ":" + expr}
+}
+
+func nodeExprs(nodes []QueryNode) []string {
+	exprs := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		if n.expr != "" {
+			exprs = append(exprs, n.expr)
+		}
+	}
+	return exprs
+}
+
+func nodesErr(nodes []QueryNode) error {
+	for _, n := range nodes {
+		if n.err != nil {
+			return n.err
+		}
+	}
+	return nil
+}
+
+// joinQuery returns the expressions joined with sep, "" if there are none,
+// so that empty clauses are left out of the query.
+func joinQuery(exprs []string, sep string) string {
+	switch len(exprs) {
+	case 0:
+		return ""
+	case 1:
+		return exprs[0]
+	}
+	return "(" + strings.Join(exprs, sep) + ")"
+}
+
+// EscapeQuery escapes the punctuation and the spaces of s with backslashes,
+// so it's matched as a single term or tag value.
+func EscapeQuery(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		if r <= unicode.MaxASCII && (unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r)) && r != '_' {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+//------------------------------------------------------------------------------
+
+// SearchQuery builds a query string and the FTSearchOptions binding its
+// params.
+//
+//	q := redis.NewSearchQuery(
+//		redis.QueryText("title", "redis"),
+//		redis.QueryNumericRange("price", 10, 20),
+//		redis.QueryNot(redis.QueryTag("tags", "deprecated")),
+//	)
+//	if err := q.Err(); err != nil {
+//		return err
+//	}
+//	res, err := rdb.FTSearchWithArgs(ctx, "idx", q.String(), q.Options()).Result()
+type SearchQuery struct {
+	nodes   []QueryNode
+	params  map[string]interface{}
+	dialect int
+	knn     string
+}
+
+// NewSearchQuery returns a query matching the documents matching all the
+// clauses, or all the documents without clauses.
+func NewSearchQuery(nodes ...QueryNode) *SearchQuery {
+	return &SearchQuery{nodes: nodes}
+}
+
+// And adds clauses that the documents must match.
+func (q *SearchQuery) And(nodes ...QueryNode) *SearchQuery {
+	q.nodes = append(q.nodes, nodes...)
+	return q
+}
+
+// Param binds a value to $name in the query.
+func (q *SearchQuery) Param(name string, value interface{}) *SearchQuery {
+	if q.params == nil {
+		q.params = make(map[string]interface{})
+	}
+	q.params[name] = value
+	return q
+}
+
+// Dialect sets the query dialect. Queries with params or KNN use
+// dialect 2 unless another one is set.
+func (q *SearchQuery) Dialect(version int) *SearchQuery {
+	q.dialect = version
+	return q
+}
+
+// KNN returns the k documents whose VECTOR field is the nearest to the
+// vector bound to the param, among the documents matching the clauses.
+// The distance is returned in the as field, if not empty.
+func (q *SearchQuery) KNN(k int, field string, param string, as string) *SearchQuery {
+	q.knn = "[KNN " + strconv.Itoa(k) + " @" + field + " $" + param
+	if as != "" {
+		q.knn += " AS " + as
+	}
+	q.knn += "]"
+	return q
+}
+
+// Err returns the first error of the clauses, see QueryNode.Err.
+func (q *SearchQuery) Err() error {
+	return nodesErr(q.nodes)
+}
+
+// String returns the query string.
+func (q *SearchQuery) String() string {
+	exprs := nodeExprs(q.nodes)
+	var s string
+	switch {
+	case len(exprs) == 0:
+		s = "*"
+	case q.knn != "":
+		s = "(" + strings.Join(exprs, " ") + ")"
+	default:
+		s = strings.Join(exprs, " ")
+	}
+	if q.knn != "" {
+		s += "=>" + q.knn
+	}
+	return s
+}
+
+// Options returns the FTSearchOptions with the params and the dialect of
+// the query. More options can be set on the result.
+func (q *SearchQuery) Options() *FTSearchOptions {
+	options := &FTSearchOptions{
+		DialectVersion: q.dialect,
+	}
+	if len(q.params) > 0 {
+		options.Params = make(map[string]interface{}, len(q.params))
+		for name, value := range q.params {
+			options.Params[name] = value
+		}
+	}
+	if options.DialectVersion == 0 && (options.Params != nil || q.knn != "") {
+		options.DialectVersion = 2
+	}
+	return options
+}
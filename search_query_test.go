@@ -0,0 +1,121 @@
+package redis_test
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func TestSearchQueryString(t *testing.T) {
+	tests := []struct {
+		name  string
+		query *redis.SearchQuery
+		want  string
+	}{
+		{"empty", redis.NewSearchQuery(), "*"},
+		{"text", redis.NewSearchQuery(redis.QueryText("title", "hello", "world")), "@title:(hello world)"},
+		{"any field", redis.NewSearchQuery(redis.QueryText("", "hello")), "hello"},
+		{"escaped text", redis.NewSearchQuery(redis.QueryText("title", "a-b.c")), `@title:a\-b\.c`},
+		{"phrase", redis.NewSearchQuery(redis.QueryPhrase("title", `say "hi"`)), `@title:"say \"hi\""`},
+		{"prefix", redis.NewSearchQuery(redis.QueryPrefix("title", "re")), "@title:re*"},
+		{"numeric", redis.NewSearchQuery(redis.QueryNumericRange("price", 10, 20.5)), "@price:[10 20.5]"},
+		{
+			"open numeric", redis.NewSearchQuery(redis.QueryNumericRange("price", math.Inf(-1), 20)),
+			"@price:[-inf 20]",
+		},
+		{
+			"exclusive numeric", redis.NewSearchQuery(redis.QueryNumericRangeExclusive("price", 10, math.Inf(1))),
+			"@price:[(10 +inf]",
+		},
+		{"numeric equal", redis.NewSearchQuery(redis.QueryNumericEqual("n", 3)), "@n:[3 3]"},
+		{
+			"tags", redis.NewSearchQuery(redis.QueryTag("tags", "a b", "c|d", "x_y", "é")),
+			`@tags:{a\ b | c\|d | x_y | é}`,
+		},
+		{
+			"geo", redis.NewSearchQuery(redis.QueryGeo("loc", -122.4, 37.7, 5, "km")),
+			"@loc:[-122.4 37.7 5 km]",
+		},
+		{
+			"vector range", redis.NewSearchQuery(redis.QueryVectorRange("vec", 0.2, "blob")),
+			"@vec:[VECTOR_RANGE 0.2 $blob]",
+		},
+		{
+			"intersect union not",
+			redis.NewSearchQuery(
+				redis.QueryUnion(redis.QueryTag("tag", "a"), redis.QueryIntersect(redis.QueryText("", "b"), redis.QueryText("", "c"))),
+				redis.QueryNot(redis.QueryText("title", "foo")),
+			),
+			"(@tag:{a} | (b c)) -@title:foo",
+		},
+		{
+			"and", redis.NewSearchQuery(redis.QueryText("", "a")).And(redis.QueryRaw("@x:[1 2]")),
+			"a @x:[1 2]",
+		},
+		{"empty union", redis.NewSearchQuery(redis.QueryUnion(), redis.QueryIntersect()), "*"},
+		{"empty tags", redis.NewSearchQuery(redis.QueryTag("tags")), "*"},
+		{
+			"empty tags clause", redis.NewSearchQuery(redis.QueryText("", "a"), redis.QueryNot(redis.QueryTag("tags"))),
+			"a",
+		},
+		{
+			"empty clauses", redis.NewSearchQuery(
+				redis.QueryUnion(redis.QueryTag("tag", "a"), redis.QueryIntersect()),
+				redis.QueryNot(redis.QueryText("title")),
+			),
+			"@tag:{a}",
+		},
+		{"knn", redis.NewSearchQuery().KNN(10, "vec", "blob", "dist"), "*=>[KNN 10 @vec $blob AS dist]"},
+		{
+			"hybrid knn", redis.NewSearchQuery(redis.QueryTag("tag", "a")).KNN(5, "vec", "blob", ""),
+			"(@tag:{a})=>[KNN 5 @vec $blob]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.query.String(); got != tt.want {
+				t.Fatalf("got %q, wanted %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryGeoUnit(t *testing.T) {
+	if got := redis.QueryGeo("loc", 1, 2, 3, "MI").String(); got != "@loc:[1 2 3 mi]" {
+		t.Fatalf("got %q", got)
+	}
+
+	if err := redis.NewSearchQuery(redis.QueryGeo("loc", 1, 2, 3, "km")).Err(); err != nil {
+		t.Fatalf("got %v", err)
+	}
+
+	q := redis.NewSearchQuery(
+		redis.QueryText("", "a"),
+		redis.QueryNot(redis.QueryUnion(redis.QueryTag("tag", "b"), redis.QueryGeo("loc", 1, 2, 3, "yd"))),
+	)
+	want := `redis: QueryGeo unit must be m, km, mi or ft, got "yd"`
+	if err := q.Err(); err == nil || err.Error() != want {
+		t.Fatalf("got %v, wanted %s", err, want)
+	}
+}
+
+func TestSearchQueryOptions(t *testing.T) {
+	q := redis.NewSearchQuery(redis.QueryRaw("@name:$name"))
+	if got := q.Options(); !reflect.DeepEqual(got, &redis.FTSearchOptions{}) {
+		t.Fatalf("got %+v", got)
+	}
+
+	q.Param("name", "joe")
+	want := &redis.FTSearchOptions{Params: map[string]interface{}{"name": "joe"}, DialectVersion: 2}
+	if got := q.Options(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %+v, wanted %+v", got, want)
+	}
+
+	q.Dialect(3)
+	if got := q.Options(); got.DialectVersion != 3 {
+		t.Fatalf("got dialect %d, wanted 3", got.DialectVersion)
+	}
+}
@@ -0,0 +1,143 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9/internal"
+)
+
+// FTRebuildProgress is reported while the new index is being built.
+type FTRebuildProgress struct {
+	Index            string
+	PercentIndexed   float64
+	NumDocs          int64
+	IndexingFailures int64
+}
+
+type FTRebuildOptions struct {
+	// Create are the options of FT.CREATE for the new index.
+	Create *FTCreateOptions
+	// PollInterval is the interval between the FT.INFO calls, 1 second by default.
+	PollInterval time.Duration
+	// MaxIndexingFailures is the number of documents that may fail to be
+	// indexed before the rebuild is aborted.
+	MaxIndexingFailures int64
+	// DropOld drops the index the alias pointed to, keeping its documents.
+	DropOld bool
+	// OnProgress is called after each FT.INFO call.
+	OnProgress func(FTRebuildProgress)
+}
+
+type FTRebuildResult struct {
+	Index string
+	// PreviousIndex is the index the alias pointed to, if any.
+	PreviousIndex string
+}
+
+// FTRebuildIndex creates a new version of the index behind alias with the
+// schema, named "<alias>_v<n>", and waits for it to index the existing
+// documents. It then points the alias to it with FT.ALIASUPDATE, so the
+// queries going through the alias switch at once, and drops the previous
+// index if options.DropOld is set.
+//
+// When indexing fails or ctx is done, the new index is dropped and the
+// alias is left untouched.
+func FTRebuildIndex(
+	ctx context.Context, c Cmdable, alias string, schema []*FieldSchema, options *FTRebuildOptions,
+) (*FTRebuildResult, error) {
+	if options == nil {
+		options = &FTRebuildOptions{}
+	}
+	interval := options.PollInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	res := &FTRebuildResult{}
+	info, err := c.FTInfo(ctx, alias).Result()
+	switch {
+	case err == nil:
+		res.PreviousIndex = info.IndexName
+	case !isRedisError(err):
+		return nil, err
+	}
+
+	indexes, err := c.FTList(ctx).Result()
+	if err != nil {
+		return nil, err
+	}
+	res.Index = alias + "_v" + strconv.Itoa(nextIndexVersion(alias, indexes))
+
+	if err := c.FTCreate(ctx, res.Index, options.Create, schema...).Err(); err != nil {
+		return nil, err
+	}
+
+	if err := waitIndexed(ctx, c, res.Index, interval, options); err != nil {
+		_ = c.FTDropIndex(context.Background(), res.Index).Err()
+		return nil, err
+	}
+
+	if res.PreviousIndex == "" {
+		err = c.FTAliasAdd(ctx, res.Index, alias).Err()
+	} else {
+		err = c.FTAliasUpdate(ctx, res.Index, alias).Err()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if options.DropOld && res.PreviousIndex != "" {
+		if err := c.FTDropIndex(ctx, res.PreviousIndex).Err(); err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+// nextIndexVersion returns 1 + the highest n of the "<alias>_v<n>" indexes.
+func nextIndexVersion(alias string, indexes []string) int {
+	var last int
+	prefix := alias + "_v"
+	for _, index := range indexes {
+		if !strings.HasPrefix(index, prefix) {
+			continue
+		}
+		if n, err := strconv.Atoi(index[len(prefix):]); err == nil && n > last {
+			last = n
+		}
+	}
+	return last + 1
+}
+
+func waitIndexed(ctx context.Context, c Cmdable, index string, interval time.Duration, options *FTRebuildOptions) error {
+	for {
+		info, err := c.FTInfo(ctx, index).Result()
+		if err != nil {
+			return err
+		}
+
+		if options.OnProgress != nil {
+			options.OnProgress(FTRebuildProgress{
+				Index:            index,
+				PercentIndexed:   info.PercentIndexed,
+				NumDocs:          info.NumDocs,
+				IndexingFailures: info.HashIndexingFailures,
+			})
+		}
+
+		if info.HashIndexingFailures > options.MaxIndexingFailures {
+			return fmt.Errorf("redis: %d documents failed to be indexed in %s", info.HashIndexingFailures, index)
+		}
+		if !info.Indexing && info.PercentIndexed >= 1 {
+			return nil
+		}
+
+		if err := internal.Sleep(ctx, interval); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package redis_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/bsm/ginkgo/v2"
+	. "github.com/bsm/gomega"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var _ = Describe("FTRebuildIndex", Label("search"), func() {
+	ctx := context.TODO()
+
+	for _, protocol := range []int{2, 3} {
+		protocol := protocol
+
+		Context(fmt.Sprintf("RESP%d", protocol), func() {
+			var client *redis.Client
+
+			create := &redis.FTCreateOptions{OnHash: true, Prefix: []interface{}{"doc:"}}
+			title := &redis.FieldSchema{FieldName: "title", FieldType: redis.SearchFieldTypeText}
+			price := &redis.FieldSchema{FieldName: "price", FieldType: redis.SearchFieldTypeNumeric}
+
+			BeforeEach(func() {
+				client = redis.NewClient(&redis.Options{Addr: rediStackAddr, Protocol: protocol})
+				Expect(client.FlushDB(ctx).Err()).NotTo(HaveOccurred())
+				for _, index := range client.FTList(ctx).Val() {
+					Expect(client.FTDropIndex(ctx, index).Err()).NotTo(HaveOccurred())
+				}
+			})
+
+			AfterEach(func() {
+				Expect(client.Close()).NotTo(HaveOccurred())
+			})
+
+			It("switches the alias once the new index is built", func() {
+				for i := 0; i < 100; i++ {
+					err := client.HSet(ctx, fmt.Sprintf("doc:%d", i), "title", "hello", "price", i).Err()
+					Expect(err).NotTo(HaveOccurred())
+				}
+
+				var progress []redis.FTRebuildProgress
+				options := &redis.FTRebuildOptions{
+					Create:       create,
+					PollInterval: 10 * time.Millisecond,
+					OnProgress: func(p redis.FTRebuildProgress) {
+						progress = append(progress, p)
+					},
+				}
+				res, err := redis.FTRebuildIndex(ctx, client, "products", []*redis.FieldSchema{title}, options)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(res).To(Equal(&redis.FTRebuildResult{Index: "products_v1"}))
+				Expect(progress).NotTo(BeEmpty())
+				Expect(progress[len(progress)-1]).To(Equal(redis.FTRebuildProgress{
+					Index:          "products_v1",
+					PercentIndexed: 1,
+					NumDocs:        100,
+				}))
+
+				options.DropOld = true
+				res, err = redis.FTRebuildIndex(ctx, client, "products", []*redis.FieldSchema{title, price}, options)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(res).To(Equal(&redis.FTRebuildResult{Index: "products_v2", PreviousIndex: "products_v1"}))
+				Expect(client.FTList(ctx).Val()).To(ConsistOf("products_v2"))
+
+				search, err := client.FTSearch(ctx, "products", "@price:[10 19]").Result()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(search.Total).To(BeEquivalentTo(10))
+			})
+
+			It("drops the new index when documents fail to be indexed", func() {
+				Expect(client.HSet(ctx, "doc:1", "title", "hello", "price", "free").Err()).NotTo(HaveOccurred())
+
+				_, err := redis.FTRebuildIndex(ctx, client, "products", []*redis.FieldSchema{title, price},
+					&redis.FTRebuildOptions{Create: create, PollInterval: 10 * time.Millisecond})
+				Expect(err).To(MatchError("redis: 1 documents failed to be indexed in products_v1"))
+				Expect(client.FTList(ctx).Val()).To(BeEmpty())
+				Expect(client.FTInfo(ctx, "products").Err()).To(HaveOccurred())
+			})
+		})
+	}
+})
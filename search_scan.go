@@ -0,0 +1,361 @@
+package redis
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/redis/go-redis/v9/internal/hscan"
+)
+
+// Scan scans the document into the struct pointed to by dst.
+//
+// The fields of a HASH document are matched to the struct fields with the
+// `redis` tag, as by MapStringStringCmd.Scan, or with the as= option of the
+// tag, which is the name the fields are returned by when they are aliased
+// in the schema. A JSON document returned as its "$" payload is decoded with
+// encoding/json, so it uses the `json` tags.
+func (d Document) Scan(dst interface{}) error {
+	if payload, ok := d.Fields["$"]; ok {
+		// DIALECT 3 returns the payload in an array.
+		if strings.HasPrefix(payload, "[") {
+			var vals []json.RawMessage
+			if err := json.Unmarshal([]byte(payload), &vals); err != nil {
+				return err
+			}
+			if len(vals) != 1 {
+				return fmt.Errorf("redis: got %d JSON values for document %s, wanted 1", len(vals), d.ID)
+			}
+			return json.Unmarshal(vals[0], dst)
+		}
+		return json.Unmarshal([]byte(payload), dst)
+	}
+
+	strct, err := hscan.Struct(dst)
+	if err != nil {
+		return err
+	}
+	aliases := schemaAliases(reflect.TypeOf(dst).Elem())
+	for k, v := range d.Fields {
+		if name, ok := aliases[k]; ok {
+			k = name
+		}
+		if err := strct.Scan(k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ScanDocuments scans the documents into a []T, see Document.Scan.
+//
+//	res, err := rdb.FTSearch(ctx, "idx", "@title:redis").Result()
+//	products, err := redis.ScanDocuments[Product](res.Docs)
+func ScanDocuments[T any](docs []Document) ([]T, error) {
+	vals := make([]T, len(docs))
+	for i, doc := range docs {
+		if err := doc.Scan(&vals[i]); err != nil {
+			return nil, err
+		}
+	}
+	return vals, nil
+}
+
+//------------------------------------------------------------------------------
+
+// FTSchemaFromStruct returns the FT.CREATE schema of the fields of the struct
+// v, or of the struct v points to, whose `redis` tag has an index type:
+//
+//	type Product struct {
+//		Title string   `redis:"title,text,sortable"`
+//		Price float64  `redis:"price,numeric"`
+//		Tags  []string `redis:"tags,tag,separator=;"`
+//		Notes string   `redis:"notes"` // not indexed
+//	}
+//
+// The type is one of text, tag, numeric, geo, geoshape and vector. It can be
+// followed by the flags sortable, unf, nostem, noindex, casesensitive,
+// withsuffixtrie, indexempty and indexmissing, and by weight=, separator=,
+// phonetic= and as=. The fields whose tag has no type, like
+// `redis:"notes,omitempty"`, are not indexed, and omitempty is ignored.
+//
+// A vector field is indexed with the flat or hnsw flag, FLAT by default, and
+// takes dim= and distance_metric=, and the initial_cap=, block_size=, m=,
+// ef_construction=, ef_runtime= and epsilon= attributes of its algorithm.
+// Its type= is FLOAT32 for Float32Vector and []float32 fields and FLOAT64
+// for Float64Vector and []float64 fields:
+//
+//	Embedding redis.Float32Vector `redis:"embedding,vector,hnsw,dim=384,distance_metric=cosine"`
+func FTSchemaFromStruct(v interface{}) ([]*FieldSchema, error) {
+	return schemaFromStruct(v, false)
+}
+
+// FTJSONSchemaFromStruct is like FTSchemaFromStruct for an index ON JSON.
+// The fields are indexed by the JSON path "$.<name>" AS <name>.
+func FTJSONSchemaFromStruct(v interface{}) ([]*FieldSchema, error) {
+	return schemaFromStruct(v, true)
+}
+
+func schemaFromStruct(v interface{}, onJSON bool) ([]*FieldSchema, error) {
+	typ := reflect.TypeOf(v)
+	if typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("redis: can't derive a schema from %T, wanted a struct", v)
+	}
+
+	var schema []*FieldSchema
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		tag := f.Tag.Get("redis")
+		if f.PkgPath != "" || tag == "" || tag == "-" {
+			continue
+		}
+
+		parts := strings.Split(tag, ",")
+		if len(parts) < 2 || parts[0] == "" {
+			continue
+		}
+
+		field, err := parseSchemaTag(parts, f.Type)
+		if err != nil {
+			return nil, fmt.Errorf("redis: field %s: %w", f.Name, err)
+		}
+		if field == nil {
+			continue
+		}
+		if onJSON {
+			if field.As == "" {
+				field.As = field.FieldName
+			}
+			field.FieldName = "$." + field.FieldName
+		}
+		schema = append(schema, field)
+	}
+	return schema, nil
+}
+
+// parseSchemaTag parses the `redis` tag of a field of type typ. It returns
+// nil if the tag has no index type.
+func parseSchemaTag(parts []string, typ reflect.Type) (*FieldSchema, error) {
+	field := &FieldSchema{FieldName: parts[0]}
+	switch strings.ToLower(parts[1]) {
+	case "text":
+		field.FieldType = SearchFieldTypeText
+	case "tag":
+		field.FieldType = SearchFieldTypeTag
+	case "numeric":
+		field.FieldType = SearchFieldTypeNumeric
+	case "geo":
+		field.FieldType = SearchFieldTypeGeo
+	case "geoshape":
+		field.FieldType = SearchFieldTypeGeoShape
+	case "vector":
+		field.FieldType = SearchFieldTypeVector
+	default:
+		return nil, nil
+	}
+
+	var vec *vectorTag
+	if field.FieldType == SearchFieldTypeVector {
+		vec = &vectorTag{typ: vectorType(typ)}
+	}
+
+	for _, opt := range parts[2:] {
+		name, value, hasValue := strings.Cut(opt, "=")
+		name = strings.ToLower(name)
+		switch name {
+		case "weight", "separator", "phonetic", "as":
+			if !hasValue || value == "" {
+				return nil, fmt.Errorf("option %q needs a value", name)
+			}
+		}
+
+		switch name {
+		case "omitempty":
+		case "sortable":
+			field.Sortable = true
+		case "unf":
+			field.UNF = true
+		case "nostem":
+			field.NoStem = true
+		case "noindex":
+			field.NoIndex = true
+		case "casesensitive":
+			field.CaseSensitive = true
+		case "withsuffixtrie":
+			field.WithSuffixtrie = true
+		case "indexempty":
+			field.IndexEmpty = true
+		case "indexmissing":
+			field.IndexMissing = true
+		case "weight":
+			w, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid weight %q", value)
+			}
+			field.Weight = w
+		case "separator":
+			field.Separator = value
+		case "phonetic":
+			field.PhoneticMatcher = value
+		case "as":
+			field.As = value
+		default:
+			if vec == nil {
+				return nil, fmt.Errorf("unsupported option %q", opt)
+			}
+			if err := vec.parse(name, value, hasValue); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if vec != nil {
+		args, err := vec.args()
+		if err != nil {
+			return nil, err
+		}
+		field.VectorArgs = args
+	}
+	return field, nil
+}
+
+// vectorTag holds the options of a vector field tag.
+type vectorTag struct {
+	hnsw           bool
+	typ            string
+	dim            int
+	distanceMetric string
+	initialCap     int
+	blockSize      int
+	m              int
+	efConstruction int
+	efRuntime      int
+	epsilon        float64
+}
+
+func (v *vectorTag) parse(name, value string, hasValue bool) error {
+	switch name {
+	case "flat", "hnsw":
+		if hasValue {
+			return fmt.Errorf("option %q takes no value", name)
+		}
+		v.hnsw = name == "hnsw"
+		return nil
+	case "type", "distance_metric":
+		if !hasValue || value == "" {
+			return fmt.Errorf("option %q needs a value", name)
+		}
+		if name == "type" {
+			v.typ = strings.ToUpper(value)
+		} else {
+			v.distanceMetric = strings.ToUpper(value)
+		}
+		return nil
+	case "epsilon":
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid epsilon %q", value)
+		}
+		v.epsilon = f
+		return nil
+	}
+
+	var dst *int
+	switch name {
+	case "dim":
+		dst = &v.dim
+	case "initial_cap":
+		dst = &v.initialCap
+	case "block_size":
+		dst = &v.blockSize
+	case "m":
+		dst = &v.m
+	case "ef_construction":
+		dst = &v.efConstruction
+	case "ef_runtime":
+		dst = &v.efRuntime
+	default:
+		return fmt.Errorf("unsupported option %q", name)
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		return fmt.Errorf("invalid %s %q", name, value)
+	}
+	*dst = n
+	return nil
+}
+
+func (v *vectorTag) args() (*FTVectorArgs, error) {
+	switch {
+	case v.typ == "":
+		return nil, fmt.Errorf("vector field needs type=")
+	case v.dim == 0:
+		return nil, fmt.Errorf("vector field needs dim=")
+	case v.distanceMetric == "":
+		return nil, fmt.Errorf("vector field needs distance_metric=")
+	}
+
+	if v.hnsw {
+		return &FTVectorArgs{HNSWOptions: &FTHNSWOptions{
+			Type:            v.typ,
+			Dim:             v.dim,
+			DistanceMetric:  v.distanceMetric,
+			InitialCapacity: v.initialCap,
+			M:               v.m,
+			EFConstruction:  v.efConstruction,
+			EFRuntime:       v.efRuntime,
+			Epsilon:         v.epsilon,
+		}}, nil
+	}
+	if v.m != 0 || v.efConstruction != 0 || v.efRuntime != 0 || v.epsilon != 0 {
+		return nil, fmt.Errorf("m, ef_construction, ef_runtime and epsilon need hnsw")
+	}
+	return &FTVectorArgs{FlatOptions: &FTFlatOptions{
+		Type:            v.typ,
+		Dim:             v.dim,
+		DistanceMetric:  v.distanceMetric,
+		InitialCapacity: v.initialCap,
+		BlockSize:       v.blockSize,
+	}}, nil
+}
+
+// vectorType returns the vector TYPE of the Go type, or "" if it's unknown.
+func vectorType(typ reflect.Type) string {
+	if typ.Kind() != reflect.Slice {
+		return ""
+	}
+	switch typ.Elem().Kind() {
+	case reflect.Float32:
+		return "FLOAT32"
+	case reflect.Float64:
+		return "FLOAT64"
+	}
+	return ""
+}
+
+// schemaAliases returns the names of the fields of the struct typ by the
+// as= option of their `redis` tag.
+func schemaAliases(typ reflect.Type) map[string]string {
+	var aliases map[string]string
+	for i := 0; i < typ.NumField(); i++ {
+		parts := strings.Split(typ.Field(i).Tag.Get("redis"), ",")
+		if parts[0] == "" || parts[0] == "-" {
+			continue
+		}
+		for _, opt := range parts[1:] {
+			name, value, _ := strings.Cut(opt, "=")
+			if strings.EqualFold(name, "as") && value != "" && value != parts[0] {
+				if aliases == nil {
+					aliases = make(map[string]string)
+				}
+				aliases[value] = parts[0]
+			}
+		}
+	}
+	return aliases
+}
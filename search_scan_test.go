@@ -0,0 +1,116 @@
+package redis_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type scanProduct struct {
+	Title     string              `redis:"title,text,sortable,omitempty" json:"title"`
+	Price     float64             `redis:"price,numeric" json:"price"`
+	Tags      string              `redis:"tags,tag,separator=;,casesensitive" json:"tags"`
+	Body      string              `redis:"body,text,weight=2,nostem,as=content" json:"body"`
+	Notes     string              `redis:"notes" json:"notes"`
+	Draft     string              `redis:"draft,omitempty" json:"draft"`
+	Embedding redis.Float32Vector `redis:"embedding,vector,hnsw,dim=2,distance_metric=cosine,m=8" json:"-"`
+	Other     []string            `json:"other"`
+}
+
+func TestScanDocuments(t *testing.T) {
+	docs := []redis.Document{
+		{ID: "p:1", Fields: map[string]string{"title": "Shirt", "price": "9.5", "content": "Cotton", "ignored": "x"}},
+		{ID: "p:2", Fields: map[string]string{"$": `{"title":"Hat","price":12}`}},
+		{ID: "p:3", Fields: map[string]string{"$": `[{"title":"Cap","price":3}]`}},
+	}
+	products, err := redis.ScanDocuments[scanProduct](docs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []scanProduct{
+		{Title: "Shirt", Price: 9.5, Body: "Cotton"},
+		{Title: "Hat", Price: 12},
+		{Title: "Cap", Price: 3},
+	}
+	if !reflect.DeepEqual(products, want) {
+		t.Fatalf("got %+v, wanted %+v", products, want)
+	}
+
+	docs = []redis.Document{{ID: "p:4", Fields: map[string]string{"price": "cheap"}}}
+	if _, err := redis.ScanDocuments[scanProduct](docs); err == nil {
+		t.Fatal("expected a scan error")
+	}
+}
+
+func TestFTSchemaFromStruct(t *testing.T) {
+	schema, err := redis.FTSchemaFromStruct(&scanProduct{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*redis.FieldSchema{
+		{FieldName: "title", FieldType: redis.SearchFieldTypeText, Sortable: true},
+		{FieldName: "price", FieldType: redis.SearchFieldTypeNumeric},
+		{FieldName: "tags", FieldType: redis.SearchFieldTypeTag, Separator: ";", CaseSensitive: true},
+		{FieldName: "body", As: "content", FieldType: redis.SearchFieldTypeText, Weight: 2, NoStem: true},
+		{
+			FieldName: "embedding", FieldType: redis.SearchFieldTypeVector,
+			VectorArgs: &redis.FTVectorArgs{HNSWOptions: &redis.FTHNSWOptions{
+				Type: "FLOAT32", Dim: 2, DistanceMetric: "COSINE", M: 8,
+			}},
+		},
+	}
+	if !reflect.DeepEqual(schema, want) {
+		t.Fatalf("got %+v, wanted %+v", schema, want)
+	}
+
+	schema, err = redis.FTJSONSchemaFromStruct(scanProduct{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f := schema[0]; f.FieldName != "$.title" || f.As != "title" {
+		t.Fatalf("got %+v", f)
+	}
+	if f := schema[3]; f.FieldName != "$.body" || f.As != "content" {
+		t.Fatalf("got %+v", f)
+	}
+
+	for _, bad := range []interface{}{
+		struct {
+			Name string `redis:"name,text,weight"`
+		}{},
+		struct {
+			Name string `redis:"name,text,dim=2"`
+		}{},
+		struct {
+			Vec []byte `redis:"vec,vector,dim=2,distance_metric=l2"`
+		}{},
+		struct {
+			Vec []float64 `redis:"vec,vector,distance_metric=l2"`
+		}{},
+		struct {
+			Vec []float64 `redis:"vec,vector,flat,dim=2,distance_metric=l2,m=8"`
+		}{},
+	} {
+		if _, err := redis.FTSchemaFromStruct(bad); err == nil {
+			t.Fatalf("expected an error for %T", bad)
+		}
+	}
+
+	var flat struct {
+		Vec []byte `redis:"vec,vector,type=float64,dim=3,distance_metric=l2,block_size=64"`
+	}
+	schema, err = redis.FTSchemaFromStruct(flat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantArgs := &redis.FTVectorArgs{FlatOptions: &redis.FTFlatOptions{
+		Type: "FLOAT64", Dim: 3, DistanceMetric: "L2", BlockSize: 64,
+	}}
+	if len(schema) != 1 || !reflect.DeepEqual(schema[0].VectorArgs, wantArgs) {
+		t.Fatalf("got %+v", schema)
+	}
+	if _, err := redis.FTSchemaFromStruct("title"); err == nil {
+		t.Fatal("expected an error for a non-struct")
+	}
+}
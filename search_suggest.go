@@ -0,0 +1,248 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redis/go-redis/v9/internal/proto"
+)
+
+type FTSugAddOptions struct {
+	Incr    bool
+	Payload string
+}
+
+type FTSugGetOptions struct {
+	Fuzzy        bool
+	WithScores   bool
+	WithPayloads bool
+	Max          int
+}
+
+// FTSugAdd - Adds a suggestion string to an auto-complete suggestion dictionary.
+// For more information - https://redis.io/commands/ft.sugadd/
+func (c cmdable) FTSugAdd(ctx context.Context, key string, term string, score float64) *IntCmd {
+	cmd := NewIntCmd(ctx, "FT.SUGADD", key, term, score)
+	_ = c(ctx, cmd)
+	return cmd
+}
+
+// FTSugAddWithArgs - Adds a suggestion string to an auto-complete suggestion dictionary.
+// This function allows for specifying additional options such as:
+// Incr and Payload.
+// For more information - https://redis.io/commands/ft.sugadd/
+func (c cmdable) FTSugAddWithArgs(ctx context.Context, key string, term string, score float64, options *FTSugAddOptions) *IntCmd {
+	args := []interface{}{"FT.SUGADD", key, term, score}
+	if options != nil {
+		if options.Incr {
+			args = append(args, "INCR")
+		}
+		if options.Payload != "" {
+			args = append(args, "PAYLOAD", options.Payload)
+		}
+	}
+	cmd := NewIntCmd(ctx, args...)
+	_ = c(ctx, cmd)
+	return cmd
+}
+
+// FTSugDel - Deletes a string from a suggestion dictionary.
+// For more information - https://redis.io/commands/ft.sugdel/
+func (c cmdable) FTSugDel(ctx context.Context, key string, term string) *IntCmd {
+	cmd := NewIntCmd(ctx, "FT.SUGDEL", key, term)
+	_ = c(ctx, cmd)
+	return cmd
+}
+
+// FTSugGet - Gets completion suggestions for a prefix.
+// For more information - https://redis.io/commands/ft.sugget/
+func (c cmdable) FTSugGet(ctx context.Context, key string, prefix string) *FTSuggestionCmd {
+	cmd := NewFTSuggestionCmd(ctx, nil, "FT.SUGGET", key, prefix)
+	_ = c(ctx, cmd)
+	return cmd
+}
+
+// FTSugGetWithArgs - Gets completion suggestions for a prefix.
+// This function allows for specifying additional options such as:
+// Fuzzy, WithScores, WithPayloads and Max.
+// For more information - https://redis.io/commands/ft.sugget/
+func (c cmdable) FTSugGetWithArgs(ctx context.Context, key string, prefix string, options *FTSugGetOptions) *FTSuggestionCmd {
+	args := []interface{}{"FT.SUGGET", key, prefix}
+	if options != nil {
+		if options.Fuzzy {
+			args = append(args, "FUZZY")
+		}
+		if options.WithScores {
+			args = append(args, "WITHSCORES")
+		}
+		if options.WithPayloads {
+			args = append(args, "WITHPAYLOADS")
+		}
+		if options.Max > 0 {
+			args = append(args, "MAX", options.Max)
+		}
+	}
+	cmd := NewFTSuggestionCmd(ctx, options, args...)
+	_ = c(ctx, cmd)
+	return cmd
+}
+
+// FTSugLen - Gets the size of an auto-complete suggestion dictionary.
+// For more information - https://redis.io/commands/ft.suglen/
+func (c cmdable) FTSugLen(ctx context.Context, key string) *IntCmd {
+	cmd := NewIntCmd(ctx, "FT.SUGLEN", key)
+	_ = c(ctx, cmd)
+	return cmd
+}
+
+//------------------------------------------------------------------------------
+
+// Suggestion is an entry of an auto-complete suggestion dictionary. Score
+// and Payload are only returned by FT.SUGGET with WithScores and WithPayloads.
+type Suggestion struct {
+	Term    string
+	Score   float64
+	Payload string
+}
+
+type FTSuggestionCmd struct {
+	baseCmd
+	val     []Suggestion
+	options *FTSugGetOptions
+}
+
+func NewFTSuggestionCmd(ctx context.Context, options *FTSugGetOptions, args ...interface{}) *FTSuggestionCmd {
+	return &FTSuggestionCmd{
+		baseCmd: baseCmd{
+			ctx:  ctx,
+			args: args,
+		},
+		options: options,
+	}
+}
+
+func (cmd *FTSuggestionCmd) SetVal(val []Suggestion) {
+	cmd.val = val
+}
+
+func (cmd *FTSuggestionCmd) Val() []Suggestion {
+	return cmd.val
+}
+
+func (cmd *FTSuggestionCmd) Result() ([]Suggestion, error) {
+	return cmd.val, cmd.err
+}
+
+func (cmd *FTSuggestionCmd) String() string {
+	return cmdString(cmd, cmd.val)
+}
+
+func (cmd *FTSuggestionCmd) readReply(rd *proto.Reader) error {
+	n, err := rd.ReadArrayLen()
+	if err != nil {
+		// No suggestions is a nil reply.
+		if err == Nil {
+			cmd.val = []Suggestion{}
+			return nil
+		}
+		return err
+	}
+
+	withScores := cmd.options != nil && cmd.options.WithScores
+	withPayloads := cmd.options != nil && cmd.options.WithPayloads
+	step := 1
+	if withScores {
+		step++
+	}
+	if withPayloads {
+		step++
+	}
+	if n%step != 0 {
+		return fmt.Errorf("redis: got %d elements in the FT.SUGGET reply, wanted a multiple of %d", n, step)
+	}
+
+	cmd.val = make([]Suggestion, 0, proto.PreallocLen(n/step))
+	for i := 0; i < n; i += step {
+		var s Suggestion
+		if s.Term, err = rd.ReadString(); err != nil {
+			return err
+		}
+		if withScores {
+			if s.Score, err = rd.ReadFloat(); err != nil {
+				return err
+			}
+		}
+		if withPayloads {
+			if s.Payload, err = rd.ReadString(); err != nil && err != Nil {
+				return err
+			}
+		}
+		cmd.val = append(cmd.val, s)
+	}
+	return nil
+}
+
+//------------------------------------------------------------------------------
+
+// SuggestionSource is a source of suggestions for FTSugLoad. It's iterated
+// like a ScanIterator.
+type SuggestionSource interface {
+	Next(ctx context.Context) bool
+	Val() Suggestion
+	Err() error
+}
+
+type FTSugLoadOptions struct {
+	// BatchSize is the number of FT.SUGADD sent in each pipeline, 1000 by default.
+	BatchSize int
+	// Incr increments the score of the suggestions already in the dictionary.
+	Incr bool
+}
+
+// FTSugLoad adds the suggestions of src to the suggestion dictionary key,
+// in pipelines of options.BatchSize commands. It returns the number of
+// added suggestions, which were all added if the error is nil.
+func FTSugLoad(ctx context.Context, c Cmdable, key string, src SuggestionSource, options *FTSugLoadOptions) (int, error) {
+	batchSize := 1000
+	var incr bool
+	if options != nil {
+		if options.BatchSize > 0 {
+			batchSize = options.BatchSize
+		}
+		incr = options.Incr
+	}
+
+	var added int
+	pipe := c.Pipeline()
+	flush := func() error {
+		cmds, err := pipe.Exec(ctx)
+		for _, cmd := range cmds {
+			if cmd.Err() == nil {
+				added++
+			}
+		}
+		return err
+	}
+
+	for src.Next(ctx) {
+		s := src.Val()
+		pipe.FTSugAddWithArgs(ctx, key, s.Term, s.Score, &FTSugAddOptions{
+			Incr:    incr,
+			Payload: s.Payload,
+		})
+		if pipe.Len() >= batchSize {
+			if err := flush(); err != nil {
+				return added, err
+			}
+		}
+	}
+	if err := src.Err(); err != nil {
+		return added, err
+	}
+	if pipe.Len() > 0 {
+		if err := flush(); err != nil {
+			return added, err
+		}
+	}
+	return added, nil
+}
@@ -0,0 +1,101 @@
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	. "github.com/bsm/ginkgo/v2"
+	. "github.com/bsm/gomega"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type sliceSuggestions struct {
+	s   []redis.Suggestion
+	pos int
+	err error
+}
+
+func (it *sliceSuggestions) Next(ctx context.Context) bool {
+	if it.pos >= len(it.s) {
+		return false
+	}
+	it.pos++
+	return true
+}
+
+func (it *sliceSuggestions) Val() redis.Suggestion { return it.s[it.pos-1] }
+func (it *sliceSuggestions) Err() error            { return it.err }
+
+var _ = Describe("RediSearch suggestions", Label("search"), func() {
+	ctx := context.TODO()
+
+	for _, protocol := range []int{2, 3} {
+		protocol := protocol
+
+		Context(fmt.Sprintf("RESP%d", protocol), func() {
+			var client *redis.Client
+
+			BeforeEach(func() {
+				client = redis.NewClient(&redis.Options{Addr: rediStackAddr, Protocol: protocol})
+				Expect(client.FlushDB(ctx).Err()).NotTo(HaveOccurred())
+			})
+
+			AfterEach(func() {
+				Expect(client.Close()).NotTo(HaveOccurred())
+			})
+
+			It("should FTSugAdd, FTSugGet, FTSugLen and FTSugDel", Label("ftsugadd", "ftsugget"), func() {
+				n, err := client.FTSugAdd(ctx, "sug", "hello", 1).Result()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(n).To(BeEquivalentTo(1))
+				n, err = client.FTSugAddWithArgs(ctx, "sug", "help", 2, &redis.FTSugAddOptions{Payload: "p"}).Result()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(n).To(BeEquivalentTo(2))
+
+				sugs, err := client.FTSugGet(ctx, "sug", "hel").Result()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(sugs).To(Equal([]redis.Suggestion{{Term: "help"}, {Term: "hello"}}))
+
+				sugs, err = client.FTSugGetWithArgs(ctx, "sug", "hel", &redis.FTSugGetOptions{
+					WithScores: true, WithPayloads: true, Max: 1,
+				}).Result()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(sugs).To(HaveLen(1))
+				Expect(sugs[0].Term).To(Equal("help"))
+				Expect(sugs[0].Score).To(BeNumerically(">", 0))
+				Expect(sugs[0].Payload).To(Equal("p"))
+
+				sugs, err = client.FTSugGet(ctx, "sug", "xyz").Result()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(sugs).To(BeEmpty())
+
+				Expect(client.FTSugDel(ctx, "sug", "hello").Val()).To(BeEquivalentTo(1))
+				Expect(client.FTSugLen(ctx, "sug").Val()).To(BeEquivalentTo(1))
+			})
+
+			It("should FTSugLoad in batches", Label("ftsugload"), func() {
+				src := &sliceSuggestions{s: []redis.Suggestion{
+					{Term: "a", Score: 1}, {Term: "b", Score: 1}, {Term: "c", Score: 1, Payload: "p"},
+				}}
+				n, err := redis.FTSugLoad(ctx, client, "sug", src, &redis.FTSugLoadOptions{BatchSize: 2})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(n).To(Equal(3))
+				Expect(client.FTSugLen(ctx, "sug").Val()).To(BeEquivalentTo(3))
+
+				sugs, err := client.FTSugGetWithArgs(ctx, "sug", "c", &redis.FTSugGetOptions{WithPayloads: true}).Result()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(sugs).To(Equal([]redis.Suggestion{{Term: "c", Payload: "p"}}))
+			})
+
+			It("should stop FTSugLoad when the source fails", Label("ftsugload"), func() {
+				srcErr := errors.New("source failed")
+				src := &sliceSuggestions{s: []redis.Suggestion{{Term: "a", Score: 1}}, err: srcErr}
+				_, err := redis.FTSugLoad(ctx, client, "sug", src, nil)
+				Expect(err).To(Equal(srcErr))
+				Expect(client.FTSugLen(ctx, "sug").Val()).To(BeZero())
+			})
+		})
+	}
+})
@@ -0,0 +1,298 @@
+package redis
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/redis/go-redis/v9/internal/proto"
+)
+
+// Float32Vector is a vector of a VECTOR field of TYPE FLOAT32. It's written
+// as the little-endian blob stored in hashes and bound to KNN queries:
+//
+//	rdb.HSet(ctx, "doc:1", "embedding", redis.Float32Vector(embedding))
+type Float32Vector []float32
+
+// MarshalBinary returns the little-endian blob of the vector.
+func (v Float32Vector) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 4*len(v))
+	for i, f := range v {
+		binary.LittleEndian.PutUint32(b[4*i:], math.Float32bits(f))
+	}
+	return b, nil
+}
+
+// UnmarshalBinary decodes a little-endian blob.
+func (v *Float32Vector) UnmarshalBinary(b []byte) error {
+	if len(b)%4 != 0 {
+		return fmt.Errorf("redis: got %d bytes for a FLOAT32 vector, wanted a multiple of 4", len(b))
+	}
+	vec := make(Float32Vector, len(b)/4)
+	for i := range vec {
+		vec[i] = math.Float32frombits(binary.LittleEndian.Uint32(b[4*i:]))
+	}
+	*v = vec
+	return nil
+}
+
+// ScanRedis implements hscan.Scanner, so vectors can be scanned from hashes.
+func (v *Float32Vector) ScanRedis(s string) error {
+	return v.UnmarshalBinary([]byte(s))
+}
+
+// Float64Vector is a vector of a VECTOR field of TYPE FLOAT64, see
+// Float32Vector.
+type Float64Vector []float64
+
+// MarshalBinary returns the little-endian blob of the vector.
+func (v Float64Vector) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 8*len(v))
+	for i, f := range v {
+		binary.LittleEndian.PutUint64(b[8*i:], math.Float64bits(f))
+	}
+	return b, nil
+}
+
+// UnmarshalBinary decodes a little-endian blob.
+func (v *Float64Vector) UnmarshalBinary(b []byte) error {
+	if len(b)%8 != 0 {
+		return fmt.Errorf("redis: got %d bytes for a FLOAT64 vector, wanted a multiple of 8", len(b))
+	}
+	vec := make(Float64Vector, len(b)/8)
+	for i := range vec {
+		vec[i] = math.Float64frombits(binary.LittleEndian.Uint64(b[8*i:]))
+	}
+	*v = vec
+	return nil
+}
+
+// ScanRedis implements hscan.Scanner, so vectors can be scanned from hashes.
+func (v *Float64Vector) ScanRedis(s string) error {
+	return v.UnmarshalBinary([]byte(s))
+}
+
+//------------------------------------------------------------------------------
+
+// FTFlatOptions are the attributes of a VECTOR field indexed with FLAT.
+type FTFlatOptions struct {
+	Type            string // FLOAT32 or FLOAT64
+	Dim             int
+	DistanceMetric  string // L2, IP or COSINE
+	InitialCapacity int
+	BlockSize       int
+}
+
+// FTHNSWOptions are the attributes of a VECTOR field indexed with HNSW.
+type FTHNSWOptions struct {
+	Type            string // FLOAT32 or FLOAT64
+	Dim             int
+	DistanceMetric  string // L2, IP or COSINE
+	InitialCapacity int
+	M               int
+	EFConstruction  int
+	EFRuntime       int
+	Epsilon         float64
+}
+
+// FTVectorArgs sets the algorithm of a VECTOR field, with either
+// FlatOptions or HNSWOptions.
+type FTVectorArgs struct {
+	FlatOptions *FTFlatOptions
+	HNSWOptions *FTHNSWOptions
+}
+
+func appendVectorArgs(args []interface{}, v *FTVectorArgs) []interface{} {
+	var algo string
+	var attrs []interface{}
+	switch {
+	case v == nil:
+		return args
+	case v.FlatOptions != nil:
+		o := v.FlatOptions
+		algo = "FLAT"
+		attrs = append(attrs, "TYPE", o.Type, "DIM", o.Dim, "DISTANCE_METRIC", o.DistanceMetric)
+		if o.InitialCapacity > 0 {
+			attrs = append(attrs, "INITIAL_CAP", o.InitialCapacity)
+		}
+		if o.BlockSize > 0 {
+			attrs = append(attrs, "BLOCK_SIZE", o.BlockSize)
+		}
+	case v.HNSWOptions != nil:
+		o := v.HNSWOptions
+		algo = "HNSW"
+		attrs = append(attrs, "TYPE", o.Type, "DIM", o.Dim, "DISTANCE_METRIC", o.DistanceMetric)
+		if o.InitialCapacity > 0 {
+			attrs = append(attrs, "INITIAL_CAP", o.InitialCapacity)
+		}
+		if o.M > 0 {
+			attrs = append(attrs, "M", o.M)
+		}
+		if o.EFConstruction > 0 {
+			attrs = append(attrs, "EF_CONSTRUCTION", o.EFConstruction)
+		}
+		if o.EFRuntime > 0 {
+			attrs = append(attrs, "EF_RUNTIME", o.EFRuntime)
+		}
+		if o.Epsilon > 0 {
+			attrs = append(attrs, "EPSILON", o.Epsilon)
+		}
+	default:
+		return args
+	}
+	args = append(args, algo, len(attrs))
+	return append(args, attrs...)
+}
+
+//------------------------------------------------------------------------------
+
+// KNNQuery finds the K documents whose VECTOR field is the nearest to Vector.
+type KNNQuery struct {
+	Field string
+	// Vector is a Float32Vector, a Float64Vector, a []float32, a []float64
+	// or an encoded []byte blob.
+	Vector interface{}
+	K      int
+	// Filter restricts the search to the documents matching the clauses.
+	Filter []QueryNode
+	// Params binds the params of the Filter clauses.
+	Params map[string]interface{}
+	// Return restricts the returned fields, all by default.
+	Return []string
+	// EFRuntime overrides the EF_RUNTIME of an HNSW field.
+	EFRuntime int
+}
+
+// KNNHit is a document returned by a KNN query, with its distance to the
+// query vector.
+type KNNHit struct {
+	ID       string
+	Distance float64
+	Fields   map[string]string
+}
+
+// knnDistanceField is the field the distance is returned in.
+const knnDistanceField = "__knn_distance"
+
+// FTSearchKNN - Runs a KNN query and returns the hits sorted by distance.
+// For more information - https://redis.io/docs/interact/search-and-query/query/vector-search/
+func (c cmdable) FTSearchKNN(ctx context.Context, index string, query *KNNQuery) *KNNSearchCmd {
+	blob, err := knnVectorBlob(query.Vector)
+	if err != nil {
+		cmd := NewKNNSearchCmd(ctx, "FT.SEARCH", index)
+		cmd.SetErr(err)
+		return cmd
+	}
+
+	knn := fmt.Sprintf("[KNN %d @%s $__knn_vector", query.K, query.Field)
+	if query.EFRuntime > 0 {
+		knn += fmt.Sprintf(" EF_RUNTIME %d", query.EFRuntime)
+	}
+	knn += " AS " + knnDistanceField + "]"
+
+	q := NewSearchQuery(query.Filter...)
+	if err := q.Err(); err != nil {
+		cmd := NewKNNSearchCmd(ctx, "FT.SEARCH", index)
+		cmd.SetErr(err)
+		return cmd
+	}
+	q.knn = knn
+	q.Param("__knn_vector", blob)
+	for name, value := range query.Params {
+		q.Param(name, value)
+	}
+
+	options := q.Options()
+	for _, f := range query.Return {
+		options.Return = append(options.Return, FTSearchReturn{FieldName: f})
+	}
+	if options.Return != nil {
+		options.Return = append(options.Return, FTSearchReturn{FieldName: knnDistanceField})
+	}
+	options.SortBy = &FTSearchSortBy{FieldName: knnDistanceField, Asc: true}
+	options.Limit = query.K
+
+	args := []interface{}{"FT.SEARCH", index, q.String()}
+	args = appendFTSearchArgs(args, options)
+	cmd := NewKNNSearchCmd(ctx, args...)
+	_ = c(ctx, cmd)
+	return cmd
+}
+
+func knnVectorBlob(v interface{}) ([]byte, error) {
+	switch v := v.(type) {
+	case []byte:
+		return v, nil
+	case Float32Vector:
+		return v.MarshalBinary()
+	case []float32:
+		return Float32Vector(v).MarshalBinary()
+	case Float64Vector:
+		return v.MarshalBinary()
+	case []float64:
+		return Float64Vector(v).MarshalBinary()
+	default:
+		return nil, fmt.Errorf("redis: unsupported KNN vector type %T", v)
+	}
+}
+
+type KNNSearchCmd struct {
+	baseCmd
+	val []KNNHit
+}
+
+func NewKNNSearchCmd(ctx context.Context, args ...interface{}) *KNNSearchCmd {
+	return &KNNSearchCmd{
+		baseCmd: baseCmd{
+			ctx:  ctx,
+			args: args,
+		},
+	}
+}
+
+func (cmd *KNNSearchCmd) SetVal(val []KNNHit) {
+	cmd.val = val
+}
+
+func (cmd *KNNSearchCmd) Val() []KNNHit {
+	return cmd.val
+}
+
+func (cmd *KNNSearchCmd) Result() ([]KNNHit, error) {
+	return cmd.val, cmd.err
+}
+
+func (cmd *KNNSearchCmd) String() string {
+	return cmdString(cmd, cmd.val)
+}
+
+func (cmd *KNNSearchCmd) readReply(rd *proto.Reader) error {
+	reply, err := rd.ReadReply()
+	if err != nil {
+		return err
+	}
+	res, err := parseFTSearch(reply, nil)
+	if err != nil {
+		return err
+	}
+
+	cmd.val = make([]KNNHit, 0, len(res.Docs))
+	for _, doc := range res.Docs {
+		dist, ok := doc.Fields[knnDistanceField]
+		if !ok {
+			return fmt.Errorf("redis: KNN hit %q has no distance", doc.ID)
+		}
+		hit := KNNHit{ID: doc.ID, Fields: doc.Fields}
+		if hit.Distance, err = DecodeReply[float64](dist); err != nil {
+			return err
+		}
+		delete(hit.Fields, knnDistanceField)
+		cmd.val = append(cmd.val, hit)
+	}
+	sort.SliceStable(cmd.val, func(i, j int) bool {
+		return cmd.val[i].Distance < cmd.val[j].Distance
+	})
+	return nil
+}
@@ -0,0 +1,102 @@
+package redis
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestFloatVectorBinary(t *testing.T) {
+	b, _ := Float32Vector{1, -2.5}.MarshalBinary()
+	if want := []byte{0, 0, 0x80, 0x3f, 0, 0, 0x20, 0xc0}; !reflect.DeepEqual(b, want) {
+		t.Fatalf("got %x, wanted %x", b, want)
+	}
+	var v32 Float32Vector
+	if err := v32.ScanRedis(string(b)); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(v32, Float32Vector{1, -2.5}) {
+		t.Fatalf("got %v", v32)
+	}
+
+	b, _ = Float64Vector{1}.MarshalBinary()
+	if want := []byte{0, 0, 0, 0, 0, 0, 0xf0, 0x3f}; !reflect.DeepEqual(b, want) {
+		t.Fatalf("got %x, wanted %x", b, want)
+	}
+	var v64 Float64Vector
+	if err := v64.UnmarshalBinary(b[:7]); err == nil {
+		t.Fatal("expected an error for a truncated blob")
+	}
+}
+
+func TestFTSearchKNN(t *testing.T) {
+	var args []interface{}
+	c := cmdable(func(ctx context.Context, cmd Cmder) error {
+		args = cmd.Args()
+		return nil
+	})
+
+	blob, _ := Float32Vector{1, 2}.MarshalBinary()
+	_ = c.FTSearchKNN(ctx, "idx", &KNNQuery{
+		Field:  "vec",
+		Vector: []float32{1, 2},
+		K:      3,
+		Filter: []QueryNode{QueryTag("color", "red")},
+		Return: []string{"title"},
+	})
+	want := []interface{}{
+		"FT.SEARCH", "idx", "(@color:{red})=>[KNN 3 @vec $__knn_vector AS __knn_distance]",
+		"RETURN", 2, "title", "__knn_distance",
+		"SORTBY", "__knn_distance", "ASC", "LIMIT", 0, 3,
+		"PARAMS", 2, "__knn_vector", blob, "DIALECT", 2,
+	}
+	if !reflect.DeepEqual(args, want) {
+		t.Fatalf("got %v, wanted %v", args, want)
+	}
+
+	cmd := c.FTSearchKNN(ctx, "idx", &KNNQuery{Field: "vec", Vector: "nope", K: 1})
+	if cmd.Err() == nil {
+		t.Fatal("expected an error for an unsupported vector type")
+	}
+
+	cmd = c.FTSearchKNN(ctx, "idx", &KNNQuery{
+		Field:  "vec",
+		Vector: []float32{1, 2},
+		K:      1,
+		Filter: []QueryNode{QueryGeo("loc", 1, 2, 3, "yd")},
+	})
+	if cmd.Err() == nil {
+		t.Fatal("expected an error for an invalid filter")
+	}
+}
+
+func TestKNNSearchCmdReadReply(t *testing.T) {
+	cmd := NewKNNSearchCmd(ctx)
+	readFTReply(t, cmd, "*5\r\n:2\r\n"+
+		"$1\r\nb\r\n*4\r\n$14\r\n__knn_distance\r\n$3\r\n0.5\r\n$5\r\ntitle\r\n$1\r\nB\r\n"+
+		"$1\r\na\r\n*2\r\n$14\r\n__knn_distance\r\n$3\r\n0.1\r\n")
+	want := []KNNHit{
+		{ID: "a", Distance: 0.1, Fields: map[string]string{}},
+		{ID: "b", Distance: 0.5, Fields: map[string]string{"title": "B"}},
+	}
+	if !reflect.DeepEqual(cmd.Val(), want) {
+		t.Fatalf("got %+v, wanted %+v", cmd.Val(), want)
+	}
+}
+
+func TestVectorFieldSchemaArgs(t *testing.T) {
+	got := appendFieldSchemaArgs(nil, &FieldSchema{
+		FieldName: "vec",
+		FieldType: SearchFieldTypeVector,
+		VectorArgs: &FTVectorArgs{HNSWOptions: &FTHNSWOptions{
+			Type: "FLOAT32", Dim: 128, DistanceMetric: "COSINE", M: 16,
+		}},
+	})
+	want := []interface{}{
+		"vec", "VECTOR", "HNSW", 8,
+		"TYPE", "FLOAT32", "DIM", 128, "DISTANCE_METRIC", "COSINE", "M", 16,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, wanted %v", got, want)
+	}
+}
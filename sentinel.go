@@ -82,6 +82,9 @@ type FailoverOptions struct {
 
 	DisableIndentity bool
 	IdentitySuffix   string
+
+	JSONCodec     JSONCodec
+	JSONValidator *JSONValidator
 }
 
 func (opt *FailoverOptions) clientOptions() *Options {
@@ -119,6 +122,9 @@ func (opt *FailoverOptions) clientOptions() *Options {
 
 		DisableIndentity: opt.DisableIndentity,
 		IdentitySuffix:   opt.IdentitySuffix,
+
+		JSONCodec:     opt.JSONCodec,
+		JSONValidator: opt.JSONValidator,
 	}
 }
 
@@ -196,6 +202,9 @@ func (opt *FailoverOptions) clusterOptions() *ClusterOptions {
 
 		DisableIndentity: opt.DisableIndentity,
 		IdentitySuffix:   opt.IdentitySuffix,
+
+		JSONCodec:     opt.JSONCodec,
+		JSONValidator: opt.JSONValidator,
 	}
 }
 
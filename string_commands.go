@@ -2,7 +2,10 @@ package redis
 
 import (
 	"context"
+	"io"
 	"time"
+
+	"github.com/redis/go-redis/v9/internal/proto"
 )
 
 type StringCmdable interface {
@@ -10,6 +13,7 @@ type StringCmdable interface {
 	Decr(ctx context.Context, key string) *IntCmd
 	DecrBy(ctx context.Context, key string, decrement int64) *IntCmd
 	Get(ctx context.Context, key string) *StringCmd
+	GetToWriter(ctx context.Context, key string, w io.Writer) *WriterCmd
 	GetRange(ctx context.Context, key string, start, end int64) *StringCmd
 	GetSet(ctx context.Context, key string, value interface{}) *StringCmd
 	GetEx(ctx context.Context, key string, expiration time.Duration) *StringCmd
@@ -24,6 +28,7 @@ type StringCmdable interface {
 	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *StatusCmd
 	SetArgs(ctx context.Context, key string, value interface{}, a SetArgs) *StatusCmd
 	SetEx(ctx context.Context, key string, value interface{}, expiration time.Duration) *StatusCmd
+	SetFromReader(ctx context.Context, key string, r io.Reader, size int64) *StatusCmd
 	SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) *BoolCmd
 	SetXX(ctx context.Context, key string, value interface{}, expiration time.Duration) *BoolCmd
 	SetRange(ctx context.Context, key string, offset int64, value string) *IntCmd
@@ -55,6 +60,15 @@ func (c cmdable) Get(ctx context.Context, key string) *StringCmd {
 	return cmd
 }
 
+// GetToWriter Redis `GET key` command. The value is copied to w in chunks as it is read,
+// instead of being loaded in memory. It returns redis.Nil error when key does not exist.
+// The whole value must be read within ReadTimeout.
+func (c cmdable) GetToWriter(ctx context.Context, key string, w io.Writer) *WriterCmd {
+	cmd := NewWriterCmd(ctx, w, "get", key)
+	_ = c(ctx, cmd)
+	return cmd
+}
+
 func (c cmdable) GetRange(ctx context.Context, key string, start, end int64) *StringCmd {
 	cmd := NewStringCmd(ctx, "getrange", key, start, end)
 	_ = c(ctx, cmd)
@@ -183,6 +197,15 @@ func (c cmdable) Set(ctx context.Context, key string, value interface{}, expirat
 	return cmd
 }
 
+// SetFromReader Redis `SET key value` command. The value of size bytes is read from r
+// in chunks while it is sent, instead of being loaded in memory.
+// The whole value must be sent within WriteTimeout.
+func (c cmdable) SetFromReader(ctx context.Context, key string, r io.Reader, size int64) *StatusCmd {
+	cmd := NewStatusCmd(ctx, "set", key, &proto.StreamArg{Reader: r, Size: size})
+	_ = c(ctx, cmd)
+	return cmd
+}
+
 // SetArgs provides arguments for the SetArgs function.
 type SetArgs struct {
 	// Mode can be `NX` or `XX` or empty.
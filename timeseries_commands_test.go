@@ -2,7 +2,10 @@ package redis_test
 
 import (
 	"context"
+	"fmt"
 	"strings"
+	"sync"
+	"time"
 
 	. "github.com/bsm/ginkgo/v2"
 	. "github.com/bsm/gomega"
@@ -346,6 +349,60 @@ var _ = Describe("RedisTimeseries commands", Label("timeseries"), func() {
 		Expect(result).To(BeEquivalentTo([]int64{1, 2, 3}))
 	})
 
+	It("should write samples with TSWriter", Label("timeseries", "tsmadd", "tswriter"), func() {
+		Expect(client.TSCreate(ctx, "a").Err()).NotTo(HaveOccurred())
+		Expect(client.TSCreate(ctx, "b").Err()).NotTo(HaveOccurred())
+
+		batches := &maddBatches{}
+		client.AddHook(batches)
+
+		var mu sync.Mutex
+		var failed []redis.TSSample
+		w := redis.NewTSWriter(client, &redis.TSWriterOptions{
+			BatchSize:     10,
+			FlushInterval: time.Hour,
+			OnError: func(samples []redis.TSSample, err error) {
+				mu.Lock()
+				defer mu.Unlock()
+				failed = append(failed, samples...)
+			},
+		})
+
+		var wg sync.WaitGroup
+		for _, key := range []string{"a", "b"} {
+			key := key
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				for i := 0; i < 25; i++ {
+					Expect(w.Add(ctx, redis.TSSample{Key: key, Timestamp: int64(i), Value: float64(i)})).NotTo(HaveOccurred())
+				}
+			}()
+		}
+		wg.Wait()
+		Expect(w.Flush(ctx)).NotTo(HaveOccurred())
+		Expect(batches.sizes()).To(HaveEach(BeNumerically("<=", 10)))
+		for _, key := range []string{"a", "b"} {
+			samples, err := client.TSRange(ctx, key, 0, 100).Result()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(samples).To(HaveLen(25))
+			Expect(samples[24]).To(Equal(redis.TSTimestampValue{Timestamp: 24, Value: 24}))
+		}
+
+		// The samples of a missing key are rejected, the others are written.
+		Expect(w.Add(ctx,
+			redis.TSSample{Key: "a", Timestamp: int64(25), Value: 1},
+			redis.TSSample{Key: "missing", Timestamp: int64(25), Value: 1},
+		)).NotTo(HaveOccurred())
+		Expect(w.Flush(ctx)).To(HaveOccurred())
+		Expect(failed).To(Equal([]redis.TSSample{{Key: "missing", Timestamp: int64(25), Value: 1}}))
+		Expect(client.TSGet(ctx, "a").Val()).To(Equal(redis.TSTimestampValue{Timestamp: 25, Value: 1}))
+
+		Expect(w.Close()).NotTo(HaveOccurred())
+		Expect(w.Add(ctx, redis.TSSample{Key: "a", Value: 1})).To(Equal(redis.ErrClosed))
+	})
+
 	It("should TSMGet and TSMGetWithArgs", Label("timeseries", "tsmget", "tsmgetWithArgs", "NonRedisEnterprise"), func() {
 		opt := &redis.TSOptions{Labels: map[string]string{"Test": "This"}}
 		resultCreate, err := client.TSCreateWithArgs(ctx, "a", opt).Result()
@@ -937,4 +994,149 @@ var _ = Describe("RedisTimeseries commands", Label("timeseries"), func() {
 		Expect(result["b"][2]).To(BeEquivalentTo([]interface{}{[]interface{}{int64(10), 8.0}, []interface{}{int64(0), 4.0}}))
 		Expect(result["d"][2]).To(BeEquivalentTo([]interface{}{[]interface{}{int64(10), 8.0}, []interface{}{int64(0), 4.0}}))
 	})
+
+	for _, protocol := range []int{2, 3} {
+		protocol := protocol
+
+		Context(fmt.Sprintf("TSSeries with RESP%d", protocol), func() {
+			var client *redis.Client
+
+			BeforeEach(func() {
+				client = redis.NewClient(&redis.Options{Addr: rediStackAddr, Protocol: protocol})
+				Expect(client.FlushDB(ctx).Err()).NotTo(HaveOccurred())
+
+				err := client.TSCreateWithArgs(ctx, "a", &redis.TSOptions{Labels: map[string]string{"Test": "This", "team": "ny"}}).Err()
+				Expect(err).NotTo(HaveOccurred())
+				err = client.TSCreateWithArgs(ctx, "b", &redis.TSOptions{Labels: map[string]string{"Test": "This", "team": "sf"}}).Err()
+				Expect(err).NotTo(HaveOccurred())
+				for i := 0; i < 4; i++ {
+					Expect(client.TSAdd(ctx, "a", i, float64(i)).Err()).NotTo(HaveOccurred())
+					Expect(client.TSAdd(ctx, "b", i, float64(2*i)).Err()).NotTo(HaveOccurred())
+				}
+			})
+
+			AfterEach(func() {
+				Expect(client.Close()).NotTo(HaveOccurred())
+			})
+
+			samples := func(values ...float64) []redis.TSTimestampValue {
+				res := make([]redis.TSTimestampValue, 0, len(values))
+				for i, v := range values {
+					res = append(res, redis.TSTimestampValue{Timestamp: int64(i), Value: v})
+				}
+				return res
+			}
+
+			It("should TSMRangeSeries", Label("timeseries", "tsmrange"), func() {
+				result, err := client.TSMRangeSeries(ctx, 0, 3, []string{"Test=This"}, &redis.TSMRangeOptions{WithLabels: true}).Result()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(ConsistOf(
+					redis.TSSeries{Key: "a", Labels: map[string]string{"Test": "This", "team": "ny"}, Samples: samples(0, 1, 2, 3)},
+					redis.TSSeries{Key: "b", Labels: map[string]string{"Test": "This", "team": "sf"}, Samples: samples(0, 2, 4, 6)},
+				))
+
+				result, err = client.TSMRangeSeries(ctx, 0, 1, []string{"Test=This"}, &redis.TSMRangeOptions{SelectedLabels: []interface{}{"team"}}).Result()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(ConsistOf(
+					redis.TSSeries{Key: "a", Labels: map[string]string{"team": "ny"}, Samples: samples(0, 1)},
+					redis.TSSeries{Key: "b", Labels: map[string]string{"team": "sf"}, Samples: samples(0, 2)},
+				))
+
+				// RESP3 replies with the reducers and sources of a group in
+				// maps, RESP2 with the __reducer__ and __source__ labels.
+				result, err = client.TSMRangeSeries(ctx, 0, 3, []string{"Test=This"},
+					&redis.TSMRangeOptions{WithLabels: true, GroupByLabel: "Test", Reducer: "sum"}).Result()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(HaveLen(1))
+				Expect(result[0].Key).To(Equal("Test=This"))
+				Expect(result[0].Labels).To(Equal(map[string]string{"Test": "This"}))
+				Expect(result[0].Reducers).To(Equal([]string{"sum"}))
+				Expect(result[0].Sources).To(ConsistOf("a", "b"))
+				Expect(result[0].Samples).To(Equal(samples(0, 3, 6, 9)))
+			})
+
+			It("should TSMRevRangeSeries", Label("timeseries", "tsmrevrange"), func() {
+				result, err := client.TSMRevRangeSeries(ctx, 2, 3, []string{"team=ny"}, &redis.TSMRevRangeOptions{WithLabels: true}).Result()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(Equal([]redis.TSSeries{{
+					Key:     "a",
+					Labels:  map[string]string{"Test": "This", "team": "ny"},
+					Samples: []redis.TSTimestampValue{{Timestamp: 3, Value: 3}, {Timestamp: 2, Value: 2}},
+				}}))
+
+				result, err = client.TSMRevRangeSeries(ctx, 0, 1, []string{"Test=This"},
+					&redis.TSMRevRangeOptions{WithLabels: true, GroupByLabel: "team", Reducer: "max"}).Result()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(ConsistOf(
+					redis.TSSeries{
+						Key:      "team=ny",
+						Labels:   map[string]string{"team": "ny"},
+						Reducers: []string{"max"},
+						Sources:  []string{"a"},
+						Samples:  []redis.TSTimestampValue{{Timestamp: 1, Value: 1}, {Timestamp: 0, Value: 0}},
+					},
+					redis.TSSeries{
+						Key:      "team=sf",
+						Labels:   map[string]string{"team": "sf"},
+						Reducers: []string{"max"},
+						Sources:  []string{"b"},
+						Samples:  []redis.TSTimestampValue{{Timestamp: 1, Value: 2}, {Timestamp: 0, Value: 0}},
+					},
+				))
+			})
+
+			It("should TSMGetSeries", Label("timeseries", "tsmget"), func() {
+				// The sample of TS.MGET isn't wrapped in an array of samples.
+				result, err := client.TSMGetSeries(ctx, []string{"Test=This"}, nil).Result()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(ConsistOf(
+					redis.TSSeries{Key: "a", Labels: map[string]string{}, Samples: []redis.TSTimestampValue{{Timestamp: 3, Value: 3}}},
+					redis.TSSeries{Key: "b", Labels: map[string]string{}, Samples: []redis.TSTimestampValue{{Timestamp: 3, Value: 6}}},
+				))
+
+				result, err = client.TSMGetSeries(ctx, []string{"team=sf"}, &redis.TSMGetOptions{WithLabels: true}).Result()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(Equal([]redis.TSSeries{{
+					Key:     "b",
+					Labels:  map[string]string{"Test": "This", "team": "sf"},
+					Samples: []redis.TSTimestampValue{{Timestamp: 3, Value: 6}},
+				}}))
+
+				result, err = client.TSMGetSeries(ctx, []string{"Test=This"}, &redis.TSMGetOptions{SelectedLabels: []interface{}{"team", "none"}}).Result()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(ConsistOf(
+					redis.TSSeries{Key: "a", Labels: map[string]string{"team": "ny", "none": ""}, Samples: []redis.TSTimestampValue{{Timestamp: 3, Value: 3}}},
+					redis.TSSeries{Key: "b", Labels: map[string]string{"team": "sf", "none": ""}, Samples: []redis.TSTimestampValue{{Timestamp: 3, Value: 6}}},
+				))
+			})
+		})
+	}
 })
+
+// maddBatches records the number of samples of the TS.MADD commands.
+type maddBatches struct {
+	mu sync.Mutex
+	n  []int
+}
+
+func (h *maddBatches) DialHook(next redis.DialHook) redis.DialHook          { return next }
+func (h *maddBatches) ProcessHook(next redis.ProcessHook) redis.ProcessHook { return next }
+
+func (h *maddBatches) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		h.mu.Lock()
+		for _, cmd := range cmds {
+			if cmd.Name() == "ts.madd" {
+				h.n = append(h.n, (len(cmd.Args())-1)/3)
+			}
+		}
+		h.mu.Unlock()
+		return next(ctx, cmds)
+	}
+}
+
+func (h *maddBatches) sizes() []int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]int(nil), h.n...)
+}
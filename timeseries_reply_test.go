@@ -0,0 +1,214 @@
+package redis
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTSMultiRangeCmdReadReply(t *testing.T) {
+	samples := []TSTimestampValue{{Timestamp: 1, Value: 1.5}, {Timestamp: 2, Value: 3}}
+
+	tests := []struct {
+		name  string
+		reply []string
+		want  []TSSeries
+	}{{
+		name: "RESP3 mrange",
+		reply: []string{
+			"%2",
+			"$1", "b",
+			"*3",
+			"%1", "$4", "team", "$2", "sf",
+			"%1", "$11", "aggregators", "*0",
+			"*2", "*2", ":1", ",1.5", "*2", ":2", ",3",
+			"$1", "a",
+			"*3",
+			"%1", "$4", "team", "_",
+			"%1", "$11", "aggregators", "*0",
+			"*0",
+		},
+		want: []TSSeries{
+			{Key: "b", Labels: map[string]string{"team": "sf"}, Samples: samples},
+			{Key: "a", Labels: map[string]string{"team": ""}, Samples: []TSTimestampValue{}},
+		},
+	}, {
+		name: "RESP2 mrange",
+		reply: []string{
+			"*1",
+			"*3", "$1", "b",
+			"*1", "*2", "$4", "team", "$2", "sf",
+			"*2", "*2", ":1", "$3", "1.5", "*2", ":2", "$1", "3",
+		},
+		want: []TSSeries{{Key: "b", Labels: map[string]string{"team": "sf"}, Samples: samples}},
+	}, {
+		name: "RESP3 groupby",
+		reply: []string{
+			"%1",
+			"$7", "team=ny",
+			"*4",
+			"%1", "$4", "team", "$2", "ny",
+			"%1", "$8", "reducers", "*1", "$3", "sum",
+			"%1", "$7", "sources", "*2", "$1", "a", "$1", "c",
+			"*2", "*2", ":1", ",1.5", "*2", ":2", ",3",
+		},
+		want: []TSSeries{{
+			Key: "team=ny", Labels: map[string]string{"team": "ny"},
+			Reducers: []string{"sum"}, Sources: []string{"a", "c"}, Samples: samples,
+		}},
+	}, {
+		name: "RESP2 groupby",
+		reply: []string{
+			"*1",
+			"*3", "$7", "team=ny",
+			"*3",
+			"*2", "$4", "team", "$2", "ny",
+			"*2", "$11", "__reducer__", "$3", "sum",
+			"*2", "$10", "__source__", "$3", "a,c",
+			"*2", "*2", ":1", "$3", "1.5", "*2", ":2", "$1", "3",
+		},
+		want: []TSSeries{{
+			Key: "team=ny", Labels: map[string]string{"team": "ny"},
+			Reducers: []string{"sum"}, Sources: []string{"a", "c"}, Samples: samples,
+		}},
+	}, {
+		name: "RESP3 mget",
+		reply: []string{
+			"%2",
+			"$1", "a", "*2", "%0", "*2", ":2", ",3",
+			"$1", "b", "*2", "%0", "*0",
+		},
+		want: []TSSeries{
+			{Key: "a", Labels: map[string]string{}, Samples: samples[1:]},
+			{Key: "b", Labels: map[string]string{}, Samples: []TSTimestampValue{}},
+		},
+	}, {
+		name: "RESP2 mget",
+		reply: []string{
+			"*1",
+			"*3", "$1", "a", "*0", "*2", ":2", "$1", "3",
+		},
+		want: []TSSeries{{Key: "a", Labels: map[string]string{}, Samples: samples[1:]}},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cmd := NewTSMultiRangeCmd(ctx)
+			readFTReply(t, cmd, strings.Join(test.reply, "\r\n")+"\r\n")
+			if !reflect.DeepEqual(cmd.Val(), test.want) {
+				t.Fatalf("got %+v, wanted %+v", cmd.Val(), test.want)
+			}
+		})
+	}
+}
+
+func TestTSMultiRangeArgs(t *testing.T) {
+	var args []interface{}
+	c := cmdable(func(ctx context.Context, cmd Cmder) error {
+		args = cmd.Args()
+		return nil
+	})
+
+	c.TSMRevRangeSeries(ctx, 0, 10, []string{"a=1"}, &TSMRevRangeOptions{
+		WithLabels: true, GroupByLabel: "team", Reducer: "sum",
+	})
+	want := []interface{}{"TS.MREVRANGE", 0, 10, "WITHLABELS", "FILTER", "a=1", "GROUPBY", "team", "REDUCE", "sum"}
+	if !reflect.DeepEqual(args, want) {
+		t.Fatalf("got %v, wanted %v", args, want)
+	}
+
+	c.TSMGetSeries(ctx, []string{"a=1"}, &TSMGetOptions{SelectedLabels: []interface{}{"team"}})
+	want = []interface{}{"TS.MGET", "SELECTED_LABELS", "team", "FILTER", "a=1"}
+	if !reflect.DeepEqual(args, want) {
+		t.Fatalf("got %v, wanted %v", args, want)
+	}
+}
+
+func TestParseTSSeriesInfo(t *testing.T) {
+	want := &tsSeriesInfo{
+		sourceKey: "temp:0",
+		retention: 86400000,
+		labels:    map[string]string{"type": "temp", "room": "kitchen"},
+		rules: map[string]tsRuleInfo{
+			"temp:1_avg_60000": {aggregator: Avg, bucketDuration: 60000},
+			"temp:1_max_10":    {aggregator: Max, bucketDuration: 10},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		reply []string
+	}{{
+		name: "RESP2",
+		reply: []string{
+			"*24",
+			"$12", "totalSamples", ":0",
+			"$11", "memoryUsage", ":4239",
+			"$14", "firstTimestamp", ":0",
+			"$13", "lastTimestamp", ":0",
+			"$13", "retentionTime", ":86400000",
+			"$10", "chunkCount", ":1",
+			"$9", "chunkSize", ":4096",
+			"$9", "chunkType", "+compressed",
+			"$15", "duplicatePolicy", "$-1",
+			"$6", "labels",
+			"*2", "*2", "+type", "+temp", "*2", "+room", "+kitchen",
+			"$9", "sourceKey", "+temp:0",
+			"$5", "rules",
+			"*2",
+			"*4", "+temp:1_avg_60000", ":60000", "+AVG", ":0",
+			"*4", "+temp:1_max_10", ":10", "+MAX", ":0",
+		},
+	}, {
+		name: "RESP3",
+		reply: []string{
+			"%12",
+			"+totalSamples", ":0",
+			"+memoryUsage", ":4239",
+			"+firstTimestamp", ":0",
+			"+lastTimestamp", ":0",
+			"+retentionTime", ":86400000",
+			"+chunkCount", ":1",
+			"+chunkSize", ":4096",
+			"+chunkType", "+compressed",
+			"+duplicatePolicy", "_",
+			"+labels", "%2", "+type", "+temp", "+room", "+kitchen",
+			"+sourceKey", "+temp:0",
+			"+rules",
+			"%2",
+			"+temp:1_avg_60000", "*3", ":60000", "+AVG", ":0",
+			"+temp:1_max_10", "*3", ":10", "+MAX", ":0",
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cmd := NewMapStringInterfaceCmd(ctx)
+			readFTReply(t, cmd, strings.Join(test.reply, "\r\n")+"\r\n")
+			info, err := parseTSSeriesInfo(cmd.Val())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(info, want) {
+				t.Fatalf("got %+v, wanted %+v", info, want)
+			}
+		})
+	}
+
+	// A series that is not the destination of a rule has a null sourceKey.
+	for _, reply := range []string{
+		"*6\r\n$9\r\nsourceKey\r\n$-1\r\n$6\r\nlabels\r\n*0\r\n$5\r\nrules\r\n*0\r\n",
+		"%3\r\n+sourceKey\r\n_\r\n+labels\r\n%0\r\n+rules\r\n%0\r\n",
+	} {
+		cmd := NewMapStringInterfaceCmd(ctx)
+		readFTReply(t, cmd, reply)
+		info, err := parseTSSeriesInfo(cmd.Val())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.sourceKey != "" || len(info.labels) != 0 || len(info.rules) != 0 {
+			t.Fatalf("got %+v", info)
+		}
+	}
+}
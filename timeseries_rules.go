@@ -0,0 +1,405 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TSRuleSpec is a compaction rule wanted for every series matching Filter.
+type TSRuleSpec struct {
+	// Filter selects the source series, like TSQueryIndex.
+	Filter         []string
+	Aggregator     Aggregator
+	BucketDuration int
+	// Retention of the destination series in milliseconds. Default is 0,
+	// which creates them with the retention of the server and leaves the
+	// retention of the existing ones as is.
+	Retention int
+	// DestKey returns the destination key of the source key.
+	// Default is source + "_" + aggregator + "_" + bucket duration, like
+	// "temp:1_avg_60000". With a cluster, it must keep the hash tag of the
+	// source, since the source and the destination of a rule must be in the
+	// same slot.
+	DestKey func(source string) string
+	// Labels of the created destination series, added to the labels of
+	// their source.
+	Labels map[string]string
+}
+
+func (s *TSRuleSpec) destKey(source string) string {
+	if s.DestKey != nil {
+		return s.DestKey(source)
+	}
+	return source + "_" + strings.ToLower(s.Aggregator.String()) + "_" + strconv.Itoa(s.BucketDuration)
+}
+
+type TSReconcileOptions struct {
+	// DryRun returns the plan without applying it.
+	DryRun bool
+	// Prune deletes the rules of the matched source series that no spec
+	// wants. Their destination series are kept.
+	Prune bool
+}
+
+// TSRuleOp is the kind of a TSRuleAction.
+type TSRuleOp string
+
+const (
+	TSRuleCreateSeries TSRuleOp = "create"
+	TSRuleAlterSeries  TSRuleOp = "alter"
+	TSRuleCreateRule   TSRuleOp = "createrule"
+	TSRuleDeleteRule   TSRuleOp = "deleterule"
+)
+
+// TSRuleAction is a change of a TSRulePlan.
+type TSRuleAction struct {
+	Op TSRuleOp
+	// SourceKey is empty for the series changes.
+	SourceKey      string
+	DestKey        string
+	Aggregator     Aggregator
+	BucketDuration int
+	Retention      int
+	Labels         map[string]string
+}
+
+func (a *TSRuleAction) String() string {
+	var b strings.Builder
+	switch a.Op {
+	case TSRuleCreateSeries:
+		b.WriteString("TS.CREATE " + a.DestKey)
+		if a.Retention != 0 {
+			b.WriteString(" RETENTION " + strconv.Itoa(a.Retention))
+		}
+		if len(a.Labels) > 0 {
+			b.WriteString(" LABELS")
+			names := make([]string, 0, len(a.Labels))
+			for name := range a.Labels {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				b.WriteString(" " + name + " " + a.Labels[name])
+			}
+		}
+	case TSRuleAlterSeries:
+		b.WriteString("TS.ALTER " + a.DestKey + " RETENTION " + strconv.Itoa(a.Retention))
+	case TSRuleCreateRule:
+		fmt.Fprintf(&b, "TS.CREATERULE %s %s AGGREGATION %s %d",
+			a.SourceKey, a.DestKey, a.Aggregator, a.BucketDuration)
+	case TSRuleDeleteRule:
+		b.WriteString("TS.DELETERULE " + a.SourceKey + " " + a.DestKey)
+	}
+	return b.String()
+}
+
+// TSRulePlan is the list of changes that reconciles the compaction rules
+// with the specs, in the order they are applied: the rules are deleted,
+// the destination series are created or altered, and the rules are created.
+type TSRulePlan struct {
+	Actions []TSRuleAction
+}
+
+func (p *TSRulePlan) String() string {
+	lines := make([]string, len(p.Actions))
+	for i := range p.Actions {
+		lines[i] = p.Actions[i].String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Apply applies the actions of the plan in order, and stops at the first
+// one that fails.
+func (p *TSRulePlan) Apply(ctx context.Context, c Cmdable) error {
+	for i := range p.Actions {
+		a := &p.Actions[i]
+		var err error
+		switch a.Op {
+		case TSRuleCreateSeries:
+			err = c.TSCreateWithArgs(ctx, a.DestKey, &TSOptions{
+				Retention: a.Retention,
+				Labels:    a.Labels,
+			}).Err()
+		case TSRuleAlterSeries:
+			err = c.TSAlter(ctx, a.DestKey, &TSAlterOptions{Retention: a.Retention}).Err()
+		case TSRuleCreateRule:
+			err = c.TSCreateRule(ctx, a.SourceKey, a.DestKey, a.Aggregator, a.BucketDuration).Err()
+		case TSRuleDeleteRule:
+			err = c.TSDeleteRule(ctx, a.SourceKey, a.DestKey).Err()
+		default:
+			err = fmt.Errorf("unknown op %q", a.Op)
+		}
+		if err != nil {
+			return fmt.Errorf("redis: %s: %w", a, err)
+		}
+	}
+	return nil
+}
+
+// TSReconcileRules makes the compaction rules of the series match the specs.
+// It finds the source series of each spec with TS.QUERYINDEX, inspects them
+// and their destinations with TS.INFO, and plans the missing destination
+// series and rules, the rules whose aggregation changed, and the retention
+// changes. The plan is applied unless options.DryRun is set, and returned
+// either way.
+//
+// The series that are the destination of a rule are never sources, so the
+// destinations can keep the labels of their source.
+func TSReconcileRules(
+	ctx context.Context, c Cmdable, specs []TSRuleSpec, options *TSReconcileOptions,
+) (*TSRulePlan, error) {
+	if options == nil {
+		options = &TSReconcileOptions{}
+	}
+
+	// The rules wanted by source and destination key.
+	wanted := make(map[string]map[string]*TSRuleSpec)
+	destSources := make(map[string]string)
+	var sources []string
+	for i := range specs {
+		spec := &specs[i]
+		if spec.Aggregator.String() == "" || spec.BucketDuration <= 0 {
+			return nil, fmt.Errorf("redis: invalid TS rule spec %d: aggregator and bucket duration are required", i)
+		}
+		keys, err := c.TSQueryIndex(ctx, spec.Filter).Result()
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range keys {
+			if wanted[key] == nil {
+				wanted[key] = make(map[string]*TSRuleSpec)
+				sources = append(sources, key)
+			}
+			dest := spec.destKey(key)
+			if dest == key {
+				return nil, fmt.Errorf("redis: TS rule spec %d: series %s is its own destination", i, key)
+			}
+			if src, ok := destSources[dest]; ok && (src != key || wanted[key][dest] != nil) {
+				return nil, fmt.Errorf("redis: TS rule spec %d: destination %s is wanted twice", i, dest)
+			}
+			destSources[dest] = key
+			wanted[key][dest] = spec
+		}
+	}
+	sort.Strings(sources)
+
+	infos, err := tsInfos(ctx, c, sources)
+	if err != nil {
+		return nil, err
+	}
+	var missing []string
+	for dest := range destSources {
+		if _, ok := infos[dest]; !ok {
+			missing = append(missing, dest)
+		}
+	}
+	sort.Strings(missing)
+	destInfos, err := tsInfos(ctx, c, missing)
+	if err != nil {
+		return nil, err
+	}
+	for dest, info := range destInfos {
+		infos[dest] = info
+	}
+
+	var deletes, creates, alters, rules []TSRuleAction
+	for _, source := range sources {
+		info := infos[source]
+		if info == nil || info.sourceKey != "" {
+			continue
+		}
+
+		dests := make([]string, 0, len(info.rules))
+		for dest := range info.rules {
+			dests = append(dests, dest)
+		}
+		sort.Strings(dests)
+		kept := make(map[string]bool)
+		for _, dest := range dests {
+			rule := info.rules[dest]
+			spec, ok := wanted[source][dest]
+			switch {
+			case ok && rule.aggregator == spec.Aggregator && rule.bucketDuration == spec.BucketDuration:
+				kept[dest] = true
+			case ok || options.Prune:
+				deletes = append(deletes, TSRuleAction{
+					Op:             TSRuleDeleteRule,
+					SourceKey:      source,
+					DestKey:        dest,
+					Aggregator:     rule.aggregator,
+					BucketDuration: rule.bucketDuration,
+				})
+			}
+		}
+
+		dests = dests[:0]
+		for dest := range wanted[source] {
+			dests = append(dests, dest)
+		}
+		sort.Strings(dests)
+		for _, dest := range dests {
+			spec := wanted[source][dest]
+			destInfo := infos[dest]
+			switch {
+			case destInfo == nil:
+				labels := make(map[string]string, len(info.labels)+len(spec.Labels))
+				for name, value := range info.labels {
+					labels[name] = value
+				}
+				for name, value := range spec.Labels {
+					labels[name] = value
+				}
+				creates = append(creates, TSRuleAction{
+					Op:        TSRuleCreateSeries,
+					DestKey:   dest,
+					Retention: spec.Retention,
+					Labels:    labels,
+				})
+			case destInfo.sourceKey != "" && destInfo.sourceKey != source:
+				return nil, fmt.Errorf("redis: TS destination %s of %s is the destination of %s",
+					dest, source, destInfo.sourceKey)
+			case spec.Retention != 0 && destInfo.retention != int64(spec.Retention):
+				alters = append(alters, TSRuleAction{
+					Op:        TSRuleAlterSeries,
+					DestKey:   dest,
+					Retention: spec.Retention,
+				})
+			}
+			if kept[dest] {
+				continue
+			}
+			rules = append(rules, TSRuleAction{
+				Op:             TSRuleCreateRule,
+				SourceKey:      source,
+				DestKey:        dest,
+				Aggregator:     spec.Aggregator,
+				BucketDuration: spec.BucketDuration,
+			})
+		}
+	}
+
+	plan := &TSRulePlan{}
+	for _, actions := range [][]TSRuleAction{deletes, creates, alters, rules} {
+		plan.Actions = append(plan.Actions, actions...)
+	}
+	if options.DryRun {
+		return plan, nil
+	}
+	return plan, plan.Apply(ctx, c)
+}
+
+type tsSeriesInfo struct {
+	sourceKey string
+	retention int64
+	labels    map[string]string
+	rules     map[string]tsRuleInfo
+}
+
+type tsRuleInfo struct {
+	aggregator     Aggregator
+	bucketDuration int
+}
+
+// tsInfos returns the info of the keys that exist.
+func tsInfos(ctx context.Context, c Cmdable, keys []string) (map[string]*tsSeriesInfo, error) {
+	infos := make(map[string]*tsSeriesInfo, len(keys))
+	if len(keys) == 0 {
+		return infos, nil
+	}
+
+	cmds := make([]*MapStringInterfaceCmd, len(keys))
+	_, _ = c.Pipelined(ctx, func(pipe Pipeliner) error {
+		for i, key := range keys {
+			cmds[i] = pipe.TSInfo(ctx, key)
+		}
+		return nil
+	})
+	for i, cmd := range cmds {
+		val, err := cmd.Result()
+		if isRedisError(err) {
+			// The key doesn't exist.
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		info, err := parseTSSeriesInfo(val)
+		if err != nil {
+			return nil, fmt.Errorf("redis: TS.INFO %s: %w", keys[i], err)
+		}
+		infos[keys[i]] = info
+	}
+	return infos, nil
+}
+
+// parseTSSeriesInfo parses the RESP2 and RESP3 replies of TS.INFO.
+func parseTSSeriesInfo(m map[string]interface{}) (*tsSeriesInfo, error) {
+	info := &tsSeriesInfo{
+		labels: make(map[string]string),
+		rules:  make(map[string]tsRuleInfo),
+	}
+	info.sourceKey, _ = m["sourceKey"].(string)
+	info.retention, _ = m["retentionTime"].(int64)
+
+	switch labels := m["labels"].(type) {
+	case map[interface{}]interface{}:
+		for name, value := range labels {
+			info.labels[fmt.Sprint(name)] = fmt.Sprint(value)
+		}
+	case []interface{}:
+		for _, label := range labels {
+			pair, ok := label.([]interface{})
+			if !ok || len(pair) != 2 {
+				return nil, fmt.Errorf("unexpected label %v", label)
+			}
+			info.labels[fmt.Sprint(pair[0])] = fmt.Sprint(pair[1])
+		}
+	}
+
+	var err error
+	switch rules := m["rules"].(type) {
+	case map[interface{}]interface{}:
+		for dest, rule := range rules {
+			fields, ok := rule.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("unexpected rule %v", rule)
+			}
+			if info.rules[fmt.Sprint(dest)], err = parseTSRuleInfo(fields); err != nil {
+				return nil, err
+			}
+		}
+	case []interface{}:
+		for _, rule := range rules {
+			fields, ok := rule.([]interface{})
+			if !ok || len(fields) == 0 {
+				return nil, fmt.Errorf("unexpected rule %v", rule)
+			}
+			if info.rules[fmt.Sprint(fields[0])], err = parseTSRuleInfo(fields[1:]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return info, nil
+}
+
+// parseTSRuleInfo parses the bucket duration, aggregator and alignment of a
+// rule.
+func parseTSRuleInfo(fields []interface{}) (tsRuleInfo, error) {
+	if len(fields) < 2 {
+		return tsRuleInfo{}, fmt.Errorf("unexpected rule %v", fields)
+	}
+	bucket, ok := fields[0].(int64)
+	if !ok {
+		return tsRuleInfo{}, fmt.Errorf("unexpected rule bucket duration %v", fields[0])
+	}
+	name := fmt.Sprint(fields[1])
+	for a := Avg; a <= Twa; a++ {
+		if strings.EqualFold(a.String(), name) {
+			return tsRuleInfo{aggregator: a, bucketDuration: int(bucket)}, nil
+		}
+	}
+	return tsRuleInfo{}, fmt.Errorf("unknown aggregator %q", name)
+}
@@ -0,0 +1,107 @@
+package redis_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	. "github.com/bsm/ginkgo/v2"
+	. "github.com/bsm/gomega"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var _ = Describe("TSReconcileRules", Label("timeseries"), func() {
+	ctx := context.TODO()
+
+	for _, protocol := range []int{2, 3} {
+		protocol := protocol
+
+		Context(fmt.Sprintf("RESP%d", protocol), func() {
+			var client *redis.Client
+
+			create := func(key string, retention int, labels map[string]string) {
+				err := client.TSCreateWithArgs(ctx, key, &redis.TSOptions{Retention: retention, Labels: labels}).Err()
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			BeforeEach(func() {
+				client = redis.NewClient(&redis.Options{Addr: rediStackAddr, Protocol: protocol})
+				Expect(client.FlushDB(ctx).Err()).NotTo(HaveOccurred())
+			})
+
+			AfterEach(func() {
+				Expect(client.Close()).NotTo(HaveOccurred())
+			})
+
+			It("plans and applies the rule changes", Label("tscreaterule", "tsinfo"), func() {
+				create("temp:1", 0, map[string]string{"type": "temp"})
+				create("temp:1_avg_60000", 5, map[string]string{"type": "temp"})
+				create("temp:1_old", 0, nil)
+				create("temp:2", 0, map[string]string{"type": "temp"})
+				create("cpu:1", 0, map[string]string{"type": "cpu"})
+				Expect(client.TSCreateRule(ctx, "temp:1", "temp:1_avg_60000", redis.Avg, 1000).Err()).NotTo(HaveOccurred())
+				Expect(client.TSCreateRule(ctx, "temp:1", "temp:1_old", redis.Max, 10).Err()).NotTo(HaveOccurred())
+
+				specs := []redis.TSRuleSpec{{
+					Filter:         []string{"type=temp"},
+					Aggregator:     redis.Avg,
+					BucketDuration: 60000,
+					Retention:      86400000,
+					Labels:         map[string]string{"downsampled": "1m"},
+				}}
+				plan, err := redis.TSReconcileRules(ctx, client, specs, &redis.TSReconcileOptions{DryRun: true, Prune: true})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(plan.String()).To(Equal(strings.Join([]string{
+					"TS.DELETERULE temp:1 temp:1_avg_60000",
+					"TS.DELETERULE temp:1 temp:1_old",
+					"TS.CREATE temp:2_avg_60000 RETENTION 86400000 LABELS downsampled 1m type temp",
+					"TS.ALTER temp:1_avg_60000 RETENTION 86400000",
+					"TS.CREATERULE temp:1 temp:1_avg_60000 AGGREGATION AVG 60000",
+					"TS.CREATERULE temp:2 temp:2_avg_60000 AGGREGATION AVG 60000",
+				}, "\n")))
+				Expect(client.Exists(ctx, "temp:2_avg_60000").Val()).To(BeZero())
+
+				_, err = redis.TSReconcileRules(ctx, client, specs, &redis.TSReconcileOptions{Prune: true})
+				Expect(err).NotTo(HaveOccurred())
+
+				info, err := client.TSInfo(ctx, "temp:2_avg_60000").Result()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(info["sourceKey"]).To(Equal("temp:2"))
+				Expect(info["retentionTime"]).To(BeEquivalentTo(86400000))
+				info, err = client.TSInfo(ctx, "temp:1_old").Result()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(info["sourceKey"]).NotTo(Equal("temp:1"))
+
+				// The destinations match the filter, but aren't sources.
+				plan, err = redis.TSReconcileRules(ctx, client, specs, &redis.TSReconcileOptions{Prune: true})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(plan.Actions).To(BeEmpty())
+			})
+
+			It("rejects conflicting specs", Label("tscreaterule"), func() {
+				create("a", 0, map[string]string{"x": "1"})
+				create("b", 0, map[string]string{"x": "1"})
+				create("a_sum", 0, nil)
+				Expect(client.TSCreateRule(ctx, "b", "a_sum", redis.Sum, 10).Err()).NotTo(HaveOccurred())
+
+				tests := [][]redis.TSRuleSpec{
+					// b is the source of a_sum.
+					{{Filter: []string{"x=1"}, Aggregator: redis.Sum, BucketDuration: 10, DestKey: func(s string) string { return s + "_sum" }}},
+					// a and b have the same destination.
+					{{Filter: []string{"x=1"}, Aggregator: redis.Sum, BucketDuration: 10, DestKey: func(string) string { return "c" }}},
+					{{Filter: []string{"x=1"}, BucketDuration: 10}},
+				}
+				for _, specs := range tests {
+					_, err := redis.TSReconcileRules(ctx, client, specs, nil)
+					Expect(err).To(HaveOccurred())
+				}
+
+				Expect(client.Exists(ctx, "c", "b_sum").Val()).To(BeZero())
+				info, err := client.TSInfo(ctx, "a_sum").Result()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(info["sourceKey"]).To(Equal("b"))
+			})
+		})
+	}
+})
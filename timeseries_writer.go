@@ -0,0 +1,331 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9/internal"
+	"github.com/redis/go-redis/v9/internal/hashtag"
+)
+
+// TSSample is a sample written by a TSWriter.
+type TSSample struct {
+	Key string
+	// Timestamp in milliseconds, or "*" for the time of the server, like
+	// the timestamp of TSAdd.
+	Timestamp interface{}
+	Value     float64
+}
+
+type TSWriterOptions struct {
+	// Maximum number of samples of a TS.MADD. The buffered samples are
+	// written as soon as there are that many of them.
+	// Default is 1000.
+	BatchSize int
+	// Maximum time a sample is buffered before it's written.
+	// Default is 1 second.
+	FlushInterval time.Duration
+	// Maximum number of buffered samples, after which Add blocks until
+	// they are written.
+	// Default is 10 * BatchSize.
+	BufferSize int
+
+	// Maximum number of retries of a batch that failed with a network
+	// error, or an error like LOADING or TRYAGAIN.
+	// Default is 3 retries; -1 (not 0) disables retries.
+	MaxRetries int
+	// Minimum backoff between each retry.
+	// Default is 8 milliseconds; -1 disables backoff.
+	MinRetryBackoff time.Duration
+	// Maximum backoff between each retry.
+	// Default is 512 milliseconds; -1 disables backoff.
+	MaxRetryBackoff time.Duration
+
+	// OnError is called with the samples of a batch that failed after the
+	// retries, or with the samples of a batch the server rejected, like the
+	// samples of a key that doesn't exist, and the error of the first one.
+	OnError func(samples []TSSample, err error)
+}
+
+func (opt *TSWriterOptions) init() {
+	if opt.BatchSize <= 0 {
+		opt.BatchSize = 1000
+	}
+	if opt.FlushInterval <= 0 {
+		opt.FlushInterval = time.Second
+	}
+	if opt.BufferSize <= 0 {
+		opt.BufferSize = 10 * opt.BatchSize
+	}
+	switch opt.MaxRetries {
+	case -1:
+		opt.MaxRetries = 0
+	case 0:
+		opt.MaxRetries = 3
+	}
+	switch opt.MinRetryBackoff {
+	case -1:
+		opt.MinRetryBackoff = 0
+	case 0:
+		opt.MinRetryBackoff = 8 * time.Millisecond
+	}
+	switch opt.MaxRetryBackoff {
+	case -1:
+		opt.MaxRetryBackoff = 0
+	case 0:
+		opt.MaxRetryBackoff = 512 * time.Millisecond
+	}
+}
+
+// TSWriter buffers the samples added by many goroutines and writes them in
+// batches with TS.MADD, when BatchSize samples are buffered or every
+// FlushInterval. With a ClusterClient, the samples are grouped by slot and
+// with a Ring by shard, so that each TS.MADD is sent to the node of its keys.
+//
+// The batches are written one at a time, in the order of the samples.
+type TSWriter struct {
+	c     Cmdable
+	opt   TSWriterOptions
+	group func(key string) string
+
+	samples chan TSSample
+	flushes chan chan error
+	closing chan struct{}
+	done    chan struct{}
+
+	mu       sync.RWMutex
+	closed   bool
+	closeErr error
+}
+
+// NewTSWriter returns a TSWriter writing with c. It must be closed to write
+// the buffered samples and release its goroutine.
+func NewTSWriter(c Cmdable, opt *TSWriterOptions) *TSWriter {
+	w := &TSWriter{
+		c:       c,
+		flushes: make(chan chan error),
+		closing: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	if opt != nil {
+		w.opt = *opt
+	}
+	w.opt.init()
+	w.samples = make(chan TSSample, w.opt.BufferSize)
+
+	switch c := c.(type) {
+	case *ClusterClient:
+		w.group = func(key string) string {
+			return strconv.Itoa(hashtag.Slot(key))
+		}
+	case *Ring:
+		w.group = func(key string) string {
+			shard, err := c.sharding.GetByKey(key)
+			if err != nil {
+				// The TS.MADD gets the error.
+				return ""
+			}
+			return shard.addr
+		}
+	default:
+		w.group = func(string) string { return "" }
+	}
+
+	go w.run()
+	return w
+}
+
+// Add buffers the samples. It blocks while the buffer is full, until ctx is
+// done, and returns ErrClosed after Close.
+func (w *TSWriter) Add(ctx context.Context, samples ...TSSample) error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.closed {
+		return ErrClosed
+	}
+	for _, sample := range samples {
+		select {
+		case w.samples <- sample:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Flush writes the buffered samples and returns the first error of their
+// batches.
+func (w *TSWriter) Flush(ctx context.Context) error {
+	errc := make(chan error, 1)
+	select {
+	case w.flushes <- errc:
+	case <-w.done:
+		return ErrClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close writes the buffered samples, like Flush, and stops the writer.
+func (w *TSWriter) Close() error {
+	// Wait for the pending Add calls, which can block until the samples
+	// are written.
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return ErrClosed
+	}
+	w.closed = true
+	w.mu.Unlock()
+
+	close(w.closing)
+	<-w.done
+	return w.closeErr
+}
+
+func (w *TSWriter) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.opt.FlushInterval)
+	defer ticker.Stop()
+
+	var buf []TSSample
+	for {
+		select {
+		case sample := <-w.samples:
+			buf = append(buf, sample)
+			if len(buf) >= w.opt.BatchSize {
+				_ = w.write(buf)
+				buf = nil
+			}
+		case <-ticker.C:
+			if len(buf) > 0 {
+				_ = w.write(buf)
+				buf = nil
+			}
+		case errc := <-w.flushes:
+			errc <- w.write(w.drain(buf))
+			buf = nil
+		case <-w.closing:
+			w.closeErr = w.write(w.drain(buf))
+			return
+		}
+	}
+}
+
+// drain appends the samples of the channel to buf. The samples added
+// before a Flush or Close call are in the channel.
+func (w *TSWriter) drain(buf []TSSample) []TSSample {
+	for {
+		select {
+		case sample := <-w.samples:
+			buf = append(buf, sample)
+		default:
+			return buf
+		}
+	}
+}
+
+// write writes the samples in batches of the same group, retrying the
+// batches that failed, and returns the first error.
+func (w *TSWriter) write(samples []TSSample) error {
+	if len(samples) == 0 {
+		return nil
+	}
+
+	var groups []string
+	grouped := make(map[string][]TSSample)
+	for _, sample := range samples {
+		group := w.group(sample.Key)
+		if _, ok := grouped[group]; !ok {
+			groups = append(groups, group)
+		}
+		grouped[group] = append(grouped[group], sample)
+	}
+	var batches [][]TSSample
+	for _, group := range groups {
+		samples := grouped[group]
+		for len(samples) > 0 {
+			n := len(samples)
+			if n > w.opt.BatchSize {
+				n = w.opt.BatchSize
+			}
+			batches = append(batches, samples[:n:n])
+			samples = samples[n:]
+		}
+	}
+
+	// The batches are shared by several callers, so they are not bound to
+	// the context of any of them.
+	ctx := context.Background()
+	var firstErr error
+	for attempt := 0; len(batches) > 0; attempt++ {
+		if attempt > 0 {
+			time.Sleep(internal.RetryBackoff(attempt-1, w.opt.MinRetryBackoff, w.opt.MaxRetryBackoff))
+		}
+
+		// The replies are read as a slice, which keeps the errors of the
+		// rejected samples.
+		cmds := make([]*SliceCmd, len(batches))
+		_, _ = w.c.Pipelined(ctx, func(pipe Pipeliner) error {
+			for i, batch := range batches {
+				args := make([]interface{}, 0, 1+3*len(batch))
+				args = append(args, "TS.MADD")
+				for _, sample := range batch {
+					args = append(args, sample.Key, sample.Timestamp, sample.Value)
+				}
+				cmds[i] = NewSliceCmd(ctx, args...)
+				_ = pipe.Process(ctx, cmds[i])
+			}
+			return nil
+		})
+
+		var retries [][]TSSample
+		for i, cmd := range cmds {
+			failed, err := batches[i], cmd.Err()
+			if err == nil {
+				failed, err = rejectedTSSamples(batches[i], cmd.Val())
+			} else if attempt < w.opt.MaxRetries && shouldRetry(err, true) {
+				retries = append(retries, batches[i])
+				continue
+			}
+			if err == nil {
+				continue
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+			if w.opt.OnError != nil {
+				w.opt.OnError(failed, err)
+			}
+		}
+		batches = retries
+	}
+	return firstErr
+}
+
+// rejectedTSSamples returns the samples whose reply is an error, and the
+// first error.
+func rejectedTSSamples(batch []TSSample, replies []interface{}) ([]TSSample, error) {
+	var rejected []TSSample
+	var firstErr error
+	for i, reply := range replies {
+		if err, ok := reply.(error); ok && i < len(batch) {
+			rejected = append(rejected, batch[i])
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return rejected, firstErr
+}
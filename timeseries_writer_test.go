@@ -0,0 +1,189 @@
+package redis
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9/internal/hashtag"
+	"github.com/redis/go-redis/v9/internal/proto"
+)
+
+// maddHook answers the TS.MADD commands of a TSWriter without a server.
+type maddHook struct {
+	mu      sync.Mutex
+	batches [][]interface{}
+	// failures is the number of batches failing with a network error.
+	failures int
+}
+
+func (h *maddHook) DialHook(next DialHook) DialHook { return next }
+
+func (h *maddHook) ProcessHook(next ProcessHook) ProcessHook { return next }
+
+func (h *maddHook) ProcessPipelineHook(next ProcessPipelineHook) ProcessPipelineHook {
+	return func(ctx context.Context, cmds []Cmder) error {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		for _, cmd := range cmds {
+			if h.failures > 0 {
+				h.failures--
+				cmd.SetErr(io.EOF)
+				continue
+			}
+			h.batches = append(h.batches, cmd.Args())
+			var replies []interface{}
+			for i := 1; i+2 < len(cmd.Args()); i += 3 {
+				if cmd.Args()[i] == "missing" {
+					replies = append(replies, proto.RedisError("ERR TSDB: the key does not exist"))
+				} else {
+					replies = append(replies, cmd.Args()[i+1])
+				}
+			}
+			cmd.(*SliceCmd).SetVal(replies)
+		}
+		return nil
+	}
+}
+
+func (h *maddHook) samples() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var n int
+	for _, batch := range h.batches {
+		n += (len(batch) - 1) / 3
+	}
+	return n
+}
+
+func TestRejectedTSSamples(t *testing.T) {
+	batch := []TSSample{{Key: "a"}, {Key: "missing"}, {Key: "b"}}
+	for _, reply := range []string{
+		"*3\r\n:1\r\n-ERR TSDB: the key does not exist\r\n:2\r\n",
+		"*3\r\n:1\r\n!32\r\nERR TSDB: the key does not exist\r\n:2\r\n",
+	} {
+		cmd := NewSliceCmd(ctx, "TS.MADD")
+		readFTReply(t, cmd, reply)
+
+		rejected, err := rejectedTSSamples(batch, cmd.Val())
+		if err == nil || err.Error() != "ERR TSDB: the key does not exist" {
+			t.Fatalf("got %v", err)
+		}
+		if len(rejected) != 1 || rejected[0].Key != "missing" {
+			t.Fatalf("got %v", rejected)
+		}
+	}
+}
+
+func TestTSWriterErrors(t *testing.T) {
+	hook := &maddHook{failures: 2}
+	client := NewClient(&Options{})
+	defer client.Close()
+	client.AddHook(hook)
+
+	var failed []TSSample
+	var failedErr error
+	w := NewTSWriter(client, &TSWriterOptions{
+		FlushInterval:   time.Millisecond,
+		MinRetryBackoff: -1,
+		OnError: func(samples []TSSample, err error) {
+			failed = append(failed, samples...)
+			failedErr = err
+		},
+	})
+	defer w.Close()
+
+	// The network errors are retried.
+	if err := w.Add(ctx, TSSample{Key: "a", Timestamp: int64(1), Value: 1}); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for hook.samples() != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("the sample wasn't written")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// The rejected samples are reported.
+	if err := w.Add(ctx,
+		TSSample{Key: "a", Timestamp: int64(2), Value: 1},
+		TSSample{Key: "missing", Timestamp: int64(2), Value: 1},
+	); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Flush(ctx); err == nil {
+		t.Fatal("expected an error")
+	}
+	if len(failed) != 1 || failed[0].Key != "missing" || failedErr == nil {
+		t.Fatalf("got %v, %v", failed, failedErr)
+	}
+
+	// Retries are exhausted.
+	hook.mu.Lock()
+	hook.failures = 10
+	hook.mu.Unlock()
+	w.opt.MaxRetries = 1
+	failed = nil
+	_ = w.Add(ctx, TSSample{Key: "a", Timestamp: int64(3), Value: 1})
+	if err := w.Flush(ctx); err != io.EOF {
+		t.Fatalf("got %v, wanted io.EOF", err)
+	}
+	if len(failed) != 1 {
+		t.Fatalf("got %v", failed)
+	}
+}
+
+func TestTSWriterBackpressure(t *testing.T) {
+	block := make(chan struct{})
+	client := NewClient(&Options{})
+	defer client.Close()
+	client.AddHook(&blockingMaddHook{block: block})
+
+	w := NewTSWriter(client, &TSWriterOptions{BatchSize: 1, BufferSize: 1})
+
+	// The first sample is being written, the second one is buffered.
+	_ = w.Add(ctx, TSSample{Key: "a", Value: 1})
+	_ = w.Add(ctx, TSSample{Key: "a", Value: 2})
+
+	ctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if err := w.Add(ctx, TSSample{Key: "a", Value: 3}); err != context.DeadlineExceeded {
+		t.Fatalf("got %v, wanted context.DeadlineExceeded", err)
+	}
+
+	close(block)
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type blockingMaddHook struct {
+	maddHook
+	block chan struct{}
+}
+
+func (h *blockingMaddHook) ProcessPipelineHook(next ProcessPipelineHook) ProcessPipelineHook {
+	process := h.maddHook.ProcessPipelineHook(next)
+	return func(ctx context.Context, cmds []Cmder) error {
+		<-h.block
+		return process(ctx, cmds)
+	}
+}
+
+func TestTSWriterClusterGroups(t *testing.T) {
+	client := NewClusterClient(&ClusterOptions{Addrs: []string{"127.0.0.1:0"}})
+	defer client.Close()
+
+	w := NewTSWriter(client, nil)
+	defer w.Close()
+
+	if w.group("{user}:1") != w.group("{user}:2") {
+		t.Fatal("the keys of a hash tag have different groups")
+	}
+	if hashtag.Slot("a") == hashtag.Slot("b") || w.group("a") == w.group("b") {
+		t.Fatal("the keys of different slots have the same group")
+	}
+}
@@ -26,7 +26,8 @@ func (c *Client) newTx() *Tx {
 	tx := Tx{
 		baseClient: baseClient{
 			opt:      c.opt,
-			connPool: pool.NewStickyConnPool(c.connPool),
+			connPool: pool.NewStickyConnPool(c.dedicatedConnPool()),
+			push:     c.push,
 		},
 		hooksMixin: c.hooksMixin.clone(),
 	}
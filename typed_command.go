@@ -0,0 +1,315 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/redis/go-redis/v9/internal/hscan"
+	"github.com/redis/go-redis/v9/internal/proto"
+)
+
+// ReplyDecoder decodes a reply into a T. The reply has one of the types
+// returned by Cmd.Val: nil, string, int64, float64, bool, *big.Int,
+// []interface{} or map[interface{}]interface{}. Errors nested in
+// aggregate replies are passed as error values.
+type ReplyDecoder[T any] func(reply interface{}) (T, error)
+
+// Processor is implemented by the clients, Tx, Conn and Pipeliner.
+type Processor interface {
+	Process(ctx context.Context, cmd Cmder) error
+}
+
+// TypedCmd is a command whose reply is decoded into a T by a ReplyDecoder.
+// It gives commands that have no dedicated Cmd type a typed result.
+type TypedCmd[T any] struct {
+	baseCmd
+
+	val    T
+	decode ReplyDecoder[T]
+}
+
+var _ Cmder = (*TypedCmd[interface{}])(nil)
+
+// NewTypedCmd returns a command whose reply is decoded by decode.
+func NewTypedCmd[T any](ctx context.Context, decode ReplyDecoder[T], args ...interface{}) *TypedCmd[T] {
+	return &TypedCmd[T]{
+		baseCmd: baseCmd{
+			ctx:  ctx,
+			args: args,
+		},
+		decode: decode,
+	}
+}
+
+func (cmd *TypedCmd[T]) SetVal(val T) {
+	cmd.val = val
+}
+
+func (cmd *TypedCmd[T]) Val() T {
+	return cmd.val
+}
+
+func (cmd *TypedCmd[T]) Result() (T, error) {
+	return cmd.val, cmd.err
+}
+
+func (cmd *TypedCmd[T]) String() string {
+	return cmdString(cmd, cmd.val)
+}
+
+func (cmd *TypedCmd[T]) readReply(rd *proto.Reader) error {
+	reply, err := rd.ReadReply()
+	if err != nil {
+		return err
+	}
+	cmd.val, err = cmd.decode(reply)
+	return err
+}
+
+// DoTyped sends the command built from args, like Client.Do, and decodes
+// the reply into a T with DecodeReply.
+//
+//	n, err := redis.DoTyped[int64](ctx, rdb, "incr", "counter").Result()
+//	m, err := redis.DoTyped[map[string]string](ctx, rdb, "hgetall", "hash").Result()
+func DoTyped[T any](ctx context.Context, c Processor, args ...interface{}) *TypedCmd[T] {
+	cmd := NewTypedCmd(ctx, DecodeReply[T], args...)
+	_ = c.Process(ctx, cmd)
+	return cmd
+}
+
+//------------------------------------------------------------------------------
+
+// DecodeReply is the default ReplyDecoder. T can be:
+//   - a string, a []byte, a bool or a number;
+//   - a type implementing ScanRedis(string) error, decoded from a string;
+//   - a pointer, which is nil for a nil reply;
+//   - a slice of any of these;
+//   - a map, decoded from a map reply or from an array of field-value
+//     pairs as returned by RESP2;
+//   - a struct, decoded like a map into the fields with a `redis` tag
+//     or, without one, the fields with the same name;
+//   - interface{}, which keeps the reply as is.
+func DecodeReply[T any](reply interface{}) (T, error) {
+	var val T
+	err := decodeReply(reflect.ValueOf(&val).Elem(), reply)
+	return val, err
+}
+
+var scannerType = reflect.TypeOf((*hscan.Scanner)(nil)).Elem()
+
+func decodeReply(dst reflect.Value, reply interface{}) error {
+	if err, ok := reply.(error); ok {
+		return err
+	}
+
+	if dst.Kind() == reflect.Interface {
+		if reply != nil {
+			dst.Set(reflect.ValueOf(reply))
+		}
+		return nil
+	}
+	if dst.Kind() == reflect.Ptr {
+		if reply == nil {
+			dst.Set(reflect.Zero(dst.Type()))
+			return nil
+		}
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		return decodeReply(dst.Elem(), reply)
+	}
+	if reply == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+
+	if dst.CanAddr() && dst.Addr().Type().Implements(scannerType) {
+		s, err := replyString(reply)
+		if err != nil {
+			return err
+		}
+		return dst.Addr().Interface().(hscan.Scanner).ScanRedis(s)
+	}
+
+	switch dst.Kind() {
+	case reflect.String:
+		s, err := replyString(reply)
+		if err != nil {
+			return err
+		}
+		dst.SetString(s)
+	case reflect.Bool:
+		b, err := toBool(reply)
+		if err != nil {
+			return err
+		}
+		dst.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := toInt64(reply)
+		if err != nil {
+			return err
+		}
+		if dst.OverflowInt(n) {
+			return fmt.Errorf("redis: %d overflows %s", n, dst.Type())
+		}
+		dst.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := toUint64(reply)
+		if err != nil {
+			return err
+		}
+		if dst.OverflowUint(n) {
+			return fmt.Errorf("redis: %d overflows %s", n, dst.Type())
+		}
+		dst.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, ok := reply.(float64)
+		if !ok {
+			var err error
+			if f, err = toFloat64(reply); err != nil {
+				return err
+			}
+		}
+		dst.SetFloat(f)
+	case reflect.Slice:
+		if dst.Type().Elem().Kind() == reflect.Uint8 {
+			s, err := replyString(reply)
+			if err != nil {
+				return err
+			}
+			dst.SetBytes([]byte(s))
+			return nil
+		}
+		return decodeSlice(dst, reply)
+	case reflect.Map:
+		return decodeMap(dst, reply)
+	case reflect.Struct:
+		return decodeStruct(dst, reply)
+	default:
+		return fmt.Errorf("redis: can't decode reply into %s", dst.Type())
+	}
+	return nil
+}
+
+func replyString(reply interface{}) (string, error) {
+	switch reply := reply.(type) {
+	case string:
+		return reply, nil
+	case int64:
+		return strconv.FormatInt(reply, 10), nil
+	case float64:
+		return strconv.FormatFloat(reply, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(reply), nil
+	case *big.Int:
+		return reply.String(), nil
+	case error:
+		return "", reply
+	default:
+		return "", fmt.Errorf("redis: unexpected type=%T for String", reply)
+	}
+}
+
+func decodeSlice(dst reflect.Value, reply interface{}) error {
+	vals, ok := reply.([]interface{})
+	if !ok {
+		return fmt.Errorf("redis: unexpected type=%T for %s", reply, dst.Type())
+	}
+
+	slice := reflect.MakeSlice(dst.Type(), len(vals), len(vals))
+	for i, v := range vals {
+		if err := decodeReply(slice.Index(i), v); err != nil {
+			return err
+		}
+	}
+	dst.Set(slice)
+	return nil
+}
+
+// forEachPair calls fn with the field-value pairs of a map reply, or of
+// an array reply with an even number of elements.
+func forEachPair(reply interface{}, fn func(k, v interface{}) error) error {
+	switch reply := reply.(type) {
+	case map[interface{}]interface{}:
+		for k, v := range reply {
+			if err := fn(k, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	case []interface{}:
+		if len(reply)%2 != 0 {
+			return fmt.Errorf("redis: got %d elements in the field-value pairs, wanted a multiple of 2", len(reply))
+		}
+		for i := 0; i < len(reply); i += 2 {
+			if err := fn(reply[i], reply[i+1]); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("redis: unexpected type=%T for field-value pairs", reply)
+	}
+}
+
+func decodeMap(dst reflect.Value, reply interface{}) error {
+	typ := dst.Type()
+	m := reflect.MakeMap(typ)
+	err := forEachPair(reply, func(k, v interface{}) error {
+		key := reflect.New(typ.Key()).Elem()
+		if err := decodeReply(key, k); err != nil {
+			return err
+		}
+		val := reflect.New(typ.Elem()).Elem()
+		if err := decodeReply(val, v); err != nil {
+			return err
+		}
+		m.SetMapIndex(key, val)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	dst.Set(m)
+	return nil
+}
+
+func decodeStruct(dst reflect.Value, reply interface{}) error {
+	typ := dst.Type()
+	fields := make(map[string]int, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		tag := f.Tag.Get("redis")
+		if tag == "-" {
+			continue
+		}
+		// The tag options, like omitempty, don't matter for decoding.
+		name := strings.Split(tag, ",")[0]
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = i
+	}
+
+	return forEachPair(reply, func(k, v interface{}) error {
+		name, err := replyString(k)
+		if err != nil {
+			return err
+		}
+		i, ok := fields[name]
+		if !ok {
+			return nil
+		}
+		if err := decodeReply(dst.Field(i), v); err != nil {
+			return fmt.Errorf("redis: decoding field %q: %w", name, err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,159 @@
+package redis_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/redis/go-redis/v9/redistest"
+)
+
+type upper string
+
+func (u *upper) ScanRedis(s string) error {
+	*u = upper(strings.ToUpper(s))
+	return nil
+}
+
+type typedUser struct {
+	Name  string `redis:"name"`
+	Age   int    `redis:"age"`
+	Email *string
+	Admin bool   `redis:"-"`
+	City  string `redis:"city,omitempty"`
+	Zip   string `redis:",omitempty"`
+}
+
+func TestDecodeReply(t *testing.T) {
+	email := "a@b.c"
+
+	tests := []struct {
+		name  string
+		reply interface{}
+		want  interface{}
+		dec   func(reply interface{}) (interface{}, error)
+	}{
+		{"string", "hello", "hello", decodeAs[string]},
+		{"string from int", int64(42), "42", decodeAs[string]},
+		{"bytes", "hello", []byte("hello"), decodeAs[[]byte]},
+		{"int", "42", 42, decodeAs[int]},
+		{"uint8", int64(200), uint8(200), decodeAs[uint8]},
+		{"float from double", 1.5, 1.5, decodeAs[float64]},
+		{"float from string", "1.5", float32(1.5), decodeAs[float32]},
+		{"bool", int64(1), true, decodeAs[bool]},
+		{"nil pointer", nil, (*string)(nil), decodeAs[*string]},
+		{"pointer", "a@b.c", &email, decodeAs[*string]},
+		{"scanner", "abc", upper("ABC"), decodeAs[upper]},
+		{
+			"slice", []interface{}{"1", int64(2), nil},
+			[]int64{1, 2, 0}, decodeAs[[]int64],
+		},
+		{
+			"map from RESP2 pairs", []interface{}{"a", "1", "b", "2"},
+			map[string]int{"a": 1, "b": 2}, decodeAs[map[string]int],
+		},
+		{
+			"map from RESP3 map", map[interface{}]interface{}{"a": "1", "b": "2"},
+			map[string]int{"a": 1, "b": 2}, decodeAs[map[string]int],
+		},
+		{
+			"struct", []interface{}{"name", "joe", "age", "42", "Email", "a@b.c", "Admin", "1", "other", "x"},
+			typedUser{Name: "joe", Age: 42, Email: &email}, decodeAs[typedUser],
+		},
+		{
+			"struct with tag options", map[interface{}]interface{}{"name": "joe", "city": "Paris", "Zip": "75001"},
+			typedUser{Name: "joe", City: "Paris", Zip: "75001"}, decodeAs[typedUser],
+		},
+		{
+			"interface", []interface{}{"a", int64(1)},
+			[]interface{}{"a", int64(1)}, decodeAs[interface{}],
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.dec(tt.reply)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %#v, wanted %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeReplyErrors(t *testing.T) {
+	if _, err := redis.DecodeReply[int8](int64(300)); err == nil {
+		t.Fatal("expected an overflow error")
+	}
+	if _, err := redis.DecodeReply[map[string]string]([]interface{}{"a"}); err == nil {
+		t.Fatal("expected an error for an odd number of pairs")
+	}
+	if _, err := redis.DecodeReply[[]string]("a"); err == nil {
+		t.Fatal("expected an error decoding a string into a slice")
+	}
+	if _, err := redis.DecodeReply[chan int]("a"); err == nil {
+		t.Fatal("expected an error decoding into a channel")
+	}
+}
+
+func decodeAs[T any](reply interface{}) (interface{}, error) {
+	return redis.DecodeReply[T](reply)
+}
+
+func TestDoTyped(t *testing.T) {
+	ctx := context.Background()
+
+	srv, err := redistest.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	for _, protocol := range []int{2, 3} {
+		client := redis.NewClient(&redis.Options{
+			Addr:     srv.Addr(),
+			Protocol: protocol,
+		})
+
+		if err := client.HSet(ctx, "user", "name", "joe", "age", 42).Err(); err != nil {
+			t.Fatal(err)
+		}
+
+		user, err := redis.DoTyped[typedUser](ctx, client, "hgetall", "user").Result()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if user.Name != "joe" || user.Age != 42 {
+			t.Fatalf("RESP%d: got %+v", protocol, user)
+		}
+
+		n, err := redis.DoTyped[int64](ctx, client, "incr", "counter").Result()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != int64(protocol-1) {
+			t.Fatalf("RESP%d: got %d", protocol, n)
+		}
+
+		if err := redis.DoTyped[string](ctx, client, "get", "missing").Err(); err != redis.Nil {
+			t.Fatalf("RESP%d: got %v, wanted redis.Nil", protocol, err)
+		}
+
+		pipe := client.Pipeline()
+		ages := redis.DoTyped[[]*int](ctx, pipe, "hmget", "user", "age", "missing")
+		if _, err := pipe.Exec(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if got := ages.Val(); len(got) != 2 || *got[0] != 42 || got[1] != nil {
+			t.Fatalf("RESP%d: got %v", protocol, got)
+		}
+
+		if err := client.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -68,6 +68,9 @@ type UniversalOptions struct {
 
 	DisableIndentity bool
 	IdentitySuffix   string
+
+	JSONCodec     JSONCodec
+	JSONValidator *JSONValidator
 }
 
 // Cluster returns cluster options created from the universal options.
@@ -114,6 +117,9 @@ func (o *UniversalOptions) Cluster() *ClusterOptions {
 
 		DisableIndentity: o.DisableIndentity,
 		IdentitySuffix:   o.IdentitySuffix,
+
+		JSONCodec:     o.JSONCodec,
+		JSONValidator: o.JSONValidator,
 	}
 }
 
@@ -160,6 +166,9 @@ func (o *UniversalOptions) Failover() *FailoverOptions {
 
 		DisableIndentity: o.DisableIndentity,
 		IdentitySuffix:   o.IdentitySuffix,
+
+		JSONCodec:     o.JSONCodec,
+		JSONValidator: o.JSONValidator,
 	}
 }
 
@@ -203,6 +212,9 @@ func (o *UniversalOptions) Simple() *Options {
 
 		DisableIndentity: o.DisableIndentity,
 		IdentitySuffix:   o.IdentitySuffix,
+
+		JSONCodec:     o.JSONCodec,
+		JSONValidator: o.JSONValidator,
 	}
 }
 